@@ -0,0 +1,229 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// Kinds of amendments
+const (
+	amendRent        = "rent"
+	amendParties     = "parties"
+	amendArea        = "area"
+	amendTermination = "termination"
+	amendExtension   = "extension"
+)
+
+var amendmentLabels = map[string]string{
+	amendRent:        "Αλλαγή μισθώματος",
+	amendParties:     "Αλλαγή προσώπων",
+	amendArea:        "Αλλαγή έκτασης",
+	amendTermination: "Πρόωρη λύση",
+	amendExtension:   "Σιωπηρή παράταση",
+}
+
+// Amendments sorted by effective date, the ones with a bad date are left out
+func sortedAmendments(amendments []Amendment) []Amendment {
+	type dated struct {
+		a Amendment
+		d time.Time
+	}
+
+	var list []dated
+	for _, a := range amendments {
+		d, err := parseDate(a.Effective)
+		if err != nil {
+			continue
+		}
+		list = append(list, dated{a, d})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].d.Before(list[j].d)
+	})
+
+	sorted := make([]Amendment, 0, len(list))
+	for _, l := range list {
+		sorted = append(sorted, l.a)
+	}
+
+	return sorted
+}
+
+// The terms of the contract on the day: the entry as it was signed with every
+// amendment that was in effect by then. An early termination and an automatic
+// extension are the exceptions, they move the end date whatever the day.
+func (e Entry) AsOf(day time.Time) Entry {
+	terms := e
+	terminated := false
+
+	for _, a := range sortedAmendments(e.Amendments) {
+		if a.Kind == amendTermination {
+			if dateBefore(a.Effective, terms.End) {
+				terms.End = a.Effective
+			}
+			terminated = true
+			continue
+		}
+		if a.Kind == amendExtension {
+			// an extension after the termination never happened
+			if !terminated && dateBefore(terms.End, a.End) {
+				terms.End = a.End
+			}
+			continue
+		}
+
+		effective, _ := parseDate(a.Effective)
+		if effective.After(day) {
+			continue
+		}
+
+		switch a.Kind {
+		case amendRent:
+			terms.Rent = a.Rent
+		case amendArea:
+			terms.Size = a.Size
+		case amendParties:
+			if len(a.Owners) > 0 {
+				terms.Owners = a.Owners
+			}
+			if len(a.Renters) > 0 {
+				terms.Renters = a.Renters
+			}
+		}
+	}
+
+	return terms
+}
+
+// The day the contract really ends, the end date, an earlier termination or
+// the end of the last extension
+func (e Entry) ActualEnd() string {
+	return e.AsOf(time.Time{}).End
+}
+
+// Everyone who was an owner or a renter at some point of the period
+func (e Entry) PartiesDuring(from, to time.Time) ([]OwnerDetails, []RenterDetails) {
+	var owners []OwnerDetails
+	var renters []RenterDetails
+	seenOwners := make(map[uint]bool)
+	seenRenters := make(map[uint]bool)
+
+	add := func(terms Entry) {
+		for _, o := range terms.Owners {
+			if !seenOwners[o.ID] {
+				seenOwners[o.ID] = true
+				owners = append(owners, o)
+			}
+		}
+		for _, r := range terms.Renters {
+			if !seenRenters[r.ID] {
+				seenRenters[r.ID] = true
+				renters = append(renters, r)
+			}
+		}
+	}
+
+	add(e.AsOf(from))
+	for _, a := range e.Amendments {
+		d, err := parseDate(a.Effective)
+		if err != nil || a.Kind != amendParties || !d.After(from) || d.After(to) {
+			continue
+		}
+		add(e.AsOf(d))
+	}
+
+	return owners, renters
+}
+
+// a is before b, both stored dates, false if either can't be parsed
+func dateBefore(a, b string) bool {
+	da, err := parseDate(a)
+	if err != nil {
+		return false
+	}
+	db, err := parseDate(b)
+	if err != nil {
+		return false
+	}
+
+	return da.Before(db)
+}
+
+// The rent that corresponds to the period, every month at the yearly rent in
+// effect on its first day (or on from for the first month)
+func proratedRent(e Entry, from, to time.Time) float64 {
+	var total float64
+
+	for m := from; !m.After(to); m = time.Date(m.Year(), m.Month()+1, 1, 0, 0, 0, 0, time.UTC) {
+		total += e.AsOf(m).Rent / 12
+	}
+
+	return TruncateFloatTo2Decimals(total)
+}
+
+func sameParties(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func ownerNames(owners []OwnerDetails) []string {
+	var names []string
+	for _, o := range owners {
+		names = append(names, o.FirstName+" "+o.LastName)
+	}
+	return names
+}
+
+func renterNames(renters []RenterDetails) []string {
+	var names []string
+	for _, r := range renters {
+		names = append(names, r.FirstName+" "+r.LastName)
+	}
+	return names
+}
+
+// Splits an edit of the contract into the corrections of the original and
+// the amendments that apply from effective on. The returned entry keeps the
+// original rent, size and parties, the changed ones become amendments.
+func amendmentsFromEdit(original, edited Entry, effective string) (Entry, []Amendment) {
+	var amendments []Amendment
+	current := original.AsOf(time.Now())
+
+	if edited.Rent != current.Rent {
+		amendments = append(amendments, Amendment{EntryID: original.ID, Kind: amendRent, Effective: effective, Rent: edited.Rent})
+	}
+	if edited.Size != current.Size {
+		amendments = append(amendments, Amendment{EntryID: original.ID, Kind: amendArea, Effective: effective, Size: edited.Size})
+	}
+
+	ownersChanged := !sameParties(ownerNames(edited.Owners), ownerNames(current.Owners))
+	rentersChanged := !sameParties(renterNames(edited.Renters), renterNames(current.Renters))
+	if ownersChanged || rentersChanged {
+		a := Amendment{EntryID: original.ID, Kind: amendParties, Effective: effective}
+		if ownersChanged {
+			a.Owners = edited.Owners
+		}
+		if rentersChanged {
+			a.Renters = edited.Renters
+		}
+		amendments = append(amendments, a)
+	}
+
+	base := edited
+	base.Rent = original.Rent
+	base.Size = original.Size
+	base.Owners = original.Owners
+	base.Renters = original.Renters
+
+	return base, amendments
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"testing"
+)
+
+func amendedEntry() Entry {
+	return Entry{
+		ID:      1,
+		Name:    "Κάμπος",
+		Rent:    1200,
+		Size:    10,
+		Start:   "01-01-2022",
+		End:     "31-12-2027",
+		Owners:  []OwnerDetails{{ID: 1, FirstName: "A", LastName: "A"}},
+		Renters: []RenterDetails{{ID: 3, FirstName: "C", LastName: "C"}},
+		Amendments: []Amendment{
+			{Kind: amendParties, Effective: "01-07-2024", Owners: []OwnerDetails{{ID: 2, FirstName: "B", LastName: "B"}}},
+			{Kind: amendRent, Effective: "01-01-2025", Rent: 2400},
+			{Kind: amendArea, Effective: "01-01-2025", Size: 12},
+			{Kind: amendTermination, Effective: "30-06-2026"},
+		},
+	}
+}
+
+func TestAsOf_AppliesTheAmendmentsInEffect(t *testing.T) {
+	t.Parallel()
+
+	e := amendedEntry()
+
+	old := e.AsOf(date("15-03-2022"))
+	if old.Rent != 1200 || old.Size != 10 || old.Owners[0].ID != 1 || old.Renters[0].ID != 3 {
+		t.Fatalf("unexpected terms in 2022: %+v", old)
+	}
+
+	now := e.AsOf(date("15-03-2025"))
+	if now.Rent != 2400 || now.Size != 12 || now.Owners[0].ID != 2 || now.Renters[0].ID != 3 {
+		t.Fatalf("unexpected terms in 2025: %+v", now)
+	}
+
+	if old.End != "30-06-2026" || e.ActualEnd() != "30-06-2026" {
+		t.Fatalf("the termination should apply on every day, got %s and %s", old.End, e.ActualEnd())
+	}
+	if e.Rent != 1200 || e.End != "31-12-2027" {
+		t.Fatalf("AsOf changed the original: %+v", e)
+	}
+}
+
+func TestProratedRent_FollowsTheRentChanges(t *testing.T) {
+	t.Parallel()
+
+	e := amendedEntry()
+
+	if got := proratedRent(e, date("01-01-2024"), date("31-12-2024")); got != 1200 {
+		t.Fatalf("proratedRent(2024) = %v, want 1200", got)
+	}
+	if got := proratedRent(e, date("01-07-2024"), date("30-06-2025")); got != 1800 {
+		t.Fatalf("proratedRent(07-2024 to 06-2025) = %v, want 1800", got)
+	}
+}
+
+func TestPartiesDuring(t *testing.T) {
+	t.Parallel()
+
+	e := amendedEntry()
+
+	owners, renters := e.PartiesDuring(date("01-01-2024"), date("31-12-2024"))
+	if len(owners) != 2 || len(renters) != 1 {
+		t.Fatalf("expected both owners of 2024, got %+v %+v", owners, renters)
+	}
+
+	owners, _ = e.PartiesDuring(date("01-01-2025"), date("31-12-2025"))
+	if len(owners) != 1 || owners[0].ID != 2 {
+		t.Fatalf("expected only the new owner in 2025, got %+v", owners)
+	}
+}
+
+func TestAmendmentsFromEdit(t *testing.T) {
+	t.Parallel()
+
+	original := Entry{
+		ID:      4,
+		Name:    "Λόφος",
+		Rent:    1000,
+		Size:    5,
+		Owners:  []OwnerDetails{{FirstName: "A", LastName: "A"}, {FirstName: "B", LastName: "B"}},
+		Renters: []RenterDetails{{FirstName: "C", LastName: "C"}},
+	}
+
+	edited := original
+	edited.Name = "Λόφος 2"
+	edited.Rent = 1100
+	edited.Owners = []OwnerDetails{{FirstName: "B", LastName: "B"}, {FirstName: "A", LastName: "A"}}
+
+	base, amendments := amendmentsFromEdit(original, edited, "01-10-2025")
+	if len(amendments) != 1 || amendments[0].Kind != amendRent || amendments[0].Rent != 1100 || amendments[0].Effective != "01-10-2025" {
+		t.Fatalf("expected only a rent amendment, got %+v", amendments)
+	}
+	if base.Name != "Λόφος 2" || base.Rent != 1000 {
+		t.Fatalf("the base should keep the original rent and take the corrections: %+v", base)
+	}
+
+	edited.Renters = []RenterDetails{{FirstName: "D", LastName: "D"}}
+	_, amendments = amendmentsFromEdit(original, edited, "01-10-2025")
+	if len(amendments) != 2 || amendments[1].Kind != amendParties || len(amendments[1].Owners) != 0 || len(amendments[1].Renters) != 1 {
+		t.Fatalf("expected a rent and a renters amendment, got %+v", amendments)
+	}
+}
+
+func TestAsOf_ExtensionsMoveTheEndUnlessTerminatedBefore(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{Start: "01-01-2024", End: "31-12-2025", Amendments: []Amendment{
+		{Kind: amendExtension, Effective: "01-01-2026", End: "31-12-2026"},
+	}}
+	if got := e.ActualEnd(); got != "31-12-2026" {
+		t.Fatalf("ActualEnd() = %s, want 31-12-2026", got)
+	}
+
+	e.Amendments = append(e.Amendments, Amendment{Kind: amendTermination, Effective: "30-06-2026"})
+	if got := e.ActualEnd(); got != "30-06-2026" {
+		t.Fatalf("terminated during the extension, ActualEnd() = %s, want 30-06-2026", got)
+	}
+
+	e.Amendments = []Amendment{
+		{Kind: amendTermination, Effective: "30-11-2025"},
+		{Kind: amendExtension, Effective: "01-01-2026", End: "31-12-2026"},
+	}
+	if got := e.ActualEnd(); got != "30-11-2025" {
+		t.Fatalf("terminated before the extension, ActualEnd() = %s, want 30-11-2025", got)
+	}
+}
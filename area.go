@@ -0,0 +1,157 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// WGS84, the ellipsoid of GPS and of the coordinates we keep
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+)
+
+const squareMetresPerStremma = 1000
+
+// The declared size can be off by that many percent of the drawn area before
+// the contract is reported
+const defaultAreaTolerance = 5.0
+
+var wgs84E = math.Sqrt(wgs84F * (2 - wgs84F))
+
+// q of the authalic latitude, see Snyder, Map Projections - A Working Manual, 3-12
+func authalicQ(phi float64) float64 {
+	e := wgs84E
+	s := math.Sin(phi)
+	return (1 - e*e) * (s/(1-e*e*s*s) - math.Log((1-e*s)/(1+e*s))/(2*e))
+}
+
+var (
+	wgs84QP = authalicQ(math.Pi / 2)
+	// radius of the sphere with the area of the ellipsoid
+	wgs84AuthalicR = wgs84A * math.Sqrt(wgs84QP/2)
+)
+
+// Area of the ring on the ellipsoid in square metres. The latitudes go to the
+// sphere of the same area (authalic latitude), where the area of the ring is
+// the shoelace of longitude and sine of latitude times R². The edges follow
+// that projection instead of geodesics, a difference that is lost in the
+// decimals at the size of a field.
+func ringArea(pts []point) float64 {
+	if len(pts) < 3 {
+		return 0
+	}
+
+	var sum float64
+	for i := range pts {
+		a, b := pts[i], pts[(i+1)%len(pts)]
+		dLon := (b.x - a.x) * math.Pi / 180
+		// the short way round across the antimeridian
+		if dLon > math.Pi {
+			dLon -= 2 * math.Pi
+		} else if dLon < -math.Pi {
+			dLon += 2 * math.Pi
+		}
+		sinA := authalicQ(a.y*math.Pi/180) / wgs84QP
+		sinB := authalicQ(b.y*math.Pi/180) / wgs84QP
+		sum += dLon * (sinA + sinB) / 2
+	}
+
+	return math.Abs(sum) * wgs84AuthalicR * wgs84AuthalicR
+}
+
+// Area of the field on the ellipsoid in square metres, its parts without
+// their holes, the edges as ringArea draws them
+func ellipsoidalArea(coords []Coordinates) float64 {
+	var area float64
+	for _, p := range shapePolygons(coords) {
+		area += ringArea(p.outer)
+		for _, h := range p.holes {
+			area -= ringArea(h)
+		}
+	}
+
+	return max(area, 0)
+}
+
+// The drawn area of the field in στρέμματα
+func drawnSize(coords []Coordinates) float64 {
+	return ellipsoidalArea(coords) / squareMetresPerStremma
+}
+
+// A contract whose declared size doesn't match the drawn area
+type AreaMismatch struct {
+	Entry    Entry
+	Declared float64 // στρέμματα on the day
+	Drawn    float64 // στρέμματα
+}
+
+// How much bigger (or smaller when negative) the drawing is, in percent of
+// the declared size
+func (m AreaMismatch) Diff() float64 {
+	if m.Declared == 0 {
+		return 100
+	}
+	return (m.Drawn - m.Declared) / m.Declared * 100
+}
+
+// The contracts with coordinates whose declared size on the day differs from
+// the drawn area by more than tolerance percent, the worst first
+func areaMismatches(entries []Entry, tolerance float64, day time.Time) []AreaMismatch {
+	var mismatches []AreaMismatch
+	for _, e := range entries {
+		drawn := drawnSize(e.Coords)
+		if drawn == 0 {
+			continue
+		}
+		m := AreaMismatch{Entry: e, Declared: e.AsOf(day).Size, Drawn: drawn}
+		if math.Abs(m.Diff()) > tolerance {
+			mismatches = append(mismatches, m)
+		}
+	}
+	sort.SliceStable(mismatches, func(i, j int) bool {
+		return math.Abs(mismatches[i].Diff()) > math.Abs(mismatches[j].Diff())
+	})
+
+	return mismatches
+}
+
+func areaMismatchReport(db *sql.DB, tolerance float64, statuses []string) ([]AreaMismatch, error) {
+	entries, err := getAllEntries(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return areaMismatches(filterByStatus(entries, statuses), tolerance, time.Now()), nil
+}
+
+var areaMismatchHeader = []string{"Συμβόλαιο", "ΚΑΕΚ", "Δηλωμένα στρ.", "Σχέδιο στρ.", "Διαφορά %"}
+
+func areaMismatchRecords(mismatches []AreaMismatch) [][]string {
+	records := make([][]string, 0, len(mismatches))
+	for _, m := range mismatches {
+		records = append(records, []string{
+			contractLabel(m.Entry),
+			m.Entry.KAEK,
+			fmt.Sprintf("%.3f", m.Declared),
+			fmt.Sprintf("%.3f", m.Drawn),
+			fmt.Sprintf("%+.1f", m.Diff()),
+		})
+	}
+
+	return records
+}
+
+func areaMismatchCSV(mismatches []AreaMismatch) ([]byte, error) {
+	return csvBytes(areaMismatchHeader, areaMismatchRecords(mismatches))
+}
+
+func areaMismatchPDF(tolerance float64, mismatches []AreaMismatch) ([]byte, error) {
+	pdf := newReportPDF("P", fmt.Sprintf("Διαφορές έκτασης πάνω από %.1f%%", tolerance))
+	pdfTable(pdf, areaMismatchHeader, []float64{70, 35, 28, 28, 25}, areaMismatchRecords(mismatches))
+
+	return pdfBytes(pdf)
+}
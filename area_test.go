@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEllipsoidalArea_SmallField(t *testing.T) {
+	t.Parallel()
+
+	// 0.01° by 0.01° at 38°N, about 971m by 879m
+	lat, side := 38.0, 0.01
+	coords := corners(0, 0, lat, 23, lat, 23+side, lat+side, 23+side, lat+side, 23)
+
+	// the radii of curvature at the middle are good enough at that size
+	phi := (lat + side/2) * math.Pi / 180
+	e2 := wgs84E * wgs84E
+	w := math.Sqrt(1 - e2*math.Sin(phi)*math.Sin(phi))
+	meridian := wgs84A * (1 - e2) / (w * w * w)
+	primeVertical := wgs84A / w
+	rad := side * math.Pi / 180
+	want := meridian * primeVertical * math.Cos(phi) * rad * rad
+
+	got := ellipsoidalArea(coords)
+	if math.Abs(got-want)/want > 1e-6 {
+		t.Fatalf("ellipsoidalArea() = %f, want %f", got, want)
+	}
+
+	// the same field the other way round
+	reversed := corners(0, 0, lat+side, 23, lat+side, 23+side, lat, 23+side, lat, 23)
+	if r := ellipsoidalArea(reversed); math.Abs(r-got) > 1e-6 {
+		t.Fatalf("the direction changed the area: %f and %f", r, got)
+	}
+	if got := drawnSize(coords); math.Abs(got-want/1000) > 1e-3 {
+		t.Fatalf("drawnSize() = %f, want %f", got, want/1000)
+	}
+}
+
+func TestEllipsoidalArea_EighthOfTheEllipsoid(t *testing.T) {
+	t.Parallel()
+
+	// from the equator to the pole over 90° of longitude
+	const ellipsoid = 510065621718491.6 // m², the surface of WGS84
+	got := ringArea([]point{{0, 0}, {90, 0}, {90, 90}, {0, 90}})
+	if math.Abs(got-ellipsoid/8)/ellipsoid > 1e-9 {
+		t.Fatalf("ringArea() = %f, want %f", got, ellipsoid/8)
+	}
+}
+
+func TestEllipsoidalArea_HolesAndParts(t *testing.T) {
+	t.Parallel()
+
+	outer := corners(0, 0, 38, 23, 38, 23.01, 38.01, 23.01, 38.01, 23)
+	hole := corners(0, 1, 38.002, 23.002, 38.002, 23.004, 38.004, 23.004, 38.004, 23.002)
+	part := corners(1, 0, 39, 23, 39, 23.01, 39.01, 23.01, 39.01, 23)
+
+	whole := ellipsoidalArea(outer)
+	withHole := ellipsoidalArea(append(append([]Coordinates(nil), outer...), hole...))
+	if want := whole - ellipsoidalArea(corners(0, 0, 38.002, 23.002, 38.002, 23.004, 38.004, 23.004, 38.004, 23.002)); math.Abs(withHole-want) > 1e-6 {
+		t.Fatalf("with the hole %f, want %f", withHole, want)
+	}
+
+	both := ellipsoidalArea(append(append([]Coordinates(nil), outer...), part...))
+	if want := whole + ellipsoidalArea(corners(0, 0, 39, 23, 39, 23.01, 39.01, 23.01, 39.01, 23)); math.Abs(both-want) > 1e-6 {
+		t.Fatalf("two parts %f, want %f", both, want)
+	}
+
+	if got := ellipsoidalArea(corners(0, 0, 38, 23, 38, 23.01)); got != 0 {
+		t.Fatalf("two corners have no area, got %f", got)
+	}
+}
+
+func TestAreaMismatches(t *testing.T) {
+	t.Parallel()
+
+	coords := corners(0, 0, 38, 23, 38, 23.001, 38.001, 23.001, 38.001, 23)
+	drawn := drawnSize(coords)
+
+	entries := []Entry{
+		{ID: 1, Name: "ok", Size: drawn * 1.03, Coords: coords},
+		{ID: 2, Name: "bigger", Size: drawn * 0.9, Coords: coords},
+		{ID: 3, Name: "much smaller", Size: drawn * 1.5, Coords: coords},
+		{ID: 4, Name: "no drawing", Size: 100},
+		{ID: 5, Name: "amended", Size: drawn * 2, Coords: coords,
+			Amendments: []Amendment{{Kind: amendArea, Effective: "01-01-2025", Size: drawn}}},
+	}
+
+	got := areaMismatches(entries, defaultAreaTolerance, date("01-06-2025"))
+	if len(got) != 2 || got[0].Entry.ID != 3 || got[1].Entry.ID != 2 {
+		t.Fatalf("unexpected mismatches: %+v", got)
+	}
+	if d := got[1].Diff(); math.Abs(d-100*(1/0.9-1)) > 1e-9 {
+		t.Fatalf("Diff() = %f", d)
+	}
+}
+
+func TestEllipsoidalArea_PlanOfAParcel(t *testing.T) {
+	t.Parallel()
+
+	// the corners of a parcel on a topographic plan in ΕΓΣΑ87, the plan gives
+	// 22823.24 m², the shoelace on the grid
+	plan := [][2]string{
+		{"476012.35", "4205003.10"},
+		{"476148.90", "4205021.75"},
+		{"476171.20", "4205139.60"},
+		{"476083.45", "4205188.05"},
+		{"475998.70", "4205112.40"},
+	}
+	const planArea = 22823.24
+
+	var coords []Coordinates
+	for i, xy := range plan {
+		c, err := parseGridCorner(xy[0], xy[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Seq = i
+		coords = append(coords, c)
+	}
+
+	// the grid is bigger than the ground by the square of the scale factor of
+	// the transverse Mercator, k0·(1 + x²/2R²) that far from the central
+	// meridian
+	phi := coords[0].Latitude * math.Pi / 180
+	e2 := wgs84E * wgs84E
+	w := math.Sqrt(1 - e2*math.Sin(phi)*math.Sin(phi))
+	r2 := wgs84A * (1 - e2) / (w * w * w) * wgs84A / w
+	x := 476085.0 - 500000
+	k := 0.9996 * (1 + x*x/(2*r2))
+
+	// and the ground of ΕΓΣΑ87 lies h above the WGS84 ellipsoid by the shift
+	// of the datum, so the field is smaller there by the square of R/(R+h)
+	lam := coords[0].Longitude * math.Pi / 180
+	h := egsa87ShiftX*math.Cos(phi)*math.Cos(lam) + egsa87ShiftY*math.Cos(phi)*math.Sin(lam) + egsa87ShiftZ*math.Sin(phi)
+	want := planArea / (k * k) * (1 - 2*h/math.Sqrt(r2))
+
+	if got := ellipsoidalArea(coords); math.Abs(got-want)/want > 2e-6 {
+		t.Fatalf("ellipsoidalArea() = %.2f m², want %.2f m² (the plan %.2f m²)", got, want, planArea)
+	}
+}
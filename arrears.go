@@ -0,0 +1,306 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// The rent of a contract (Entry.Rent) is per year and it's due at the start
+// of every lease year, the last year is pro-rated when it's not a full one.
+// Every installment is the rent in effect on its due date.
+type Installment struct {
+	EntryID uint
+	Due     time.Time
+	Amount  float64
+	Paid    float64
+}
+
+// Aging buckets of the overdue amounts
+const (
+	bucketUpTo30 = "0-30"
+	bucketUpTo90 = "31-90"
+	bucketOver90 = "90+"
+)
+
+var arrearsBuckets = []string{bucketUpTo30, bucketUpTo90, bucketOver90}
+
+// An overdue installment
+type ArrearsLine struct {
+	Entry       Entry
+	Installment Installment
+	Outstanding float64
+	DaysOverdue int
+	Bucket      string
+	Interest    float64
+}
+
+// Everything a person owes or is owed, with the amounts already split
+// between the co-owners or co-renters of each contract
+type PersonArrears struct {
+	Name     string
+	AFM      uint
+	Lines    []ArrearsLine
+	Buckets  map[string]float64
+	Total    float64
+	Interest float64
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+// Installments that fell due up to (and including) the date
+func rentInstallments(e Entry, until time.Time) ([]Installment, error) {
+	var installments []Installment
+
+	start, err := parseDate(e.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseDate(e.ActualEnd())
+	if err != nil {
+		return nil, err
+	}
+
+	for due := start; !due.After(end) && !due.After(until); due = due.AddDate(1, 0, 0) {
+		rent := e.AsOf(due).Rent
+		amount := rent
+
+		next := due.AddDate(1, 0, 0)
+		if next.After(end.AddDate(0, 0, 1)) {
+			amount = rent * float64(daysBetween(due, end)+1) / float64(daysBetween(due, next))
+		}
+
+		installments = append(installments, Installment{
+			EntryID: e.ID,
+			Due:     due,
+			Amount:  TruncateFloatTo2Decimals(amount),
+		})
+	}
+
+	return installments, nil
+}
+
+// Payments pay off the oldest installments first
+func allocatePayments(installments []Installment, payments []Payment) []Installment {
+	var paid float64
+	for _, p := range payments {
+		paid += p.Amount
+	}
+
+	for i := range installments {
+		if paid <= 0 {
+			break
+		}
+		installments[i].Paid = math.Min(paid, installments[i].Amount)
+		paid -= installments[i].Paid
+	}
+
+	return installments
+}
+
+func agingBucket(days int) string {
+	switch {
+	case days <= 30:
+		return bucketUpTo30
+	case days <= 90:
+		return bucketUpTo90
+	default:
+		return bucketOver90
+	}
+}
+
+// Simple interest from the day after the due date until asOf, every day
+// uses the latest rate (yearly percentage) that applied on that day
+func lateInterest(amount float64, due, asOf time.Time, rates []InterestRate) float64 {
+	type period struct {
+		from time.Time
+		rate float64
+	}
+
+	var periods []period
+	for _, r := range rates {
+		from, err := parseDate(r.From)
+		if err != nil {
+			continue
+		}
+		periods = append(periods, period{from, r.Rate})
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].from.Before(periods[j].from)
+	})
+
+	var interest float64
+	for i, p := range periods {
+		from := p.from
+		if from.Before(due) {
+			from = due
+		}
+		to := asOf
+		if i+1 < len(periods) && periods[i+1].from.Before(asOf) {
+			to = periods[i+1].from
+		}
+		if !to.After(from) {
+			continue
+		}
+
+		interest += amount * p.rate / 100 * float64(daysBetween(from, to)) / 365
+	}
+
+	return TruncateFloatTo2Decimals(interest)
+}
+
+func buildArrears(entries []Entry, payments []Payment, rates []InterestRate, asOf time.Time) ([]ArrearsLine, error) {
+	var lines []ArrearsLine
+
+	// a report back in time doesn't know of the payments made after it
+	byEntry := make(map[uint][]Payment)
+	for _, p := range payments {
+		if paid, err := parseDate(p.Date); err == nil && paid.After(asOf) {
+			continue
+		}
+		byEntry[p.EntryID] = append(byEntry[p.EntryID], p)
+	}
+
+	for _, e := range entries {
+		// only the cash rents fall due
+		if !e.Rules().Rent || (e.Rent <= 0 && len(e.Amendments) == 0) {
+			continue
+		}
+
+		installments, err := rentInstallments(e, asOf)
+		if err != nil {
+			return nil, fmt.Errorf("contract %s: %v", e.Name, err)
+		}
+		installments = allocatePayments(installments, byEntry[e.ID])
+
+		for _, inst := range installments {
+			outstanding := TruncateFloatTo2Decimals(inst.Amount - inst.Paid)
+			if outstanding <= 0 || !inst.Due.Before(asOf) {
+				continue
+			}
+
+			days := daysBetween(inst.Due, asOf)
+			line := ArrearsLine{
+				Entry:       e.AsOf(inst.Due),
+				Installment: inst,
+				Outstanding: outstanding,
+				DaysOverdue: days,
+				Bucket:      agingBucket(days),
+			}
+			if len(rates) > 0 {
+				line.Interest = lateInterest(outstanding, inst.Due, asOf, rates)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, nil
+}
+
+// Groups the overdue lines by renter (who owes) or by owner (who is owed)
+func groupArrears(lines []ArrearsLine, byRenter bool) []PersonArrears {
+	people := make(map[string]*PersonArrears)
+	var order []string
+
+	add := func(key, name string, afm uint, share int, l ArrearsLine) {
+		p, ok := people[key]
+		if !ok {
+			p = &PersonArrears{Name: name, AFM: afm, Buckets: make(map[string]float64)}
+			people[key] = p
+			order = append(order, key)
+		}
+
+		l.Outstanding = TruncateFloatTo2Decimals(l.Outstanding / float64(share))
+		l.Interest = TruncateFloatTo2Decimals(l.Interest / float64(share))
+
+		p.Lines = append(p.Lines, l)
+		p.Buckets[l.Bucket] += l.Outstanding
+		p.Total += l.Outstanding
+		p.Interest += l.Interest
+	}
+
+	for _, l := range lines {
+		if byRenter {
+			for _, r := range l.Entry.Renters {
+				add("r"+strconv.FormatUint(uint64(r.ID), 10), r.FirstName+" "+r.LastName, r.AFM, len(l.Entry.Renters), l)
+			}
+		} else {
+			for _, o := range l.Entry.Owners {
+				add("o"+strconv.FormatUint(uint64(o.ID), 10), o.FirstName+" "+o.LastName, o.AFM, len(l.Entry.Owners), l)
+			}
+		}
+	}
+
+	result := make([]PersonArrears, 0, len(order))
+	for _, k := range order {
+		result = append(result, *people[k])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Total > result[j].Total
+	})
+
+	return result
+}
+
+func arrearsReport(db *sql.DB, asOf time.Time, withInterest bool, statuses []string) ([]ArrearsLine, error) {
+	entries, err := getAllEntries(db)
+	if err != nil {
+		return nil, err
+	}
+	entries = filterByStatus(entries, statuses)
+	payments, err := getAllPayments(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []InterestRate
+	if withInterest {
+		rates, err = getInterestRates(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buildArrears(entries, payments, rates, asOf)
+}
+
+var arrearsHeader = []string{"Συμβόλαιο", "Λήξη δόσης", "Ημέρες", "Κλιμάκιο", "Οφειλή (€)", "Τόκοι (€)"}
+
+func arrearsRecords(p PersonArrears) [][]string {
+	records := make([][]string, 0, len(p.Lines)+1)
+
+	for _, l := range p.Lines {
+		records = append(records, []string{
+			contractLabel(l.Entry),
+			l.Installment.Due.Format(dateLayout),
+			strconv.Itoa(l.DaysOverdue),
+			l.Bucket,
+			fmt.Sprintf("%.2f", l.Outstanding),
+			fmt.Sprintf("%.2f", l.Interest),
+		})
+	}
+	records = append(records, []string{"Σύνολο", "", "", "", fmt.Sprintf("%.2f", p.Total), fmt.Sprintf("%.2f", p.Interest)})
+
+	return records
+}
+
+func arrearsCSV(p PersonArrears) ([]byte, error) {
+	return csvBytes(arrearsHeader, arrearsRecords(p))
+}
+
+func arrearsPDF(p PersonArrears, asOf time.Time) ([]byte, error) {
+	pdf := newReportPDF("P", "Κατάσταση οφειλών",
+		fmt.Sprintf("%s, Α.Φ.Μ.: %d", p.Name, p.AFM),
+		fmt.Sprintf("Έως: %s", asOf.Format(dateLayout)),
+		fmt.Sprintf("0-30: %.2f€   31-90: %.2f€   90+: %.2f€",
+			p.Buckets[bucketUpTo30], p.Buckets[bucketUpTo90], p.Buckets[bucketOver90]),
+	)
+	pdfTable(pdf, arrearsHeader, []float64{55, 27, 18, 22, 34, 34}, arrearsRecords(p))
+
+	return pdfBytes(pdf)
+}
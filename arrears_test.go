@@ -0,0 +1,165 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := parseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestRentInstallments_ProRatesTheLastYear(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{ID: 1, Rent: 1200, Start: "01-10-2023", End: "31-03-2025"}
+
+	got, err := rentInstallments(e, date("01-01-2030"))
+	if err != nil {
+		t.Fatalf("rentInstallments returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 installments, got %d", len(got))
+	}
+	if got[0].Amount != 1200 || !got[0].Due.Equal(date("01-10-2023")) {
+		t.Fatalf("unexpected first installment: %+v", got[0])
+	}
+	// 01-10-2024 to 31-03-2025 is 182 of 365 days
+	if math.Abs(got[1].Amount-598.35) > 0.01 {
+		t.Fatalf("unexpected pro-rated amount: %v", got[1].Amount)
+	}
+
+	got, err = rentInstallments(e, date("30-09-2024"))
+	if err != nil || len(got) != 1 {
+		t.Fatalf("expected only the installments due so far, got %d (err: %v)", len(got), err)
+	}
+}
+
+func TestRentInstallments_UseTheAmendedRent(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{
+		ID:         1,
+		Rent:       1000,
+		Start:      "01-01-2024",
+		End:        "31-12-2027",
+		Amendments: []Amendment{{Kind: amendRent, Effective: "01-06-2024", Rent: 1500}, {Kind: amendTermination, Effective: "31-12-2025"}},
+	}
+
+	got, err := rentInstallments(e, date("01-01-2030"))
+	if err != nil {
+		t.Fatalf("rentInstallments returned error: %v", err)
+	}
+	if len(got) != 2 || got[0].Amount != 1000 || got[1].Amount != 1500 {
+		t.Fatalf("unexpected installments: %+v", got)
+	}
+}
+
+func TestAllocatePayments_OldestFirst(t *testing.T) {
+	t.Parallel()
+
+	installments := []Installment{{Amount: 100}, {Amount: 100}, {Amount: 100}}
+	got := allocatePayments(installments, []Payment{{Amount: 120}, {Amount: 30}})
+
+	if got[0].Paid != 100 || got[1].Paid != 50 || got[2].Paid != 0 {
+		t.Fatalf("unexpected allocation: %+v", got)
+	}
+}
+
+func TestAgingBucket(t *testing.T) {
+	t.Parallel()
+
+	cases := map[int]string{1: bucketUpTo30, 30: bucketUpTo30, 31: bucketUpTo90, 90: bucketUpTo90, 91: bucketOver90}
+	for days, want := range cases {
+		if got := agingBucket(days); got != want {
+			t.Errorf("agingBucket(%d) = %s, want %s", days, got, want)
+		}
+	}
+}
+
+func TestLateInterest_UsesTheRateOfEachPeriod(t *testing.T) {
+	t.Parallel()
+
+	rates := []InterestRate{
+		{From: "01-01-2020", Rate: 10},
+		{From: "01-07-2025", Rate: 5},
+	}
+
+	// 181 days at 10% and 184 days at 5%
+	got := lateInterest(1000, date("01-01-2025"), date("01-01-2026"), rates)
+	want := TruncateFloatTo2Decimals(1000*0.10*181/365 + 1000*0.05*184/365)
+	if got != want {
+		t.Fatalf("lateInterest() = %v, want %v", got, want)
+	}
+
+	if got := lateInterest(1000, date("01-01-2025"), date("01-01-2026"), nil); got != 0 {
+		t.Fatalf("expected no interest without rates, got %v", got)
+	}
+}
+
+func TestBuildArrears_GroupsAndSplits(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{
+		ID:      1,
+		Name:    "Κάμπος",
+		Rent:    1000,
+		Start:   "01-01-2025",
+		End:     "31-12-2026",
+		Owners:  []OwnerDetails{{ID: 1, FirstName: "A"}, {ID: 2, FirstName: "B"}},
+		Renters: []RenterDetails{{ID: 3, FirstName: "C"}},
+	}
+	payments := []Payment{{EntryID: 1, Amount: 400}}
+
+	lines, err := buildArrears([]Entry{e}, payments, nil, date("15-02-2025"))
+	if err != nil {
+		t.Fatalf("buildArrears returned error: %v", err)
+	}
+	if len(lines) != 1 || lines[0].Outstanding != 600 || lines[0].DaysOverdue != 45 {
+		t.Fatalf("unexpected lines: %+v", lines)
+	}
+	if lines[0].Bucket != bucketUpTo90 {
+		t.Fatalf("expected bucket %s, got %s", bucketUpTo90, lines[0].Bucket)
+	}
+
+	renters := groupArrears(lines, true)
+	if len(renters) != 1 || renters[0].Total != 600 {
+		t.Fatalf("unexpected renters: %+v", renters)
+	}
+
+	owners := groupArrears(lines, false)
+	if len(owners) != 2 || owners[0].Total != 300 || owners[1].Buckets[bucketUpTo90] != 300 {
+		t.Fatalf("unexpected owners: %+v", owners)
+	}
+}
+
+func TestBuildArrears_IgnoresLaterPayments(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{ID: 1, Rent: 1000, Start: "01-01-2025", End: "31-12-2026", Renters: []RenterDetails{{ID: 3, FirstName: "C"}}}
+	payments := []Payment{
+		{EntryID: 1, Date: "10-01-2025", Amount: 400},
+		{EntryID: 1, Date: "01-03-2025", Amount: 600},
+	}
+
+	lines, err := buildArrears([]Entry{e}, payments, nil, date("15-02-2025"))
+	if err != nil {
+		t.Fatalf("buildArrears returned error: %v", err)
+	}
+	if len(lines) != 1 || lines[0].Outstanding != 600 {
+		t.Fatalf("the payment of March settled February's arrears: %+v", lines)
+	}
+
+	lines, err = buildArrears([]Entry{e}, payments, nil, date("15-03-2025"))
+	if err != nil {
+		t.Fatalf("buildArrears returned error: %v", err)
+	}
+	if len(lines) != 0 {
+		t.Fatalf("expected nothing owed after the second payment, got %+v", lines)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of agreements for the use of the land, not to be confused with the
+// Type of the entry which is the crop
+const (
+	contractLease     = "lease"
+	contractFreeUse   = "free_use"
+	contractSharecrop = "sharecrop"
+	contractFamily    = "family"
+	contractOwned     = "owned"
+)
+
+// In the order they are shown in the forms
+var contractTypes = []string{contractLease, contractFreeUse, contractSharecrop, contractFamily, contractOwned}
+
+var contractTypeLabels = map[string]string{
+	contractLease:     "Μίσθωση",
+	contractFreeUse:   "Χρησιδάνειο",
+	contractSharecrop: "Επίμορτη αγροληψία",
+	contractFamily:    "Χρήση από μέλος οικογένειας",
+	contractOwned:     "Ιδιόκτητο - ιδιοκαλλιέργεια",
+}
+
+// What a type of contract needs and how the reports treat it
+type ContractTypeRules struct {
+	Rent    bool // a cash rent is paid in installments that can fall in arrears
+	Share   bool // a share of the crop is given instead of rent
+	Renters bool // somebody other than the owners farms the land
+	E2      bool // the owners declare it in their Ε2, free ones with no rent
+}
+
+var contractTypeRules = map[string]ContractTypeRules{
+	contractLease:     {Rent: true, Renters: true, E2: true},
+	contractFreeUse:   {Renters: true, E2: true},
+	contractSharecrop: {Share: true, Renters: true, E2: true},
+	contractFamily:    {Renters: true, E2: true},
+	contractOwned:     {},
+}
+
+// The rules of the type of the contract, the ones from before the types are leases
+func (e Entry) Rules() ContractTypeRules {
+	if e.ContractType == "" {
+		return contractTypeRules[contractLease]
+	}
+	return contractTypeRules[e.ContractType]
+}
+
+func contractTypeLabel(t string) string {
+	if t == "" {
+		t = contractLease
+	}
+	return contractTypeLabels[t]
+}
+
+// Checks the fields the type of the contract needs and the ones it can't have
+func validateContractType(e Entry) error {
+	if _, ok := contractTypeRules[e.ContractType]; !ok && e.ContractType != "" {
+		return fmt.Errorf("unknown contract type: %s", e.ContractType)
+	}
+	rules := e.Rules()
+	label := contractTypeLabel(e.ContractType)
+
+	if len(e.Owners) == 0 {
+		return errors.New("the contract needs at least one owner")
+	}
+	switch {
+	case rules.Renters && len(e.Renters) == 0:
+		return fmt.Errorf("%s needs at least one renter", label)
+	case !rules.Renters && len(e.Renters) > 0:
+		return fmt.Errorf("%s has no renters", label)
+	case rules.Rent && e.Rent <= 0:
+		return fmt.Errorf("%s needs a rent", label)
+	case !rules.Rent && e.Rent != 0:
+		return fmt.Errorf("%s has no rent", label)
+	case rules.Share && (e.Share <= 0 || e.Share >= 100):
+		return fmt.Errorf("%s needs a share of the crop between 0 and 100%%", label)
+	case !rules.Share && e.Share != 0:
+		return fmt.Errorf("%s has no share of the crop", label)
+	}
+
+	return nil
+}
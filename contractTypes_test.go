@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateContractType(t *testing.T) {
+	t.Parallel()
+
+	owners := []OwnerDetails{{FirstName: "Α"}}
+	renters := []RenterDetails{{FirstName: "Β"}}
+
+	tests := []struct {
+		name string
+		e    Entry
+		err  string
+	}{
+		{"lease", Entry{ContractType: contractLease, Owners: owners, Renters: renters, Rent: 100}, ""},
+		{"old lease without a type", Entry{Owners: owners, Renters: renters, Rent: 100}, ""},
+		{"lease without rent", Entry{ContractType: contractLease, Owners: owners, Renters: renters}, "needs a rent"},
+		{"no owners", Entry{ContractType: contractLease, Renters: renters, Rent: 100}, "owner"},
+		{"free use", Entry{ContractType: contractFreeUse, Owners: owners, Renters: renters}, ""},
+		{"free use with rent", Entry{ContractType: contractFreeUse, Owners: owners, Renters: renters, Rent: 1}, "has no rent"},
+		{"sharecrop", Entry{ContractType: contractSharecrop, Owners: owners, Renters: renters, Share: 25}, ""},
+		{"sharecrop without share", Entry{ContractType: contractSharecrop, Owners: owners, Renters: renters}, "share of the crop"},
+		{"sharecrop with all of it", Entry{ContractType: contractSharecrop, Owners: owners, Renters: renters, Share: 100}, "share of the crop"},
+		{"family without renter", Entry{ContractType: contractFamily, Owners: owners}, "renter"},
+		{"owned", Entry{ContractType: contractOwned, Owners: owners}, ""},
+		{"owned with renters", Entry{ContractType: contractOwned, Owners: owners, Renters: renters}, "has no renters"},
+		{"lease with share", Entry{ContractType: contractLease, Owners: owners, Renters: renters, Rent: 100, Share: 10}, "has no share"},
+		{"unknown", Entry{ContractType: "barter", Owners: owners}, "unknown"},
+	}
+	for _, tt := range tests {
+		err := validateContractType(tt.e)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: expected an error with %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestContractTypesInReports(t *testing.T) {
+	t.Parallel()
+
+	alice := OwnerDetails{ID: 1, FirstName: "Alice"}
+	entries := []Entry{
+		{ID: 1, Name: "Μίσθωση", Owners: []OwnerDetails{alice}, Rent: 1200, Start: "01-01-2025", End: "31-12-2025"},
+		{ID: 2, Name: "Χρησιδάνειο", ContractType: contractFreeUse, Owners: []OwnerDetails{alice}, Start: "01-01-2025", End: "31-12-2025"},
+		{ID: 3, Name: "Δικό μας", ContractType: contractOwned, Owners: []OwnerDetails{alice}, Start: "01-01-2025", End: "31-12-2025"},
+		{ID: 4, Name: "Επίμορτη", ContractType: contractSharecrop, Share: 30, Owners: []OwnerDetails{alice}, Start: "01-01-2025", End: "31-12-2025"},
+	}
+
+	rows, err := buildE2Rows(alice, entries, nil, 2025)
+	if err != nil {
+		t.Fatalf("buildE2Rows returned error: %v", err)
+	}
+	if len(rows) != 3 || rows[1].Type != "Χρησιδάνειο" || rows[1].Rent != 0 {
+		t.Fatalf("expected everything but the owned land, got %+v", rows)
+	}
+
+	// only the cash lease falls in arrears, even if the others have a rent by mistake
+	entries[1].Rent = 1200
+	lines, err := buildArrears(entries, nil, nil, date("01-01-2026"))
+	if err != nil {
+		t.Fatalf("buildArrears returned error: %v", err)
+	}
+	for _, l := range lines {
+		if l.Entry.ID != 1 {
+			t.Fatalf("unexpected arrears for %d", l.Entry.ID)
+		}
+	}
+	if len(lines) == 0 {
+		t.Fatalf("expected the lease in arrears")
+	}
+}
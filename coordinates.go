@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// What the angle is, it decides the range and the hemisphere letters
+type angleKind int
+
+const (
+	anyAngle angleKind = iota
+	latitudeAngle
+	longitudeAngle
+)
+
+func (k angleKind) String() string {
+	switch k {
+	case latitudeAngle:
+		return "latitude"
+	case longitudeAngle:
+		return "longitude"
+	}
+	return "coordinate"
+}
+
+// The hemisphere letters in English and in Greek (Βόρειο, Νότιο, Ανατολικό,
+// Δυτικό), with the sign and the kind of angle they go with. The Greek Ν is
+// south, the Latin N north.
+var hemispheres = map[rune]struct {
+	sign float64
+	kind angleKind
+}{
+	'N': {1, latitudeAngle}, 'S': {-1, latitudeAngle},
+	'E': {1, longitudeAngle}, 'W': {-1, longitudeAngle},
+	'Β': {1, latitudeAngle}, 'Ν': {-1, latitudeAngle},
+	'Α': {1, longitudeAngle}, 'Δ': {-1, longitudeAngle},
+}
+
+func hemisphere(r rune) (float64, angleKind, bool) {
+	h, ok := hemispheres[unicode.ToUpper(r)]
+	return h.sign, h.kind, ok
+}
+
+// The marks map apps and keyboards use for degrees, minutes, seconds and
+// the minus, all turned into °, ', " and -
+var angleMarks = strings.NewReplacer(
+	"−", "-", "º", "°", "˚", "°",
+	"′", "'", "’", "'", "‘", "'", "´", "'",
+	"″", `"`, "“", `"`, "”", `"`, "''", `"`,
+)
+
+// An angle in decimal degrees (-37.97), degrees and decimal minutes
+// (37 58.2), degrees, minutes and seconds (37°58'12.5"), with a sign or a
+// hemisphere letter in front or at the end (N 37°58', 23.72E). The kind is
+// the one of the hemisphere letter if there is one.
+func parseAngle(s string, kind angleKind) (float64, angleKind, error) {
+	text := strings.TrimSpace(angleMarks.Replace(s))
+	if text == "" {
+		return 0, kind, fmt.Errorf("the %s is empty", kind)
+	}
+
+	sign := 1.0
+	hemi := false
+	takeHemisphere := func(r rune) (bool, error) {
+		hs, hk, ok := hemisphere(r)
+		if !ok {
+			return false, nil
+		}
+		if hemi {
+			return false, fmt.Errorf("%q has two hemisphere letters", s)
+		}
+		if kind != anyAngle && hk != kind {
+			return false, fmt.Errorf("%c is a hemisphere of the %s, not of the %s", r, hk, kind)
+		}
+		sign, kind, hemi = hs, hk, true
+		return true, nil
+	}
+	runes := []rune(text)
+	if ok, err := takeHemisphere(runes[0]); err != nil {
+		return 0, kind, err
+	} else if ok {
+		runes = runes[1:]
+	}
+	if len(runes) > 0 {
+		if ok, err := takeHemisphere(runes[len(runes)-1]); err != nil {
+			return 0, kind, err
+		} else if ok {
+			runes = runes[:len(runes)-1]
+		}
+	}
+	text = strings.TrimSpace(string(runes))
+
+	if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+") {
+		if hemi {
+			return 0, kind, fmt.Errorf("%q has both a sign and a hemisphere letter, use one of them", s)
+		}
+		if text[0] == '-' {
+			sign = -1
+		}
+		text = strings.TrimSpace(text[1:])
+	}
+
+	// a decimal comma, there is no other comma in a single angle
+	text = strings.ReplaceAll(text, ",", ".")
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == '°' || r == '\'' || r == '"' || r == ':' || unicode.IsSpace(r)
+	})
+	if len(fields) == 0 || len(fields) > 3 {
+		return 0, kind, fmt.Errorf("%q is not a %s, expected degrees, minutes and seconds like 37°58'12\" or decimal degrees like 37.97", s, kind)
+	}
+
+	var parts [3]float64
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+			return 0, kind, fmt.Errorf("%q is not a %s, %q is not a number", s, kind, f)
+		}
+		if i < len(fields)-1 && v != math.Trunc(v) {
+			return 0, kind, fmt.Errorf("%q is not a %s, only the last part can have decimals", s, kind)
+		}
+		parts[i] = v
+	}
+	if parts[1] >= 60 {
+		return 0, kind, fmt.Errorf("%q is not a %s, the minutes must be less than 60", s, kind)
+	}
+	if parts[2] >= 60 {
+		return 0, kind, fmt.Errorf("%q is not a %s, the seconds must be less than 60", s, kind)
+	}
+
+	value := sign * (parts[0] + parts[1]/60 + parts[2]/3600)
+	limit := 180.0
+	if kind == latitudeAngle {
+		limit = 90
+	}
+	if math.Abs(value) > limit {
+		return 0, kind, fmt.Errorf("%s %s is out of range, it must be between -%.0f and %.0f", kind, strings.TrimSpace(s), limit, limit)
+	}
+
+	return value, kind, nil
+}
+
+func parseLatitude(s string) (float64, error) {
+	v, _, err := parseAngle(s, latitudeAngle)
+	return v, err
+}
+
+func parseLongitude(s string) (float64, error) {
+	v, _, err := parseAngle(s, longitudeAngle)
+	return v, err
+}
+
+// A latitude and longitude pasted together, as map apps copy them:
+// "37.9838, 23.7275", "37°58'12"N 23°43'39"E", "N 37 58.2 E 23 43.6",
+// "geo:37.98,23.72" or the @37.98,23.72,15z of a map link. The longitude can
+// come first when the hemisphere letters say so.
+func parseLatLon(s string) (Coordinates, error) {
+	text := strings.TrimSpace(angleMarks.Replace(s))
+	// the @lat,lon,zoom of a map link and geo:lat,lon[,alt][;u=..]
+	if i := strings.LastIndex(text, "@"); i >= 0 {
+		text = firstTwo(text[i+1:])
+	} else if rest, ok := strings.CutPrefix(text, "geo:"); ok {
+		if i := strings.IndexAny(rest, ";?"); i >= 0 {
+			rest = rest[:i]
+		}
+		text = firstTwo(rest)
+	}
+
+	first, second, ok := splitPair(text)
+	if !ok {
+		return Coordinates{}, fmt.Errorf("%q is not a latitude and longitude, expected something like 37.9838, 23.7275", s)
+	}
+
+	a, kindA, err := parseAngle(first, anyAngle)
+	if err != nil {
+		return Coordinates{}, err
+	}
+	b, kindB, err := parseAngle(second, anyAngle)
+	if err != nil {
+		return Coordinates{}, err
+	}
+	if kindA == longitudeAngle || kindB == latitudeAngle {
+		a, b, kindA, kindB = b, a, kindB, kindA
+	}
+	if kindA == longitudeAngle || kindB == latitudeAngle {
+		return Coordinates{}, fmt.Errorf("%q has two latitudes or two longitudes", s)
+	}
+	if math.Abs(a) > 90 {
+		return Coordinates{}, fmt.Errorf("latitude %s is out of range, it must be between -90 and 90", strings.TrimSpace(first))
+	}
+
+	return Coordinates{Latitude: a, Longitude: b}, nil
+}
+
+func firstTwo(text string) string {
+	if f := strings.Split(text, ","); len(f) > 2 {
+		return f[0] + "," + f[1]
+	}
+	return text
+}
+
+// The two halves of a pasted pair
+func splitPair(text string) (string, string, bool) {
+	two := func(a, b string) (string, string, bool) {
+		a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+		return a, b, a != "" && b != ""
+	}
+
+	if a, b, ok := strings.Cut(text, ";"); ok {
+		return two(a, b)
+	}
+	switch strings.Count(text, ",") {
+	case 1:
+		a, b, _ := strings.Cut(text, ",")
+		return two(a, b)
+	case 3:
+		// decimal commas on both sides, 37,98, 23,72
+		if a, b, ok := strings.Cut(text, ", "); ok {
+			return two(a, b)
+		}
+	}
+
+	// after a hemisphere letter at the end of the first or before the one at
+	// the start of the second
+	runes := []rune(text)
+	for i := 1; i < len(runes)-1; i++ {
+		if _, _, ok := hemisphere(runes[i]); !ok {
+			continue
+		}
+		if _, _, first := hemisphere(runes[0]); first {
+			return two(string(runes[:i]), string(runes[i:]))
+		}
+		return two(string(runes[:i+1]), string(runes[i+1:]))
+	}
+
+	// as many numbers on both sides
+	fields := strings.Fields(strings.NewReplacer("°", "° ", "'", "' ", `"`, `" `).Replace(text))
+	if len(fields) >= 2 && len(fields)%2 == 0 {
+		half := len(fields) / 2
+		return two(strings.Join(fields[:half], " "), strings.Join(fields[half:], " "))
+	}
+
+	return "", "", false
+}
+
+// The text holds a whole pair, not a single angle
+func isLatLonPair(s string) bool {
+	if strings.TrimSpace(s) == "" {
+		return false
+	}
+	if _, _, err := parseAngle(s, anyAngle); err == nil {
+		return false
+	}
+	_, err := parseLatLon(s)
+	return err == nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseAngle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		kind angleKind
+		want float64
+		err  string
+	}{
+		{"37.9838", latitudeAngle, 37.9838, ""},
+		{"-122.4194", longitudeAngle, -122.4194, ""},
+		{"+23.5", longitudeAngle, 23.5, ""},
+		{"37,9838", latitudeAngle, 37.9838, ""},
+		{"37 58.2", latitudeAngle, 37.97, ""},
+		{"37°58.2'", latitudeAngle, 37.97, ""},
+		{`37°58'12"N`, latitudeAngle, 37 + 58.0/60 + 12.0/3600, ""},
+		{"37º 58′ 12″ N", latitudeAngle, 37 + 58.0/60 + 12.0/3600, ""},
+		{"37:58:12", latitudeAngle, 37 + 58.0/60 + 12.0/3600, ""},
+		{"S 33 52 4.5", latitudeAngle, -(33 + 52.0/60 + 4.5/3600), ""},
+		{"23.72e", longitudeAngle, 23.72, ""},
+		{"W 0°7'", longitudeAngle, -7.0 / 60, ""},
+		{"−8.5", longitudeAngle, -8.5, ""},
+		{"37.5 Β", latitudeAngle, 37.5, ""},
+		{"37.5 Ν", latitudeAngle, -37.5, ""},
+		{"23.7 Α", longitudeAngle, 23.7, ""},
+		{"", latitudeAngle, 0, "empty"},
+		{"abc", latitudeAngle, 0, "not a number"},
+		{"91", latitudeAngle, 0, "between -90 and 90"},
+		{"181", longitudeAngle, 0, "between -180 and 180"},
+		{"37 60", latitudeAngle, 0, "minutes must be less than 60"},
+		{"37 58 60", latitudeAngle, 0, "seconds must be less than 60"},
+		{"37.5 58", latitudeAngle, 0, "only the last part"},
+		{"-37 N", latitudeAngle, 0, "both a sign and a hemisphere"},
+		{"23.7 E", latitudeAngle, 0, "hemisphere of the longitude, not of the latitude"},
+		{"N 37 S", latitudeAngle, 0, "two hemisphere letters"},
+		{"1 2 3 4", latitudeAngle, 0, "expected degrees"},
+	}
+	for _, tt := range tests {
+		got, _, err := parseAngle(tt.in, tt.kind)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%q: unexpected error: %v", tt.in, err)
+		case tt.err == "" && math.Abs(got-tt.want) > 1e-9:
+			t.Errorf("%q: got %f, want %f", tt.in, got, tt.want)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%q: expected an error with %q, got %v", tt.in, tt.err, err)
+		}
+	}
+}
+
+func TestParseLatLon(t *testing.T) {
+	t.Parallel()
+
+	dms := 37 + 58.0/60 + 12.0/3600
+	tests := []struct {
+		in       string
+		lat, lon float64
+		err      string
+	}{
+		{"37.9838, 23.7275", 37.9838, 23.7275, ""},
+		{"37.9838 23.7275", 37.9838, 23.7275, ""},
+		{"-33.8688;151.2093", -33.8688, 151.2093, ""},
+		{"37,9838, 23,7275", 37.9838, 23.7275, ""},
+		{`37°58'12"N 23°43'39"E`, dms, 23 + 43.0/60 + 39.0/3600, ""},
+		{`37°58'12" 23°43'39"`, dms, 23 + 43.0/60 + 39.0/3600, ""},
+		{"N 37 58.2 E 23 43.65", 37.97, 23.7275, ""},
+		{"23.7275E 37.9838N", 37.9838, 23.7275, ""},
+		{"37.9838N, 23.7275W", 37.9838, -23.7275, ""},
+		{"geo:37.9838,23.7275;u=35", 37.9838, 23.7275, ""},
+		{"https://www.google.com/maps/@37.9838,23.7275,15z", 37.9838, 23.7275, ""},
+		{"37.98", 0, 0, "not a latitude and longitude"},
+		{"95, 23", 0, 0, "between -90 and 90"},
+		{"37N, 38N", 0, 0, "two latitudes"},
+		{"37.98, x", 0, 0, "not a number"},
+	}
+	for _, tt := range tests {
+		c, err := parseLatLon(tt.in)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%q: unexpected error: %v", tt.in, err)
+		case tt.err == "" && (math.Abs(c.Latitude-tt.lat) > 1e-9 || math.Abs(c.Longitude-tt.lon) > 1e-9):
+			t.Errorf("%q: got %f, %f, want %f, %f", tt.in, c.Latitude, c.Longitude, tt.lat, tt.lon)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%q: expected an error with %q, got %v", tt.in, tt.err, err)
+		}
+	}
+}
+
+func TestParseCorner_Pasted(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCorner("37.9838, 23.7275", "")
+	if err != nil || c.Latitude != 37.9838 || c.Longitude != 23.7275 {
+		t.Fatalf("parseCorner() = %+v, %v", c, err)
+	}
+	c, err = parseCorner(`37°58'12"N`, "23 43.65")
+	if err != nil || math.Abs(c.Longitude-23.7275) > 1e-9 {
+		t.Fatalf("parseCorner() = %+v, %v", c, err)
+	}
+	if _, err := parseCorner("37.98", ""); err == nil || !strings.Contains(err.Error(), "longitude is empty") {
+		t.Fatalf("expected the missing longitude, got %v", err)
+	}
+
+	if !isLatLonPair("37.98 23.72") || isLatLonPair("37 58") || isLatLonPair("37.98") {
+		t.Fatal("isLatLonPair() is wrong")
+	}
+}
@@ -23,7 +23,8 @@ func NewCenteredButtonsLayout(btnWidth, btnHeight, spacing float32) *CenteredBut
 // Layout positions the objects according to the custom layout
 func (c *CenteredButtonsLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
 	// Calculate total height needed for buttons and spacing
-	totalHeight := (c.buttonHeight * 3) + (c.spacing * 2)
+	n := float32(len(objects))
+	totalHeight := (c.buttonHeight * n) + (c.spacing * (n - 1))
 
 	// Calculate starting Y position to center vertically
 	startY := (size.Height - totalHeight) / 2
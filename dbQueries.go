@@ -7,6 +7,9 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -140,127 +143,2211 @@ func initDB(dbPath string) (*sql.DB, error) {
 
 	log.Printf("Tables created successfully!")
 
+	if err := migrateDB(db); err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+
 	return db, nil
 }
 
-func saveEntry(db *sql.DB, entry Entry) error {
-	tx, err := db.Begin()
+// Brings an existing database up to date with the tables and columns
+// added after the first release. Every statement must be safe to run again.
+func migrateDB(db *sql.DB) error {
+	log.Println("Migrating database...")
+
+	createPayments := `
+		CREATE TABLE IF NOT EXISTS payments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+			owner_id INTEGER REFERENCES ownerDetails(id) ON DELETE SET NULL,
+			renter_id INTEGER REFERENCES renterDetails(id) ON DELETE SET NULL,
+			paidDate TEXT NOT NULL,
+			amount REAL NOT NULL,
+			notes TEXT
+		);
+	`
+	log.Println("Creating table payments...")
+	_, err := db.Exec(createPayments)
+	if err != nil {
+		return fmt.Errorf("error creating payments table: %v", err)
+	}
+
+	createAttachments := `
+		CREATE TABLE IF NOT EXISTS attachments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			data BLOB,
+			created DATETIME NOT NULL
+		);
+	`
+	log.Println("Creating table attachments...")
+	_, err = db.Exec(createAttachments)
+	if err != nil {
+		return fmt.Errorf("error creating attachments table: %v", err)
+	}
+
+	// a receipt stays when its payment is deleted, so its number is never
+	// given again
+	createReceipts := `
+		CREATE TABLE IF NOT EXISTS receipts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			payment_id INTEGER UNIQUE REFERENCES payments(id) ON DELETE SET NULL,
+			series TEXT NOT NULL,
+			year INTEGER NOT NULL,
+			number INTEGER NOT NULL,
+			issued TEXT NOT NULL,
+			attachment_id INTEGER REFERENCES attachments(id) ON DELETE SET NULL,
+			UNIQUE (series, year, number)
+		);
+	`
+	log.Println("Creating table receipts...")
+	_, err = db.Exec(createReceipts)
+	if err != nil {
+		return fmt.Errorf("error creating receipts table: %v", err)
+	}
+	if err := keepDeletedReceipts(db); err != nil {
+		return err
+	}
+
+	createInterestRates := `
+		CREATE TABLE IF NOT EXISTS late_interest_rates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			fromDate TEXT NOT NULL,
+			rate REAL NOT NULL
+		);
+	`
+	log.Println("Creating table late_interest_rates...")
+	_, err = db.Exec(createInterestRates)
+	if err != nil {
+		return fmt.Errorf("error creating late_interest_rates table: %v", err)
+	}
+
+	createExpenses := `
+		CREATE TABLE IF NOT EXISTS expenses (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+			owner_id INTEGER REFERENCES ownerDetails(id) ON DELETE SET NULL,
+			category TEXT NOT NULL,
+			expenseDate TEXT NOT NULL,
+			amount REAL NOT NULL,
+			bearer TEXT NOT NULL,
+			notes TEXT,
+			attachment BLOB
+		);
+	`
+	log.Println("Creating table expenses...")
+	_, err = db.Exec(createExpenses)
+	if err != nil {
+		return fmt.Errorf("error creating expenses table: %v", err)
+	}
+
+	createDeposits := `
+		CREATE TABLE IF NOT EXISTS deposits (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+			kind TEXT NOT NULL,
+			amount REAL NOT NULL,
+			receivedDate TEXT NOT NULL,
+			holder TEXT,
+			conditions TEXT,
+			returnedDate TEXT,
+			notes TEXT
+		);
+	`
+	log.Println("Creating table deposits...")
+	_, err = db.Exec(createDeposits)
+	if err != nil {
+		return fmt.Errorf("error creating deposits table: %v", err)
+	}
+
+	// Existing contracts start as active and get their dated status on the
+	// next refreshStatuses
+	err = addColumnIfMissing(db, "entries", "status", "TEXT NOT NULL DEFAULT 'active'")
+	if err != nil {
+		return err
+	}
+
+	createAmendments := `
+		CREATE TABLE IF NOT EXISTS amendments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+			kind TEXT NOT NULL,
+			effectiveDate TEXT NOT NULL,
+			rent REAL,
+			size REAL,
+			document BLOB,
+			notes TEXT
+		);
+		CREATE TABLE IF NOT EXISTS amendments_owner (
+			amendment_id INTEGER NOT NULL REFERENCES amendments(id) ON DELETE CASCADE,
+			owner_id INTEGER NOT NULL REFERENCES ownerDetails(id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS amendments_renter (
+			amendment_id INTEGER NOT NULL REFERENCES amendments(id) ON DELETE CASCADE,
+			renter_id INTEGER NOT NULL REFERENCES renterDetails(id) ON DELETE CASCADE
+		);
+	`
+	log.Println("Creating tables amendments, amendments_owner and amendments_renter...")
+	_, err = db.Exec(createAmendments)
+	if err != nil {
+		return fmt.Errorf("error creating amendments tables: %v", err)
+	}
+
+	createOverlapOverrides := `
+		CREATE TABLE IF NOT EXISTS overlap_overrides (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+			other_id INTEGER REFERENCES entries(id) ON DELETE SET NULL,
+			reason TEXT NOT NULL,
+			created DATETIME NOT NULL
+		);
+	`
+	log.Println("Creating table overlap_overrides...")
+	_, err = db.Exec(createOverlapOverrides)
+	if err != nil {
+		return fmt.Errorf("error creating overlap_overrides table: %v", err)
+	}
+	// one override for every other contract, the saves before it added one
+	// every time, the last is kept
+	_, err = db.Exec(`
+		DELETE FROM overlap_overrides
+		WHERE other_id IS NOT NULL AND id NOT IN (SELECT MAX(id) FROM overlap_overrides GROUP BY entry_id, other_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS overlap_overrides_pair ON overlap_overrides (entry_id, other_id);`)
+	if err != nil {
+		return fmt.Errorf("error removing the repeated overlap overrides: %v", err)
+	}
+
+	createTemplates := `
+		CREATE TABLE IF NOT EXISTS templates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			body TEXT NOT NULL,
+			created DATETIME NOT NULL
+		);
+	`
+	log.Println("Creating table templates...")
+	_, err = db.Exec(createTemplates)
+	if err != nil {
+		return fmt.Errorf("error creating templates table: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO templates (name, body, created)
+		SELECT ?, ?, ? WHERE NOT EXISTS (SELECT 1 FROM templates)`,
+		defaultTemplateName, defaultTemplateBody, time.Now())
+	if err != nil {
+		return fmt.Errorf("error adding the default template: %v", err)
+	}
+
+	err = addColumnIfMissing(db, "entries", "predecessor_id", "INTEGER REFERENCES entries(id) ON DELETE SET NULL")
+	if err != nil {
+		return err
+	}
+
+	// every number given stays here so it's never given again, even after
+	// the contract is deleted
+	createContractNumbers := `
+		CREATE TABLE IF NOT EXISTS contract_numbers (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER UNIQUE REFERENCES entries(id) ON DELETE SET NULL,
+			series TEXT NOT NULL,
+			year INTEGER NOT NULL,
+			number INTEGER NOT NULL,
+			label TEXT NOT NULL UNIQUE,
+			UNIQUE (series, year, number)
+		);
+	`
+	log.Println("Creating table contract_numbers...")
+	_, err = db.Exec(createContractNumbers)
+	if err != nil {
+		return fmt.Errorf("error creating contract_numbers table: %v", err)
+	}
+	err = addColumnIfMissing(db, "entries", "number", "TEXT")
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS entries_number ON entries(number)`)
+	if err != nil {
+		return fmt.Errorf("error creating the index of the contract numbers: %v", err)
+	}
+	if err := numberExistingEntries(db); err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(db, "entries", "parent_id", "INTEGER REFERENCES entries(id) ON DELETE SET NULL")
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(db, "entries", "contract_type", "TEXT NOT NULL DEFAULT 'lease'")
+	if err != nil {
+		return err
+	}
+	err = addColumnIfMissing(db, "entries", "share", "REAL NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(db, "entries", "notice_months", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	err = addColumnIfMissing(db, "entries", "auto_renew_months", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	err = addColumnIfMissing(db, "entries", "notice_party", "TEXT NOT NULL DEFAULT 'both'")
+	if err != nil {
+		return err
+	}
+	err = addColumnIfMissing(db, "amendments", "endDate", "TEXT")
+	if err != nil {
+		return err
+	}
+
+	createParcels := `
+		CREATE TABLE IF NOT EXISTS parcels (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kaek TEXT,
+			atak INTEGER,
+			area REAL NOT NULL DEFAULT 0,
+			municipality TEXT,
+			notes TEXT
+		);
+		CREATE TABLE IF NOT EXISTS parcel_coordinates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			parcel_id INTEGER NOT NULL REFERENCES parcels(id) ON DELETE CASCADE,
+			latitude REAL NOT NULL,
+			longitude REAL NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS entries_parcel (
+			entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+			parcel_id INTEGER NOT NULL REFERENCES parcels(id) ON DELETE RESTRICT,
+			PRIMARY KEY (entry_id, parcel_id)
+		);
+	`
+	log.Println("Creating tables parcels, parcel_coordinates and entries_parcel...")
+	_, err = db.Exec(createParcels)
+	if err != nil {
+		return fmt.Errorf("error creating parcels tables: %v", err)
+	}
+
+	// the corners are ordered in parts and rings, the ones from before are
+	// the outer ring of a single part in the order they were saved
+	for _, table := range []string{"coordinates", "parcel_coordinates"} {
+		for _, column := range []string{"part", "ring", "seq"} {
+			err = addColumnIfMissing(db, table, column, "INTEGER NOT NULL DEFAULT 0")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// the forms used to save the corners that were left empty as 0,0
+	_, err = db.Exec(`
+		DELETE FROM coordinates WHERE latitude = 0 AND longitude = 0;
+		DELETE FROM parcel_coordinates WHERE latitude = 0 AND longitude = 0;`)
+	if err != nil {
+		return fmt.Errorf("error removing the empty coordinates: %v", err)
+	}
+
+	if err := parcelsFromEntries(db); err != nil {
+		return err
+	}
+
+	// the box around the field of every contract and every parcel in an
+	// R*Tree, for finding them at a point without reading all the corners.
+	// The triggers keep it up with the corners, it is built again from them
+	// on every start in case it fell behind.
+	for _, index := range []struct{ name, table, key string }{
+		{"entry_bounds", "coordinates", "entry_id"},
+		{"parcel_bounds", "parcel_coordinates", "parcel_id"},
+	} {
+		log.Printf("Creating the spatial index %s...", index.name)
+		_, err = db.Exec(boundsIndex(index.name, index.table, index.key))
+		if err != nil {
+			return fmt.Errorf("error creating the spatial index %s: %v", index.name, err)
+		}
+	}
+
+	log.Println("Database migrated successfully!")
+
+	return nil
+}
+
+// Every contract from before the parcels gets a parcel of its own with its
+// KAEK, ATAK, size and coordinates
+func parcelsFromEntries(db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT id, kaek, atak, size
+		FROM entries
+		WHERE NOT EXISTS (SELECT 1 FROM entries_parcel WHERE entry_id = entries.id)
+		ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("error reading the entries without a parcel: %v", err)
+	}
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.ID, &e.KAEK, &e.ATAK, &e.Size); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Close(); err != nil {
+		log.Println("rows.Close() error: ", err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	for i := range entries {
+		entries[i].Coords, err = getCoords(db, entries[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
+
+	for _, e := range entries {
+		if err := linkParcels(tx, e.ID, []Parcel{parcelFromEntry(e)}); err != nil {
+			return fmt.Errorf("error creating the parcel of %d: %v", e.ID, err)
+		}
+	}
+	log.Printf("Created the parcels of %d existing entries", len(entries))
+
+	return tx.Commit()
+}
+
+// Gives the contracts from before the numbering a number with the default
+// scheme and the year they were created, oldest first
+func numberExistingEntries(db *sql.DB) error {
+	// a number that was given once stays with its contract
+	_, err := db.Exec(`
+		UPDATE entries
+		SET number = (SELECT label FROM contract_numbers WHERE entry_id = entries.id)
+		WHERE number IS NULL`)
+	if err != nil {
+		return fmt.Errorf("error restoring the contract numbers: %v", err)
+	}
+
+	rows, err := db.Query(`SELECT id, timestamp FROM entries WHERE number IS NULL ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("error reading the entries without a number: %v", err)
+	}
+
+	type unnumbered struct {
+		id   uint
+		year int
+	}
+	var entries []unnumbered
+	for rows.Next() {
+		var u unnumbered
+		var timestamp time.Time
+		if err := rows.Scan(&u.id, &timestamp); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		u.year = timestamp.Year()
+		if timestamp.IsZero() {
+			u.year = time.Now().Year()
+		}
+		entries = append(entries, u)
+	}
+	if err := rows.Close(); err != nil {
+		log.Println("rows.Close() error: ", err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
+
+	for _, u := range entries {
+		if _, err := assignNumber(tx, u.id, defaultNumberingScheme, u.year); err != nil {
+			return err
+		}
+	}
+	log.Printf("Numbered %d existing entries", len(entries))
+
+	return tx.Commit()
+}
+
+// The R*Tree of the boxes around the corners in table, one for every key,
+// with the triggers that keep it up
+func boundsIndex(index, table, key string) string {
+	box := fmt.Sprintf(`SELECT %[2]s, MIN(latitude), MAX(latitude), MIN(longitude), MAX(longitude) FROM %[1]s`, table, key)
+
+	return fmt.Sprintf(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS %[1]s USING rtree(id, min_lat, max_lat, min_lon, max_lon);
+		CREATE TRIGGER IF NOT EXISTS %[2]s_bounds_insert AFTER INSERT ON %[2]s BEGIN
+			INSERT OR REPLACE INTO %[1]s
+			%[4]s WHERE %[3]s = NEW.%[3]s GROUP BY %[3]s;
+		END;
+		CREATE TRIGGER IF NOT EXISTS %[2]s_bounds_update AFTER UPDATE ON %[2]s BEGIN
+			DELETE FROM %[1]s WHERE id IN (OLD.%[3]s, NEW.%[3]s);
+			INSERT INTO %[1]s
+			%[4]s WHERE %[3]s IN (OLD.%[3]s, NEW.%[3]s) GROUP BY %[3]s;
+		END;
+		CREATE TRIGGER IF NOT EXISTS %[2]s_bounds_delete AFTER DELETE ON %[2]s BEGIN
+			DELETE FROM %[1]s WHERE id = OLD.%[3]s;
+			INSERT INTO %[1]s
+			%[4]s WHERE %[3]s = OLD.%[3]s GROUP BY %[3]s;
+		END;
+		DELETE FROM %[1]s;
+		INSERT INTO %[1]s
+		%[4]s GROUP BY %[3]s;`,
+		index, table, key, box)
+}
+
+// The receipts used to go with their payment and their numbers were given
+// again. SQLite can't change a foreign key, the table is made again with the
+// receipts that are left, the ones whose payment is already gone without it.
+func keepDeletedReceipts(db *sql.DB) error {
+	var schema string
+	err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'receipts'`).Scan(&schema)
+	if err != nil {
+		return fmt.Errorf("error reading the receipts table: %v", err)
+	}
+	if !strings.Contains(schema, "ON DELETE CASCADE") {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
+
+	log.Println("Keeping the receipts of deleted payments...")
+	_, err = tx.Exec(`
+		CREATE TABLE receipts_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			payment_id INTEGER UNIQUE REFERENCES payments(id) ON DELETE SET NULL,
+			series TEXT NOT NULL,
+			year INTEGER NOT NULL,
+			number INTEGER NOT NULL,
+			issued TEXT NOT NULL,
+			attachment_id INTEGER REFERENCES attachments(id) ON DELETE SET NULL,
+			UNIQUE (series, year, number)
+		);
+		INSERT INTO receipts_new (id, payment_id, series, year, number, issued, attachment_id)
+		SELECT id, (SELECT id FROM payments WHERE id = payment_id), series, year, number, issued, attachment_id FROM receipts;
+		DROP TABLE receipts;
+		ALTER TABLE receipts_new RENAME TO receipts;`)
+	if err != nil {
+		return fmt.Errorf("error rebuilding the receipts table: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("error reading the columns of %s: %v", table, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	log.Printf("Adding column %s to %s...", column, table)
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("error adding column %s to %s: %v", column, table, err)
+	}
+
+	return nil
+}
+
+// Columns of the entries table in the order scanEntry expects them
+const entryColumns = `id, name, timestamp, atak, kaek, size, type, rent, startDate, endDate, emisth, status, predecessor_id, number, parent_id, contract_type, share, notice_months, auto_renew_months, notice_party`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row rowScanner, e *Entry) error {
+	var predecessorID, parentID sql.NullInt64
+	var number sql.NullString
+
+	err := row.Scan(&e.ID, &e.Name, &e.Timestamp, &e.ATAK, &e.KAEK, &e.Size, &e.Type, &e.Rent, &e.Start, &e.End, &e.emisth, &e.Status, &predecessorID, &number, &parentID, &e.ContractType, &e.Share, &e.NoticeMonths, &e.AutoRenewMonths, &e.NoticeParty)
+	if err != nil {
+		return err
+	}
+	e.PredecessorID = uint(predecessorID.Int64)
+	e.ParentID = uint(parentID.Int64)
+	e.Number = number.String
+
+	return nil
+}
+
+func saveEntry(db *sql.DB, entry Entry, scheme NumberingScheme) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
+
+	if _, err := insertEntry(tx, entry, scheme); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Inserts the entry with its people and coordinates, numbers it with the
+// scheme and returns its id
+func insertEntry(tx *sql.Tx, entry Entry, scheme NumberingScheme) (int64, error) {
+	var err error
+	if entry.ContractType == "" {
+		entry.ContractType = contractLease
+	}
+	entry.NoticeParty = entry.noticeParty()
+	if entry.Status == "" {
+		entry.Status, err = deriveStatus(statusActive, entry.End, time.Now())
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO entries (name, timestamp, atak, kaek, size, type, rent, startDate, endDate, emisth, status, predecessor_id, parent_id, contract_type, share, notice_months, auto_renew_months, notice_party)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Name, entry.Timestamp, entry.ATAK, entry.KAEK, entry.Size, entry.Type, entry.Rent, entry.Start, entry.End, entry.emisth, entry.Status, nullableID(entry.PredecessorID), nullableID(entry.ParentID), entry.ContractType, entry.Share, entry.NoticeMonths, entry.AutoRenewMonths, entry.NoticeParty)
+	if err != nil {
+		return 0, err
+	}
+
+	entryID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	year := time.Now().Year()
+	if !entry.Timestamp.IsZero() {
+		year = entry.Timestamp.Year()
+	}
+	if _, err := assignNumber(tx, uint(entryID), scheme, year); err != nil {
+		return 0, err
+	}
+
+	// get or create owner(s)
+	for _, o := range entry.Owners {
+		ownerID, err := getOrCreateOwner(tx, o)
+		if err != nil {
+			return 0, err
+		}
+
+		// link owner to entry on the junction table
+		_, err = tx.Exec(`
+		INSERT OR IGNORE INTO entries_owner (entry_id, owner_id)
+		VALUES (?, ?)`,
+			entryID, ownerID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// get or create renter(s)
+	for _, r := range entry.Renters {
+		renterID, err := getOrCreateRenters(tx, r)
+		if err != nil {
+			return 0, err
+		}
+
+		// link owner to entry on the junction tablle
+		_, err = tx.Exec(`
+		INSERT OR IGNORE INTO entries_renter (entry_id, renter_id)
+		VALUES (?, ?)`,
+			entryID, renterID)
+		if err != nil {
+			return 0, err
+		}
+
+	}
+
+	if err := insertCoords(tx, uint(entryID), entry.Coords); err != nil {
+		return 0, err
+	}
+
+	if err := insertOverrides(tx, uint(entryID), entry.Overrides); err != nil {
+		return 0, err
+	}
+
+	if err := linkParcels(tx, uint(entryID), entryParcels(entry)); err != nil {
+		return 0, err
+	}
+	if err := syncEntryShape(tx, uint(entryID), entry.Coords); err != nil {
+		return 0, err
+	}
+
+	return entryID, nil
+}
+
+// Saves the corners of the contract numbered in the order of their parts and
+// rings, the missing ones (0,0) are not saved
+func insertCoords(tx *sql.Tx, entryID uint, coords []Coordinates) error {
+	for _, c := range numberShape(shapeRings(coords)) {
+		if c.Latitude == 0 && c.Longitude == 0 {
+			continue
+		}
+		_, err := tx.Exec(`
+			INSERT INTO coordinates (entry_id, latitude, longitude, part, ring, seq)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			entryID, c.Latitude, c.Longitude, c.Part, c.Ring, c.Seq)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getOrCreateOwner(tx *sql.Tx, o OwnerDetails) (int64, error) {
+	var ownerID int64
+
+	query := `SELECT id FROM ownerDetails WHERE firstName = ? AND lastName = ?`
+	err := tx.QueryRow(query, o.FirstName, o.LastName).Scan(&ownerID)
+	if err == nil {
+		return ownerID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO ownerDetails (firstName, lastName, fathersName, afm, adt, e9, homeAddress, phoneNumber, email, accountantInfo, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		o.FirstName, o.LastName, o.FathersName, o.AFM, o.ADT, o.E9, o.HomeAddress, o.PhoneNumber, o.Email, o.AccountantInfo, o.Notes)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func getOrCreateRenters(tx *sql.Tx, r RenterDetails) (int64, error) {
+	var renterID int64
+
+	query := `SELECT id FROM renterDetails WHERE firstName = ? AND lastName = ?`
+	err := tx.QueryRow(query, r.FirstName, r.LastName).Scan(&renterID)
+	if err == nil {
+		return renterID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO renterDetails (firstName, lastName, fathersName, afm, adt, e9, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		r.FirstName, r.LastName, r.FathersName, r.AFM, r.ADT, r.E9, r.Notes)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// Corrects the contract as it was signed, changes that apply from a date on
+// are amendments (see amendEntry)
+func updateEntry(db *sql.DB, entry Entry) error {
+	return amendEntry(db, entry, nil)
+}
+
+// Updates the contract and adds the amendments in one go
+func amendEntry(db *sql.DB, entry Entry, amendments []Amendment) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
+
+	if err := updateEntryTx(tx, entry); err != nil {
+		return err
+	}
+	for _, a := range amendments {
+		if _, err := insertAmendment(tx, a); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func updateEntryTx(tx *sql.Tx, entry Entry) error {
+	// The status isn't edited with the rest, it only follows the new dates
+	err := tx.QueryRow(`SELECT status FROM entries WHERE id = ?`, entry.ID).Scan(&entry.Status)
+	if err != nil {
+		return err
+	}
+	status, err := entryStatus(entry, time.Now())
+	if err != nil {
+		return err
+	}
+	if entry.ContractType == "" {
+		entry.ContractType = contractLease
+	}
+
+	_, err = tx.Exec(`
+		UPDATE entries
+		SET name = ?, timestamp = ?, atak = ?, kaek = ?, size = ?, type = ?, rent = ?, startDate = ?, endDate = ?, emisth = ?, status = ?, parent_id = ?, contract_type = ?, share = ?,
+			notice_months = ?, auto_renew_months = ?, notice_party = ?
+		WHERE id = ?`,
+		entry.Name, entry.Timestamp, entry.ATAK, entry.KAEK, entry.Size, entry.Type, entry.Rent, entry.Start, entry.End, entry.emisth, status, nullableID(entry.ParentID), entry.ContractType, entry.Share,
+		entry.NoticeMonths, entry.AutoRenewMonths, entry.noticeParty(), entry.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM entries_owner WHERE entry_id = ?`,
+		entry.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range entry.Owners {
+		ownerID, err := getOrCreateOwner(tx, o)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO entries_owner (entry_id, owner_id)
+			VALUES (?, ?)`,
+			entry.ID, ownerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM entries_renter WHERE entry_id = ?`,
+		entry.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range entry.Renters {
+		renterID, err := getOrCreateRenters(tx, r)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO entries_renter (entry_id, renter_id)
+			VALUES (?, ?)`,
+			entry.ID, renterID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM coordinates WHERE entry_id = ?`,
+		entry.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertCoords(tx, entry.ID, entry.Coords); err != nil {
+		return err
+	}
+
+	if err := insertOverrides(tx, entry.ID, entry.Overrides); err != nil {
+		return err
+	}
+
+	// an edit that took all the parcels off gets one from its own KAEK, like
+	// a new contract
+	if err := linkParcels(tx, entry.ID, entryParcels(entry)); err != nil {
+		return err
+	}
+
+	return syncEntryShape(tx, entry.ID, entry.Coords)
+}
+
+func getAllEntries(db *sql.DB) ([]Entry, error) {
+	var entries []Entry
+
+	rows, err := db.Query(`SELECT ` + entryColumns + ` FROM entries`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	for rows.Next() {
+		var e Entry
+
+		err := scanEntry(rows, &e)
+		if err != nil {
+			return nil, err
+		}
+
+		// get the owners for the entry
+		ownerRows, err := db.Query(`
+			SELECT o.id, o.firstName, o.lastName, o.fathersName, o.afm, o.adt, o.e9, o.homeAddress, o.phoneNumber, o.email, o.accountantInfo, o.notes 
+			FROM ownerDetails o
+			JOIN entries_owner eo ON o.id = eo.owner_id
+			WHERE eo.entry_id = ?`,
+			e.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for ownerRows.Next() {
+			var o OwnerDetails
+
+			err := ownerRows.Scan(&o.ID, &o.FirstName, &o.LastName, &o.FathersName, &o.AFM, &o.ADT, &o.E9, &o.HomeAddress, &o.PhoneNumber, &o.Email, &o.AccountantInfo, &o.Notes)
+			if err != nil {
+				if err := ownerRows.Close(); err != nil {
+					log.Println("ownerRows.Close() error: ", err)
+				}
+				return nil, err
+			}
+			e.Owners = append(e.Owners, o)
+		}
+		if err := ownerRows.Close(); err != nil {
+			log.Println("ownerRows.Close() error: ", err)
+		}
+
+		// get the renters for the entry
+		renterRows, err := db.Query(`
+			SELECT r.id, r.firstName, r.lastName, r.fathersName, r.afm, r.adt, r.e9, r.notes
+			FROM renterDetails r
+			JOIN entries_renter er ON r.id = er.renter_id
+			WHERE er.entry_id = ?`,
+			e.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for renterRows.Next() {
+			var r RenterDetails
+
+			err := renterRows.Scan(&r.ID, &r.FirstName, &r.LastName, &r.FathersName, &r.AFM, &r.ADT, &r.E9, &r.Notes)
+			if err != nil {
+				if err := renterRows.Close(); err != nil {
+					log.Println("renterRows.Close() error: ", err)
+				}
+				return nil, err
+			}
+			e.Renters = append(e.Renters, r)
+		}
+		if err := renterRows.Close(); err != nil {
+			log.Println("renterRows.Close() error: ", err)
+		}
+
+		// get the coordinates for the entry
+		e.Coords, err = getCoords(db, e)
+		if err != nil {
+			return nil, err
+		}
+
+		e.Amendments, err = getAmendments(db, e.ID)
+		if err != nil {
+			return nil, err
+		}
+		e.Parcels, err = getEntryParcels(db, e.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func getEntry(db *sql.DB, id uint) (Entry, error) {
+	var e Entry
+
+	row := db.QueryRow(`
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE id = ?`, id)
+	err := scanEntry(row, &e)
+	if err != nil {
+		return e, err
+	}
+
+	// get Owners
+	e.Owners, err = getOwners(db, e)
+	if err != nil {
+		return e, err
+	}
+
+	// get Renters
+	e.Renters, err = getRenters(db, e)
+	if err != nil {
+		return e, err
+	}
+
+	// get coordinates
+	e.Coords, err = getCoords(db, e)
+	if err != nil {
+		return e, err
+	}
+
+	e.Amendments, err = getAmendments(db, e.ID)
+	if err != nil {
+		return e, err
+	}
+	e.Parcels, err = getEntryParcels(db, e.ID)
+	if err != nil {
+		return e, err
+	}
+
+	return e, nil
+}
+
+func getOwners(db *sql.DB, e Entry) ([]OwnerDetails, error) {
+	var owners []OwnerDetails
+
+	rows, err := db.Query(`
+		SELECT o.id, o.firstName, o.lastName, o.fathersName, o.afm, o.adt, o.e9, o.homeAddress, o.phoneNumber, o.email, o.accountantInfo, o.notes
+		FROM ownerDetails o
+		JOIN entries_owner eo ON o.id = eo.owner_id
+		WHERE eo.entry_id = ?`,
+		e.ID)
+	if err != nil {
+		return owners, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	for rows.Next() {
+		var o OwnerDetails
+
+		err := rows.Scan(&o.ID, &o.FirstName, &o.LastName, &o.FathersName, &o.AFM, &o.ADT, &o.E9, &o.HomeAddress, &o.PhoneNumber, &o.Email, &o.AccountantInfo, &o.Notes)
+		if err != nil {
+			return owners, err
+		}
+		owners = append(owners, o)
+	}
+
+	return owners, nil
+}
+
+func getRenters(db *sql.DB, e Entry) ([]RenterDetails, error) {
+	var renters []RenterDetails
+
+	rows, err := db.Query(`
+		SELECT r.id, r.firstName, r.lastName, r.fathersName, r.afm, r.adt, r.e9, r.notes
+		FROM renterDetails r
+		JOIN entries_renter er ON r.id = er.renter_id
+		WHERE er.entry_id = ?`,
+		e.ID)
+	if err != nil {
+		return renters, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	for rows.Next() {
+		var r RenterDetails
+
+		err := rows.Scan(&r.ID, &r.FirstName, &r.LastName, &r.FathersName, &r.AFM, &r.ADT, &r.E9, &r.Notes)
+		if err != nil {
+			return renters, err
+		}
+		renters = append(renters, r)
+	}
+
+	return renters, nil
+}
+
+func getCoords(db *sql.DB, e Entry) ([]Coordinates, error) {
+	var coordinates []Coordinates
+
+	rows, err := db.Query(`
+		SELECT id, entry_id, latitude, longitude, part, ring, seq
+		FROM coordinates
+		WHERE entry_id = ?
+		ORDER BY part, ring, seq, id`,
+		e.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	for rows.Next() {
+		var c Coordinates
+
+		err := rows.Scan(&c.ID, &c.EntryID, &c.Latitude, &c.Longitude, &c.Part, &c.Ring, &c.Seq)
+		if err != nil {
+			return nil, err
+		}
+		coordinates = append(coordinates, c)
+	}
+
+	return coordinates, nil
+}
+
+func delEntry(db *sql.DB, id uint) error {
+	if id <= 0 {
+		return errors.New("invalid entry id")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
+
+	var exists bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM entries WHERE id = ?)", id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("entry not found")
+	}
+
+	res, err := tx.Exec("DELETE FROM entries WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("entry not found")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func getAllOwners(db *sql.DB) ([]OwnerDetails, error) {
+	var owners []OwnerDetails
+
+	rows, err := db.Query(`SELECT * FROM ownerDetails`)
+	if err != nil {
+		return owners, err
+	}
+
+	for rows.Next() {
+		var o OwnerDetails
+
+		err := rows.Scan(&o.ID, &o.FirstName, &o.LastName, &o.FathersName, &o.AFM, &o.ADT, &o.E9, &o.HomeAddress, &o.PhoneNumber, &o.Email, &o.AccountantInfo, &o.Notes)
+		if err != nil {
+			return owners, err
+		}
+		owners = append(owners, o)
+	}
+
+	return owners, nil
+}
+
+func getAllRenters(db *sql.DB) ([]RenterDetails, error) {
+	var renters []RenterDetails
+
+	rows, err := db.Query(`SELECT * FROM renterDetails`)
+	if err != nil {
+		return renters, err
+	}
+
+	for rows.Next() {
+		var r RenterDetails
+
+		err := rows.Scan(&r.ID, &r.FirstName, &r.LastName, &r.FathersName, &r.AFM, &r.ADT, &r.E9, &r.Notes)
+		if err != nil {
+			return renters, err
+		}
+		renters = append(renters, r)
+	}
+
+	return renters, nil
+}
+
+func GetOwnerEntries(db *sql.DB, o OwnerDetails) ([]Entry, error) {
+	var entries []Entry
+
+	rows, err := db.Query(`
+		SELECT e.id, e.name, e.timestamp, e.atak, e.kaek, e.size, e.type, e.rent, e.startDate, e.endDate
+		FROM entries e
+		JOIN entries_owner eo ON e.id = eo.entry_id
+		WHERE eo.owner_id = ?`,
+		o.ID)
+	if err != nil {
+		return entries, err
+	}
+
+	for rows.Next() {
+		var e Entry
+
+		err := rows.Scan(&e.ID, &e.Name, &e.Timestamp, &e.ATAK, &e.KAEK, &e.Size, &e.Type, &e.Rent, &e.Start, &e.End)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func GetRenterEntries(db *sql.DB, r RenterDetails) ([]Entry, error) {
+	var entries []Entry
+
+	rows, err := db.Query(`
+		SELECT e.id, e.name, e.timestamp, e.atak, e.kaek, e.size, e.type, e.rent, e.startDate, e.endDate
+		FROM entries e
+		JOIN entries_renter er ON e.id = er.entry_id
+		WHERE er.renter_id = ?`,
+		r.ID)
+	if err != nil {
+		return entries, err
+	}
+
+	for rows.Next() {
+		var e Entry
+
+		err := rows.Scan(&e.ID, &e.Name, &e.Timestamp, &e.ATAK, &e.KAEK, &e.Size, &e.Type, &e.Rent, &e.Start, &e.End)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func updateOwner(db *sql.DB, o OwnerDetails) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
+
+	res, err := tx.Exec(`
+		UPDATE ownerDetails
+		SET firstName = ?, lastName = ?, fathersName = ?, afm = ?, adt = ?, e9 = ?, homeAddress = ?, phoneNumber = ?, email = ?, accountantInfo = ?, notes = ?
+		WHERE id = ?`,
+		o.FirstName, o.LastName, o.FathersName, o.AFM, o.ADT, o.E9, o.HomeAddress,
+		o.PhoneNumber, o.Email, o.AccountantInfo, o.Notes, o.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("owner with id %d not found", o.ID)
+	}
+
+	return tx.Commit()
+}
+
+func updateRenter(db *sql.DB, r RenterDetails) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
+
+	res, err := tx.Exec(`
+		UPDATE renterDetails
+		SET firstName = ?, lastName = ?, fathersName = ?, afm = ?, adt = ?, e9 = ?, notes = ?
+		WHERE id = ?`,
+		r.FirstName, r.LastName, r.FathersName, r.AFM, r.ADT, r.E9, r.Notes, r.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("renter with id %d not found", r.ID)
+	}
+
+	return tx.Commit()
+}
+
+func deleteOwner(db *sql.DB, id int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM entries_owner WHERE owner_id = ?`, id)
+	if err != nil {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+		return fmt.Errorf("error deleting from entries_owner: %v", err)
+	}
+
+	res, err := tx.Exec(`DELETE FROM ownerDetails WHERE id = ?`, id)
+	if err != nil {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+		return fmt.Errorf("error deleting owner: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+		return fmt.Errorf("error checking rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+		return fmt.Errorf("owner with id %d not found", id)
+	}
+
+	return tx.Commit()
+}
+
+func deleteRenter(db *sql.DB, id int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM entries_renter WHERE renter_id = ?`, id)
+	if err != nil {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+		return fmt.Errorf("error deleting from entries_renter: %v", err)
+	}
+
+	res, err := tx.Exec(`DELETE FROM renterDetails WHERE id = ?`, id)
+	if err != nil {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+		return fmt.Errorf("error deleting renter: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+		return fmt.Errorf("error checking rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+		return fmt.Errorf("renter with id %d not found", id)
+	}
+
+	return tx.Commit()
+}
+
+func getOwner(db *sql.DB, id uint) (OwnerDetails, error) {
+	var o OwnerDetails
+
+	err := db.QueryRow(`SELECT * FROM ownerDetails WHERE id = ?`, id).Scan(
+		&o.ID, &o.FirstName, &o.LastName, &o.FathersName, &o.AFM, &o.ADT, &o.E9,
+		&o.HomeAddress, &o.PhoneNumber, &o.Email, &o.AccountantInfo, &o.Notes)
+	if err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+func getRenter(db *sql.DB, id uint) (RenterDetails, error) {
+	var r RenterDetails
+
+	err := db.QueryRow(`SELECT * FROM renterDetails WHERE id = ?`, id).Scan(
+		&r.ID, &r.FirstName, &r.LastName, &r.FathersName, &r.AFM, &r.ADT, &r.E9, &r.Notes)
+	if err != nil {
+		return r, err
+	}
+
+	return r, err
+}
+
+func getYearRange(db *sql.DB) (oldestYear, newestYear int, err error) {
+	query := `
+		SELECT 
+            MIN(year) AS oldest,
+            MAX(year) AS newest
+        FROM (
+            SELECT substr(startDate, 7, 4) AS year FROM entries
+            UNION ALL
+            SELECT substr(endDate,   7, 4) AS year FROM entries
+            UNION ALL
+            SELECT substr(endDate,   7, 4) AS year FROM amendments WHERE kind = 'extension'
+        )
+        WHERE year GLOB '[0-9][0-9][0-9][0-9]'   -- basic protection against bad data
+	`
+
+	var oldest, newest sql.NullInt64
+
+	err = db.QueryRow(query).Scan(&oldest, &newest)
+	if err == sql.ErrNoRows || !oldest.Valid && !newest.Valid {
+		return 0, 0, fmt.Errorf("no valid date entries found")
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !oldest.Valid {
+		return 0, 0, fmt.Errorf("no valid oldest year found")
+	}
+
+	if !newest.Valid {
+		return 0, 0, fmt.Errorf("no valid newest year found")
+	}
+
+	return int(oldest.Int64), int(newest.Int64), nil
+}
+
+func getAllEntriesByYear(db *sql.DB, year string) ([]Entry, error) {
+	var entries []Entry
+
+	// not very safe in case if bad date format
+	query := `
+		SELECT ` + entryColumns + `
+		FROM entries
+		WHERE substr(startDate, 7, 4) <= ? AND (substr(endDate, 7, 4) >= ? OR EXISTS (
+			SELECT 1 FROM amendments a
+			WHERE a.entry_id = entries.id AND a.kind = 'extension' AND substr(a.endDate, 7, 4) >= ?))
+		ORDER BY startDate ASC
+	`
+
+	rows, err := db.Query(query, year, year, year)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get entries of the year %s: %v", year, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	for rows.Next() {
+		var e Entry
+
+		err := scanEntry(rows, &e)
+		if err != nil {
+			return nil, err
+		}
+
+		// get the owners for the entry
+		ownerRows, err := db.Query(`
+			SELECT o.id, o.firstName, o.lastName, o.fathersName, o.afm, o.adt, o.e9, o.homeAddress, o.phoneNumber, o.email, o.accountantInfo, o.notes 
+			FROM ownerDetails o
+			JOIN entries_owner eo ON o.id = eo.owner_id
+			WHERE eo.entry_id = ?`,
+			e.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for ownerRows.Next() {
+			var o OwnerDetails
+
+			err := ownerRows.Scan(&o.ID, &o.FirstName, &o.LastName, &o.FathersName, &o.AFM, &o.ADT, &o.E9, &o.HomeAddress, &o.PhoneNumber, &o.Email, &o.AccountantInfo, &o.Notes)
+			if err != nil {
+				if err := ownerRows.Close(); err != nil {
+					log.Println("ownerRows.Close() error: ", err)
+				}
+				return nil, err
+			}
+			e.Owners = append(e.Owners, o)
+		}
+		if err := ownerRows.Close(); err != nil {
+			log.Println("ownerRows.Close() error: ", err)
+		}
+
+		// get the renters for the entry
+		renterRows, err := db.Query(`
+			SELECT r.id, r.firstName, r.lastName, r.fathersName, r.afm, r.adt, r.e9, r.notes
+			FROM renterDetails r
+			JOIN entries_renter er ON r.id = er.renter_id
+			WHERE er.entry_id = ?`,
+			e.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for renterRows.Next() {
+			var r RenterDetails
+
+			err := renterRows.Scan(&r.ID, &r.FirstName, &r.LastName, &r.FathersName, &r.AFM, &r.ADT, &r.E9, &r.Notes)
+			if err != nil {
+				if err := renterRows.Close(); err != nil {
+					log.Println("renterRows.Close() error: ", err)
+				}
+				return nil, err
+			}
+			e.Renters = append(e.Renters, r)
+		}
+		if err := renterRows.Close(); err != nil {
+			log.Println("renterRows.Close() error: ", err)
+		}
+
+		// get the coordinates for the entry
+		e.Coords, err = getCoords(db, e)
+		if err != nil {
+			return nil, err
+		}
+
+		e.Amendments, err = getAmendments(db, e.ID)
+		if err != nil {
+			return nil, err
+		}
+		e.Parcels, err = getEntryParcels(db, e.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		// ended early, before the year
+		if end, err := parseDate(e.ActualEnd()); err == nil && strconv.Itoa(end.Year()) < year {
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// sqlite wants NULL and not 0 for the optional foreign keys
+func nullableID(id uint) any {
+	if id == 0 {
+		return nil
+	}
+
+	return id
+}
+
+func savePayment(db *sql.DB, p Payment) error {
+	if p.EntryID == 0 {
+		return errors.New("payment without a contract")
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO payments (entry_id, owner_id, renter_id, paidDate, amount, notes)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		p.EntryID, nullableID(p.OwnerID), nullableID(p.RenterID), p.Date, p.Amount, p.Notes)
+	if err != nil {
+		return fmt.Errorf("error saving payment: %v", err)
+	}
+
+	return nil
+}
+
+func delPayment(db *sql.DB, id uint) error {
+	res, err := db.Exec(`DELETE FROM payments WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("payment with id %d not found", id)
+	}
+
+	return nil
+}
+
+func scanPayments(rows *sql.Rows) ([]Payment, error) {
+	var payments []Payment
+
+	for rows.Next() {
+		var p Payment
+		var ownerID, renterID sql.NullInt64
+		var notes sql.NullString
+
+		err := rows.Scan(&p.ID, &p.EntryID, &ownerID, &renterID, &p.Date, &p.Amount, &notes)
+		if err != nil {
+			return nil, err
+		}
+		p.OwnerID = uint(ownerID.Int64)
+		p.RenterID = uint(renterID.Int64)
+		p.Notes = notes.String
+
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+func getPayments(db *sql.DB, entryID uint) ([]Payment, error) {
+	rows, err := db.Query(`
+		SELECT id, entry_id, owner_id, renter_id, paidDate, amount, notes
+		FROM payments
+		WHERE entry_id = ?
+		ORDER BY substr(paidDate, 7, 4), substr(paidDate, 4, 2), substr(paidDate, 1, 2)`,
+		entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	return scanPayments(rows)
+}
+
+func getAllPayments(db *sql.DB) ([]Payment, error) {
+	rows, err := db.Query(`
+		SELECT id, entry_id, owner_id, renter_id, paidDate, amount, notes
+		FROM payments`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	return scanPayments(rows)
+}
+
+func getPaymentsByYear(db *sql.DB, year string) ([]Payment, error) {
+	rows, err := db.Query(`
+		SELECT id, entry_id, owner_id, renter_id, paidDate, amount, notes
+		FROM payments
+		WHERE substr(paidDate, 7, 4) = ?`,
+		year)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get payments of the year %s: %v", year, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	return scanPayments(rows)
+}
+
+func getAttachments(db *sql.DB, entryID uint) ([]Attachment, error) {
+	var attachments []Attachment
+
+	rows, err := db.Query(`
+		SELECT id, entry_id, name, data, created
+		FROM attachments
+		WHERE entry_id = ?
+		ORDER BY created`,
+		entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	for rows.Next() {
+		var a Attachment
+
+		err := rows.Scan(&a.ID, &a.EntryID, &a.Name, &a.Data, &a.Created)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+func getAttachment(db *sql.DB, id uint) (Attachment, error) {
+	var a Attachment
+
+	err := db.QueryRow(`
+		SELECT id, entry_id, name, data, created
+		FROM attachments
+		WHERE id = ?`,
+		id).Scan(&a.ID, &a.EntryID, &a.Name, &a.Data, &a.Created)
+	if err != nil {
+		return a, err
+	}
+
+	return a, nil
+}
+
+func saveAttachment(tx *sql.Tx, a Attachment) (int64, error) {
+	res, err := tx.Exec(`
+		INSERT INTO attachments (entry_id, name, data, created)
+		VALUES (?, ?, ?, ?)`,
+		a.EntryID, a.Name, a.Data, a.Created)
+	if err != nil {
+		return 0, fmt.Errorf("error saving attachment: %v", err)
+	}
+
+	return res.LastInsertId()
+}
+
+func delAttachment(db *sql.DB, id uint) error {
+	res, err := db.Exec(`DELETE FROM attachments WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("attachment with id %d not found", id)
+	}
+
+	return nil
+}
+
+// ok is false when no receipt was issued for the payment yet
+func getReceiptForPayment(db *sql.DB, paymentID uint) (r Receipt, ok bool, err error) {
+	var attachmentID sql.NullInt64
+
+	err = db.QueryRow(`
+		SELECT id, payment_id, series, year, number, issued, attachment_id
+		FROM receipts
+		WHERE payment_id = ?`,
+		paymentID).Scan(&r.ID, &r.PaymentID, &r.Series, &r.Year, &r.Number, &r.Issued, &attachmentID)
+	if err == sql.ErrNoRows {
+		return r, false, nil
+	}
+	if err != nil {
+		return r, false, err
+	}
+	r.AttachmentID = uint(attachmentID.Int64)
+
+	return r, true, nil
+}
+
+// Gives the payment the next number of the series for the year of issue,
+// render builds the document that gets attached to the contract.
+func createReceipt(db *sql.DB, p Payment, series, issued string, render func(Receipt) ([]byte, error)) (Receipt, error) {
+	r := Receipt{PaymentID: p.ID, Series: series, Issued: issued}
+
+	issuedDate, err := parseDate(issued)
+	if err != nil {
+		return r, err
+	}
+	r.Year = issuedDate.Year()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return r, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
+
+	err = tx.QueryRow(`
+		SELECT COALESCE(MAX(number), 0) + 1
+		FROM receipts
+		WHERE series = ? AND year = ?`,
+		r.Series, r.Year).Scan(&r.Number)
+	if err != nil {
+		return r, err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO receipts (payment_id, series, year, number, issued)
+		VALUES (?, ?, ?, ?, ?)`,
+		r.PaymentID, r.Series, r.Year, r.Number, r.Issued)
+	if err != nil {
+		return r, fmt.Errorf("error saving receipt: %v", err)
+	}
+	receiptID, err := res.LastInsertId()
+	if err != nil {
+		return r, err
+	}
+	r.ID = uint(receiptID)
+
+	data, err := render(r)
+	if err != nil {
+		return r, err
+	}
+
+	attachmentID, err := saveAttachment(tx, Attachment{
+		EntryID: p.EntryID,
+		Name:    receiptFileName(r),
+		Data:    data,
+		Created: time.Now(),
+	})
+	if err != nil {
+		return r, err
+	}
+	r.AttachmentID = uint(attachmentID)
+
+	_, err = tx.Exec(`UPDATE receipts SET attachment_id = ? WHERE id = ?`, attachmentID, r.ID)
+	if err != nil {
+		return r, err
+	}
+
+	return r, tx.Commit()
+}
+
+func getInterestRates(db *sql.DB) ([]InterestRate, error) {
+	var rates []InterestRate
+
+	rows, err := db.Query(`
+		SELECT id, fromDate, rate
+		FROM late_interest_rates
+		ORDER BY substr(fromDate, 7, 4), substr(fromDate, 4, 2), substr(fromDate, 1, 2)`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	for rows.Next() {
+		var r InterestRate
+
+		if err := rows.Scan(&r.ID, &r.From, &r.Rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, r)
+	}
+
+	return rates, rows.Err()
+}
+
+func saveInterestRate(db *sql.DB, r InterestRate) error {
+	if _, err := parseDate(r.From); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO late_interest_rates (fromDate, rate)
+		VALUES (?, ?)`,
+		r.From, r.Rate)
+	if err != nil {
+		return fmt.Errorf("error saving interest rate: %v", err)
+	}
+
+	return nil
+}
+
+func delInterestRate(db *sql.DB, id uint) error {
+	_, err := db.Exec(`DELETE FROM late_interest_rates WHERE id = ?`, id)
+
+	return err
+}
+
+func saveExpense(db *sql.DB, e Expense) error {
+	if e.EntryID == 0 {
+		return errors.New("expense without a contract")
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO expenses (entry_id, owner_id, category, expenseDate, amount, bearer, notes, attachment)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.EntryID, nullableID(e.OwnerID), e.Category, e.Date, e.Amount, e.Bearer, e.Notes, e.Attachment)
+	if err != nil {
+		return fmt.Errorf("error saving expense: %v", err)
+	}
+
+	return nil
+}
+
+func delExpense(db *sql.DB, id uint) error {
+	res, err := db.Exec(`DELETE FROM expenses WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("expense with id %d not found", id)
+	}
+
+	return nil
+}
+
+func scanExpenses(rows *sql.Rows) ([]Expense, error) {
+	var expenses []Expense
+
+	for rows.Next() {
+		var e Expense
+		var ownerID sql.NullInt64
+		var notes sql.NullString
+
+		err := rows.Scan(&e.ID, &e.EntryID, &ownerID, &e.Category, &e.Date, &e.Amount, &e.Bearer, &notes, &e.Attachment)
+		if err != nil {
+			return nil, err
+		}
+		e.OwnerID = uint(ownerID.Int64)
+		e.Notes = notes.String
+
+		expenses = append(expenses, e)
+	}
+
+	return expenses, rows.Err()
+}
+
+func getExpenses(db *sql.DB, entryID uint) ([]Expense, error) {
+	rows, err := db.Query(`
+		SELECT id, entry_id, owner_id, category, expenseDate, amount, bearer, notes, attachment
+		FROM expenses
+		WHERE entry_id = ?
+		ORDER BY substr(expenseDate, 7, 4), substr(expenseDate, 4, 2), substr(expenseDate, 1, 2)`,
+		entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	return scanExpenses(rows)
+}
+
+func getExpensesByYear(db *sql.DB, year string) ([]Expense, error) {
+	rows, err := db.Query(`
+		SELECT id, entry_id, owner_id, category, expenseDate, amount, bearer, notes, attachment
+		FROM expenses
+		WHERE substr(expenseDate, 7, 4) = ?`,
+		year)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get expenses of the year %s: %v", year, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	return scanExpenses(rows)
+}
+
+func saveDeposit(db *sql.DB, d Deposit) error {
+	if d.EntryID == 0 {
+		return errors.New("deposit without a contract")
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO deposits (entry_id, kind, amount, receivedDate, holder, conditions, returnedDate, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.EntryID, d.Kind, d.Amount, d.Received, d.Holder, d.Conditions, d.Returned, d.Notes)
+	if err != nil {
+		return fmt.Errorf("error saving deposit: %v", err)
+	}
+
+	return nil
+}
+
+func returnDeposit(db *sql.DB, id uint, returned string) error {
+	if _, err := parseDate(returned); err != nil {
+		return fmt.Errorf("invalid return date: %v", err)
+	}
+
+	res, err := db.Exec(`UPDATE deposits SET returnedDate = ? WHERE id = ?`, returned, id)
+	if err != nil {
+		return fmt.Errorf("error returning deposit: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("deposit with id %d not found", id)
+	}
+
+	return nil
+}
+
+func delDeposit(db *sql.DB, id uint) error {
+	res, err := db.Exec(`DELETE FROM deposits WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("deposit with id %d not found", id)
+	}
+
+	return nil
+}
+
+func scanDeposits(rows *sql.Rows) ([]Deposit, error) {
+	var deposits []Deposit
+
+	for rows.Next() {
+		var d Deposit
+		var holder, conditions, returned, notes sql.NullString
+
+		err := rows.Scan(&d.ID, &d.EntryID, &d.Kind, &d.Amount, &d.Received, &holder, &conditions, &returned, &notes)
+		if err != nil {
+			return nil, err
+		}
+		d.Holder = holder.String
+		d.Conditions = conditions.String
+		d.Returned = returned.String
+		d.Notes = notes.String
+
+		deposits = append(deposits, d)
+	}
+
+	return deposits, rows.Err()
+}
+
+func getDeposits(db *sql.DB, entryID uint) ([]Deposit, error) {
+	rows, err := db.Query(`
+		SELECT id, entry_id, kind, amount, receivedDate, holder, conditions, returnedDate, notes
+		FROM deposits
+		WHERE entry_id = ?
+		ORDER BY substr(receivedDate, 7, 4), substr(receivedDate, 4, 2), substr(receivedDate, 1, 2)`,
+		entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	return scanDeposits(rows)
+}
+
+// Deposits of every contract that haven't been returned yet
+func getHeldDeposits(db *sql.DB) ([]Deposit, error) {
+	rows, err := db.Query(`
+		SELECT id, entry_id, kind, amount, receivedDate, holder, conditions, returnedDate, notes
+		FROM deposits
+		WHERE returnedDate IS NULL OR returnedDate = ''`)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("cannot get the held deposits: %v", err)
 	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
 
-	res, err := tx.Exec(`
-		INSERT INTO entries (name, timestamp, atak, kaek, size, type, rent, startDate, endDate, emisth)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		entry.Name, entry.Timestamp, entry.ATAK, entry.KAEK, entry.Size, entry.Type, entry.Rent, entry.Start, entry.End, entry.emisth)
+	return scanDeposits(rows)
+}
+
+func setEntryStatus(db *sql.DB, id uint, to string) error {
+	e, err := getEntry(db, id)
 	if err != nil {
 		return err
 	}
 
-	entryID, err := res.LastInsertId()
+	status, err := nextStatus(e, to, time.Now())
 	if err != nil {
 		return err
 	}
 
-	// get or create owner(s)
-	for _, o := range entry.Owners {
-		ownerID, err := getOrCreateOwner(tx, o)
-		if err != nil {
-			return err
-		}
+	_, err = db.Exec(`UPDATE entries SET status = ? WHERE id = ?`, status, id)
+	if err != nil {
+		return fmt.Errorf("error updating the status of %d: %v", id, err)
+	}
+	log.Printf("Status of %d changed from %s to %s", id, e.Status, status)
 
-		// link owner to entry on the junction table
-		_, err = tx.Exec(`
-		INSERT OR IGNORE INTO entries_owner (entry_id, owner_id)
-		VALUES (?, ?)`,
-			entryID, ownerID)
-		if err != nil {
-			return err
-		}
+	return nil
+}
+
+// Brings the dated statuses (active, expiring, expired) up to date
+func refreshStatuses(db *sql.DB, today time.Time) error {
+	entries, err := getAllEntries(db)
+	if err != nil {
+		return fmt.Errorf("cannot get the statuses: %v", err)
 	}
 
-	// get or create renter(s)
-	for _, r := range entry.Renters {
-		renterID, err := getOrCreateRenters(tx, r)
+	changed := 0
+	for _, e := range entries {
+		status, err := entryStatus(e, today)
 		if err != nil {
-			return err
+			log.Printf("Cannot derive the status of %d: %v", e.ID, err)
+			continue
 		}
-
-		// link owner to entry on the junction tablle
-		_, err = tx.Exec(`
-		INSERT OR IGNORE INTO entries_renter (entry_id, renter_id)
-		VALUES (?, ?)`,
-			entryID, renterID)
-		if err != nil {
-			return err
+		if status == e.Status {
+			continue
 		}
 
-	}
-
-	// coordinates
-	for _, c := range entry.Coords {
-		_, err := tx.Exec(`
-			INSERT INTO coordinates (entry_id, latitude, longitude)
-			VALUES (?, ?, ?)`,
-			entryID, c.Latitude, c.Longitude)
+		_, err = db.Exec(`UPDATE entries SET status = ? WHERE id = ?`, status, e.ID)
 		if err != nil {
-			return err
+			return fmt.Errorf("error updating the status of %d: %v", e.ID, err)
 		}
+		changed++
+	}
+	if changed > 0 {
+		log.Printf("Updated the status of %d contracts", changed)
 	}
 
-	return tx.Commit()
+	return nil
 }
 
-func getOrCreateOwner(tx *sql.Tx, o OwnerDetails) (int64, error) {
-	var ownerID int64
-
-	query := `SELECT id FROM ownerDetails WHERE firstName = ? AND lastName = ?`
-	err := tx.QueryRow(query, o.FirstName, o.LastName).Scan(&ownerID)
-	if err == nil {
-		return ownerID, nil
-	}
-	if err != sql.ErrNoRows {
-		return 0, err
+// Extends the contracts that renew themselves and weren't given notice in time,
+// returns how many were extended
+func applyAutoRenewals(db *sql.DB, today time.Time) (int, error) {
+	entries, err := getAllEntries(db)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get the contracts to renew: %v", err)
 	}
 
-	res, err := tx.Exec(`
-		INSERT INTO ownerDetails (firstName, lastName, fathersName, afm, adt, e9, homeAddress, phoneNumber, email, accountantInfo, notes)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		o.FirstName, o.LastName, o.FathersName, o.AFM, o.ADT, o.E9, o.HomeAddress, o.PhoneNumber, o.Email, o.AccountantInfo, o.Notes)
+	tx, err := db.Begin()
 	if err != nil {
 		return 0, err
 	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
 
-	return res.LastInsertId()
-}
+	extended := 0
+	for _, e := range entries {
+		extensions := pendingExtensions(e, today)
+		for _, a := range extensions {
+			if _, err := insertAmendment(tx, a); err != nil {
+				return 0, fmt.Errorf("error extending %s: %v", contractLabel(e), err)
+			}
+		}
+		if len(extensions) > 0 {
+			log.Printf("Contract %d renewed itself until %s", e.ID, extensions[len(extensions)-1].End)
+			extended++
+		}
+	}
 
-func getOrCreateRenters(tx *sql.Tx, r RenterDetails) (int64, error) {
-	var renterID int64
+	return extended, tx.Commit()
+}
 
-	query := `SELECT id FROM renterDetails WHERE firstName = ? AND lastName = ?`
-	err := tx.QueryRow(query, r.FirstName, r.LastName).Scan(&renterID)
-	if err == nil {
-		return renterID, nil
-	}
-	if err != sql.ErrNoRows {
+// Saves the renewal of old and marks old as renewed, returns the id of the new contract
+func renewEntry(db *sql.DB, old Entry, renewed Entry, scheme NumberingScheme) (int64, error) {
+	start, err := parseDate(renewed.Start)
+	if err != nil {
 		return 0, err
 	}
-
-	res, err := tx.Exec(`
-		INSERT INTO renterDetails (firstName, lastName, fathersName, afm, adt, e9, notes)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		r.FirstName, r.LastName, r.FathersName, r.AFM, r.ADT, r.E9, r.Notes)
+	end, err := parseDate(renewed.End)
 	if err != nil {
 		return 0, err
 	}
+	if end.Before(start) {
+		return 0, fmt.Errorf("the renewal ends (%s) before it starts (%s)", renewed.End, renewed.Start)
+	}
 
-	return res.LastInsertId()
-}
-
-func updateEntry(db *sql.DB, entry Entry) error {
 	tx, err := db.Begin()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() {
 		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
@@ -268,233 +2355,286 @@ func updateEntry(db *sql.DB, entry Entry) error {
 		}
 	}()
 
-	_, err = tx.Exec(`
-		UPDATE entries
-		SET name = ?, timestamp = ?, atak = ?, kaek = ?, size = ?, type = ?, rent = ?, startDate = ?, endDate = ?, emisth = ?
-		WHERE id = ?`,
-		entry.Name, entry.Timestamp, entry.ATAK, entry.KAEK, entry.Size, entry.Type, entry.Rent, entry.Start, entry.End, entry.emisth, entry.ID)
+	// the status in the database, old might be stale
+	var status string
+	err = tx.QueryRow(`SELECT status FROM entries WHERE id = ?`, old.ID).Scan(&status)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if !canTransition(status, statusRenewed) || status == statusRenewed {
+		return 0, fmt.Errorf("contract %s cannot be renewed while %s", old.Name, statusLabels[status])
 	}
 
-	_, err = tx.Exec(`
-		DELETE FROM entries_owner WHERE entry_id = ?`,
-		entry.ID)
+	renewed.PredecessorID = old.ID
+	id, err := insertEntry(tx, renewed, scheme)
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("error saving the renewal: %v", err)
 	}
 
-	for _, o := range entry.Owners {
-		ownerID, err := getOrCreateOwner(tx, o)
+	_, err = tx.Exec(`UPDATE entries SET status = ? WHERE id = ?`, statusRenewed, old.ID)
+	if err != nil {
+		return 0, fmt.Errorf("error marking %d as renewed: %v", old.ID, err)
+	}
+
+	return id, tx.Commit()
+}
+
+// Every contract of the renewal chain the entry is part of, oldest first.
+// Only the columns of the entries table are filled.
+func getRenewalChain(db *sql.DB, id uint) ([]Entry, error) {
+	get := func(query string, arg uint) (Entry, bool, error) {
+		var e Entry
+		err := scanEntry(db.QueryRow(`SELECT `+entryColumns+` FROM entries WHERE `+query, arg), &e)
+		if err == sql.ErrNoRows {
+			return e, false, nil
+		}
+		return e, err == nil, err
+	}
+
+	current, ok, err := get("id = ?", id)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	chain := []Entry{current}
+	seen := map[uint]bool{current.ID: true}
+
+	// back to the first contract
+	for e := current; e.PredecessorID != 0; {
+		e, ok, err = get("id = ?", e.PredecessorID)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		if !ok || seen[e.ID] {
+			break
+		}
+		seen[e.ID] = true
+		chain = append([]Entry{e}, chain...)
+	}
 
-		_, err = tx.Exec(`
-			INSERT INTO entries_owner (entry_id, owner_id)
-			VALUES (?, ?)`,
-			entry.ID, ownerID)
+	// and forward to the latest renewal
+	for e := current; ; {
+		e, ok, err = get("predecessor_id = ? ORDER BY id LIMIT 1", e.ID)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		if !ok || seen[e.ID] {
+			break
+		}
+		seen[e.ID] = true
+		chain = append(chain, e)
 	}
 
-	_, err = tx.Exec(`
-		DELETE FROM entries_renter WHERE entry_id = ?`,
-		entry.ID)
+	return chain, nil
+}
+
+func insertAmendment(tx *sql.Tx, a Amendment) (int64, error) {
+	if _, err := parseDate(a.Effective); err != nil {
+		return 0, fmt.Errorf("invalid effective date: %v", err)
+	}
+	if _, ok := amendmentLabels[a.Kind]; !ok {
+		return 0, fmt.Errorf("unknown amendment kind: %s", a.Kind)
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO amendments (entry_id, kind, effectiveDate, rent, size, endDate, document, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.EntryID, a.Kind, a.Effective, a.Rent, a.Size, a.End, a.Document, a.Notes)
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("error saving amendment: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
 	}
 
-	for _, r := range entry.Renters {
+	for _, o := range a.Owners {
+		ownerID, err := getOrCreateOwner(tx, o)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.Exec(`INSERT INTO amendments_owner (amendment_id, owner_id) VALUES (?, ?)`, id, ownerID)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, r := range a.Renters {
 		renterID, err := getOrCreateRenters(tx, r)
 		if err != nil {
-			return err
+			return 0, err
 		}
-
-		_, err = tx.Exec(`
-			INSERT INTO entries_renter (entry_id, renter_id)
-			VALUES (?, ?)`,
-			entry.ID, renterID)
+		_, err = tx.Exec(`INSERT INTO amendments_renter (amendment_id, renter_id) VALUES (?, ?)`, id, renterID)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
-	_, err = tx.Exec(`
-		DELETE FROM coordinates WHERE entry_id = ?`,
-		entry.ID)
+	return id, nil
+}
+
+func saveAmendment(db *sql.DB, a Amendment) error {
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
-
-	for _, c := range entry.Coords {
-		_, err := tx.Exec(`
-			INSERT INTO coordinates (entry_id, latitude, longitude)
-			VALUES (?, ?, ?)`,
-			entry.ID, c.Latitude, c.Longitude)
-		if err != nil {
-			return err
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
 		}
+	}()
+
+	if _, err := insertAmendment(tx, a); err != nil {
+		return err
 	}
 
 	return tx.Commit()
 }
 
-func getAllEntries(db *sql.DB) ([]Entry, error) {
-	var entries []Entry
+func setAmendmentDocument(db *sql.DB, id uint, document []byte) error {
+	_, err := db.Exec(`UPDATE amendments SET document = ? WHERE id = ?`, document, id)
+	if err != nil {
+		return fmt.Errorf("error saving the document of amendment %d: %v", id, err)
+	}
+
+	return nil
+}
 
-	rows, err := db.Query(`SELECT * FROM entries`)
+func delAmendment(db *sql.DB, id uint) error {
+	res, err := db.Exec(`DELETE FROM amendments WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("amendment with id %d not found", id)
+	}
+
+	return nil
+}
+
+func getAmendments(db *sql.DB, entryID uint) ([]Amendment, error) {
+	var amendments []Amendment
+
+	rows, err := db.Query(`
+		SELECT id, entry_id, kind, effectiveDate, rent, size, endDate, document, notes
+		FROM amendments
+		WHERE entry_id = ?
+		ORDER BY substr(effectiveDate, 7, 4), substr(effectiveDate, 4, 2), substr(effectiveDate, 1, 2), id`,
+		entryID)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := rows.Close(); err != nil {
-			log.Println("rows.Close() error: ", err)
-		}
-	}()
 
 	for rows.Next() {
-		var e Entry
+		var a Amendment
+		var rent, size sql.NullFloat64
+		var end, notes sql.NullString
 
-		err := rows.Scan(&e.ID, &e.Name, &e.Timestamp, &e.ATAK, &e.KAEK, &e.Size, &e.Type, &e.Rent, &e.Start, &e.End, &e.emisth)
+		err := rows.Scan(&a.ID, &a.EntryID, &a.Kind, &a.Effective, &rent, &size, &end, &a.Document, &notes)
 		if err != nil {
+			_ = rows.Close()
 			return nil, err
 		}
+		a.Rent = rent.Float64
+		a.Size = size.Float64
+		a.End = end.String
+		a.Notes = notes.String
+
+		amendments = append(amendments, a)
+	}
+	if err := rows.Close(); err != nil {
+		log.Println("rows.Close() error: ", err)
+	}
+
+	// the people of the party changes
+	for i, a := range amendments {
+		if a.Kind != amendParties {
+			continue
+		}
 
-		// get the owners for the entry
 		ownerRows, err := db.Query(`
-			SELECT o.id, o.firstName, o.lastName, o.fathersName, o.afm, o.adt, o.e9, o.homeAddress, o.phoneNumber, o.email, o.accountantInfo, o.notes 
+			SELECT o.id, o.firstName, o.lastName, o.fathersName, o.afm, o.adt, o.e9, o.homeAddress, o.phoneNumber, o.email, o.accountantInfo, o.notes
 			FROM ownerDetails o
-			JOIN entries_owner eo ON o.id = eo.owner_id
-			WHERE eo.entry_id = ?`,
-			e.ID)
+			JOIN amendments_owner ao ON o.id = ao.owner_id
+			WHERE ao.amendment_id = ?`,
+			a.ID)
 		if err != nil {
 			return nil, err
 		}
-
 		for ownerRows.Next() {
 			var o OwnerDetails
-
 			err := ownerRows.Scan(&o.ID, &o.FirstName, &o.LastName, &o.FathersName, &o.AFM, &o.ADT, &o.E9, &o.HomeAddress, &o.PhoneNumber, &o.Email, &o.AccountantInfo, &o.Notes)
 			if err != nil {
-				if err := ownerRows.Close(); err != nil {
-					log.Println("ownerRows.Close() error: ", err)
-				}
+				_ = ownerRows.Close()
 				return nil, err
 			}
-			e.Owners = append(e.Owners, o)
+			amendments[i].Owners = append(amendments[i].Owners, o)
 		}
 		if err := ownerRows.Close(); err != nil {
 			log.Println("ownerRows.Close() error: ", err)
 		}
 
-		// get the renters for the entry
 		renterRows, err := db.Query(`
 			SELECT r.id, r.firstName, r.lastName, r.fathersName, r.afm, r.adt, r.e9, r.notes
 			FROM renterDetails r
-			JOIN entries_renter er ON r.id = er.renter_id
-			WHERE er.entry_id = ?`,
-			e.ID)
+			JOIN amendments_renter ar ON r.id = ar.renter_id
+			WHERE ar.amendment_id = ?`,
+			a.ID)
 		if err != nil {
 			return nil, err
 		}
-
 		for renterRows.Next() {
 			var r RenterDetails
-
 			err := renterRows.Scan(&r.ID, &r.FirstName, &r.LastName, &r.FathersName, &r.AFM, &r.ADT, &r.E9, &r.Notes)
 			if err != nil {
-				if err := renterRows.Close(); err != nil {
-					log.Println("renterRows.Close() error: ", err)
-				}
-				return nil, err
-			}
-			e.Renters = append(e.Renters, r)
-		}
-		if err := renterRows.Close(); err != nil {
-			log.Println("renterRows.Close() error: ", err)
-		}
-
-		// get the coordinates for the entry
-		coordRows, err := db.Query(`
-			SELECT *
-			FROM coordinates
-			WHERE entry_id = ?`,
-			e.ID)
-		if err != nil {
-			return nil, err
-		}
-
-		for coordRows.Next() {
-			var c Coordinates
-			err := coordRows.Scan(&c.ID, &c.EntryID, &c.Latitude, &c.Longitude)
-			if err != nil {
-				if err := coordRows.Close(); err != nil {
-					log.Println("coordRows.Close() error: ", err)
-				}
-
+				_ = renterRows.Close()
 				return nil, err
 			}
-			e.Coords = append(e.Coords, c)
+			amendments[i].Renters = append(amendments[i].Renters, r)
 		}
-		if err := coordRows.Close(); err != nil {
-			log.Println("coordRows.Close() error: ", err)
+		if err := renterRows.Close(); err != nil {
+			log.Println("renterRows.Close() error: ", err)
 		}
-
-		entries = append(entries, e)
-	}
-	err = rows.Err()
-	if err != nil {
-		return nil, err
 	}
 
-	return entries, nil
+	return amendments, nil
 }
 
-func getEntry(db *sql.DB, id uint) (Entry, error) {
-	var e Entry
-
-	err := db.QueryRow(`
-		SELECT * 
-		FROM entries 
-		WHERE id = ?`, id).Scan(&e.ID, &e.Name, &e.Timestamp, &e.ATAK, &e.KAEK, &e.Size, &e.Type, &e.Rent, &e.Start, &e.End, &e.emisth)
-	if err != nil {
-		return e, err
-	}
-
-	// get Owners
-	e.Owners, err = getOwners(db, e)
-	if err != nil {
-		return e, err
-	}
-
-	// get Renters
-	e.Renters, err = getRenters(db, e)
-	if err != nil {
-		return e, err
-	}
+func insertOverrides(tx *sql.Tx, entryID uint, overrides []OverlapOverride) error {
+	for _, o := range overrides {
+		if o.Reason == "" {
+			return errors.New("an overlap override needs a reason")
+		}
 
-	// get coordinates
-	e.Coords, err = getCoords(db, e)
-	if err != nil {
-		return e, err
+		// accepted again, the new reason replaces the old one
+		_, err := tx.Exec(`
+			INSERT INTO overlap_overrides (entry_id, other_id, reason, created)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (entry_id, other_id) DO UPDATE SET reason = excluded.reason, created = excluded.created`,
+			entryID, nullableID(o.OtherID), o.Reason, time.Now())
+		if err != nil {
+			return fmt.Errorf("error saving the overlap override: %v", err)
+		}
 	}
 
-	return e, nil
+	return nil
 }
 
-func getOwners(db *sql.DB, e Entry) ([]OwnerDetails, error) {
-	var owners []OwnerDetails
+func getOverrides(db *sql.DB, entryID uint) ([]OverlapOverride, error) {
+	var overrides []OverlapOverride
 
 	rows, err := db.Query(`
-		SELECT o.id, o.firstName, o.lastName, o.fathersName, o.afm, o.adt, o.e9, o.homeAddress, o.phoneNumber, o.email, o.accountantInfo, o.notes
-		FROM ownerDetails o
-		JOIN entries_owner eo ON o.id = eo.owner_id
-		WHERE eo.entry_id = ?`,
-		e.ID)
+		SELECT id, entry_id, other_id, reason, created
+		FROM overlap_overrides
+		WHERE entry_id = ?
+		ORDER BY created`,
+		entryID)
 	if err != nil {
-		return owners, err
+		return nil, err
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
@@ -503,57 +2643,58 @@ func getOwners(db *sql.DB, e Entry) ([]OwnerDetails, error) {
 	}()
 
 	for rows.Next() {
-		var o OwnerDetails
+		var o OverlapOverride
+		var otherID sql.NullInt64
 
-		err := rows.Scan(&o.ID, &o.FirstName, &o.LastName, &o.FathersName, &o.AFM, &o.ADT, &o.E9, &o.HomeAddress, &o.PhoneNumber, &o.Email, &o.AccountantInfo, &o.Notes)
-		if err != nil {
-			return owners, err
+		if err := rows.Scan(&o.ID, &o.EntryID, &otherID, &o.Reason, &o.Created); err != nil {
+			return nil, err
 		}
-		owners = append(owners, o)
+		o.OtherID = uint(otherID.Int64)
+
+		overrides = append(overrides, o)
 	}
 
-	return owners, nil
+	return overrides, rows.Err()
 }
 
-func getRenters(db *sql.DB, e Entry) ([]RenterDetails, error) {
-	var renters []RenterDetails
+// Inserts a new template or updates the one with the same id
+func saveTemplate(db *sql.DB, t Template) error {
+	if t.Name == "" {
+		return errors.New("the template needs a name")
+	}
+	if unknown := unknownPlaceholders(t.Body); len(unknown) > 0 {
+		return fmt.Errorf("unknown placeholders: %s", strings.Join(unknown, ", "))
+	}
 
-	rows, err := db.Query(`
-		SELECT r.id, r.firstName, r.lastName, r.fathersName, r.afm, r.adt, r.e9, r.notes
-		FROM renterDetails r
-		JOIN entries_renter er ON r.id = er.renter_id
-		WHERE er.entry_id = ?`,
-		e.ID)
+	var err error
+	if t.ID == 0 {
+		_, err = db.Exec(`
+			INSERT INTO templates (name, body, created)
+			VALUES (?, ?, ?)`,
+			t.Name, t.Body, time.Now())
+	} else {
+		_, err = db.Exec(`UPDATE templates SET name = ?, body = ? WHERE id = ?`, t.Name, t.Body, t.ID)
+	}
 	if err != nil {
-		return renters, err
+		return fmt.Errorf("error saving template: %v", err)
 	}
-	defer func() {
-		if err := rows.Close(); err != nil {
-			log.Println("rows.Close() error: ", err)
-		}
-	}()
 
-	for rows.Next() {
-		var r RenterDetails
+	return nil
+}
 
-		err := rows.Scan(&r.ID, &r.FirstName, &r.LastName, &r.FathersName, &r.AFM, &r.ADT, &r.E9, &r.Notes)
-		if err != nil {
-			return renters, err
-		}
-		renters = append(renters, r)
-	}
+func delTemplate(db *sql.DB, id uint) error {
+	_, err := db.Exec(`DELETE FROM templates WHERE id = ?`, id)
 
-	return renters, nil
+	return err
 }
 
-func getCoords(db *sql.DB, e Entry) ([]Coordinates, error) {
-	var coordinates []Coordinates
+func getTemplates(db *sql.DB) ([]Template, error) {
+	var templates []Template
 
 	rows, err := db.Query(`
-		SELECT id, entry_id, latitude, longitude
-		FROM coordinates
-		WHERE entry_id = ?`,
-		e.ID)
+		SELECT id, name, body, created
+		FROM templates
+		ORDER BY name`)
 	if err != nil {
 		return nil, err
 	}
@@ -564,23 +2705,32 @@ func getCoords(db *sql.DB, e Entry) ([]Coordinates, error) {
 	}()
 
 	for rows.Next() {
-		var c Coordinates
+		var t Template
 
-		err := rows.Scan(&c.ID, &c.EntryID, &c.Latitude, &c.Longitude)
-		if err != nil {
+		if err := rows.Scan(&t.ID, &t.Name, &t.Body, &t.Created); err != nil {
 			return nil, err
 		}
-		coordinates = append(coordinates, c)
+		templates = append(templates, t)
 	}
 
-	return coordinates, nil
+	return templates, rows.Err()
 }
 
-func delEntry(db *sql.DB, id uint) error {
-	if id <= 0 {
-		return errors.New("invalid entry id")
+// Replaces the μισθωτήριο of the contract
+func setEntryDocument(db *sql.DB, id uint, data []byte) error {
+	res, err := db.Exec(`UPDATE entries SET emisth = ? WHERE id = ?`, data, id)
+	if err != nil {
+		return fmt.Errorf("error saving the document: %v", err)
 	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("no entry with id %d", id)
+	}
+
+	return nil
+}
 
+// Replaces the coordinates of the contracts, all of them or none
+func setEntriesCoords(db *sql.DB, ids []uint, coords []Coordinates) error {
 	tx, err := db.Begin()
 	if err != nil {
 		return err
@@ -591,163 +2741,130 @@ func delEntry(db *sql.DB, id uint) error {
 		}
 	}()
 
-	var exists bool
-	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM entries WHERE id = ?)", id).Scan(&exists)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		return errors.New("entry not found")
+	for _, id := range ids {
+		if _, err := tx.Exec(`DELETE FROM coordinates WHERE entry_id = ?`, id); err != nil {
+			return err
+		}
+		if err := insertCoords(tx, id, coords); err != nil {
+			return err
+		}
+		if err := syncEntryShape(tx, id, coords); err != nil {
+			return err
+		}
 	}
 
-	res, err := tx.Exec("DELETE FROM entries WHERE id = ?", id)
-	if err != nil {
-		return err
-	}
+	return tx.Commit()
+}
 
-	rowsAffected, err := res.RowsAffected()
+// Replaces the corners of the parcels and of the contracts on them, all of
+// them or none
+func setParcelsCoords(db *sql.DB, ids []uint, coords []Coordinates) error {
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return errors.New("entry not found")
-	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("tx rollback error: %v", err)
+		}
+	}()
 
-	err = tx.Commit()
-	if err != nil {
-		return err
+	for _, id := range ids {
+		if err := replaceParcelCoords(tx, id, coords); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-func getAllOwners(db *sql.DB) ([]OwnerDetails, error) {
-	var owners []OwnerDetails
+// Takes the next number of the series for the entry, a number is never
+// changed or given twice
+func assignNumber(tx *sql.Tx, entryID uint, scheme NumberingScheme, year int) (string, error) {
+	if err := scheme.Validate(); err != nil {
+		return "", err
+	}
+	series, seriesYear := scheme.Series(year)
 
-	rows, err := db.Query(`SELECT * FROM ownerDetails`)
+	var number int
+	err := tx.QueryRow(`
+		SELECT COALESCE(MAX(number), 0) + 1
+		FROM contract_numbers
+		WHERE series = ? AND year = ?`,
+		series, seriesYear).Scan(&number)
 	if err != nil {
-		return owners, err
+		return "", err
 	}
 
-	for rows.Next() {
-		var o OwnerDetails
-
-		err := rows.Scan(&o.ID, &o.FirstName, &o.LastName, &o.FathersName, &o.AFM, &o.ADT, &o.E9, &o.HomeAddress, &o.PhoneNumber, &o.Email, &o.AccountantInfo, &o.Notes)
+	// a scheme that changed may have given the label already
+	label := scheme.Format(year, number)
+	for {
+		var taken bool
+		err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM contract_numbers WHERE label = ?)`, label).Scan(&taken)
 		if err != nil {
-			return owners, err
+			return "", err
 		}
-		owners = append(owners, o)
+		if !taken {
+			break
+		}
+		number++
+		label = scheme.Format(year, number)
 	}
 
-	return owners, nil
-}
-
-func getAllRenters(db *sql.DB) ([]RenterDetails, error) {
-	var renters []RenterDetails
-
-	rows, err := db.Query(`SELECT * FROM renterDetails`)
+	_, err = tx.Exec(`
+		INSERT INTO contract_numbers (entry_id, series, year, number, label)
+		VALUES (?, ?, ?, ?, ?)`,
+		entryID, series, seriesYear, number, label)
 	if err != nil {
-		return renters, err
+		return "", fmt.Errorf("error saving contract number %s: %v", label, err)
 	}
-
-	for rows.Next() {
-		var r RenterDetails
-
-		err := rows.Scan(&r.ID, &r.FirstName, &r.LastName, &r.FathersName, &r.AFM, &r.ADT, &r.E9, &r.Notes)
-		if err != nil {
-			return renters, err
-		}
-		renters = append(renters, r)
+	_, err = tx.Exec(`UPDATE entries SET number = ? WHERE id = ?`, label, entryID)
+	if err != nil {
+		return "", fmt.Errorf("error numbering entry %d: %v", entryID, err)
 	}
 
-	return renters, nil
+	return label, nil
 }
 
-func GetOwnerEntries(db *sql.DB, o OwnerDetails) ([]Entry, error) {
-	var entries []Entry
-
-	rows, err := db.Query(`
-		SELECT e.id, e.name, e.timestamp, e.atak, e.kaek, e.size, e.type, e.rent, e.startDate, e.endDate
-		FROM entries e
-		JOIN entries_owner eo ON e.id = eo.entry_id
-		WHERE eo.owner_id = ?`,
-		o.ID)
+func insertParcel(tx *sql.Tx, p Parcel) (int64, error) {
+	res, err := tx.Exec(`
+		INSERT INTO parcels (kaek, atak, area, municipality, notes)
+		VALUES (?, ?, ?, ?, ?)`,
+		p.KAEK, p.ATAK, p.Area, p.Municipality, p.Notes)
 	if err != nil {
-		return entries, err
-	}
-
-	for rows.Next() {
-		var e Entry
-
-		err := rows.Scan(&e.ID, &e.Name, &e.Timestamp, &e.ATAK, &e.KAEK, &e.Size, &e.Type, &e.Rent, &e.Start, &e.End)
-		if err != nil {
-			return entries, err
-		}
-		entries = append(entries, e)
+		return 0, fmt.Errorf("error saving the parcel: %v", err)
 	}
-
-	return entries, nil
-}
-
-func GetRenterEntries(db *sql.DB, r RenterDetails) ([]Entry, error) {
-	var entries []Entry
-
-	rows, err := db.Query(`
-		SELECT e.id, e.name, e.timestamp, e.atak, e.kaek, e.size, e.type, e.rent, e.startDate, e.endDate
-		FROM entries e
-		JOIN entries_renter er ON e.id = er.entry_id
-		WHERE er.renter_id = ?`,
-		r.ID)
+	id, err := res.LastInsertId()
 	if err != nil {
-		return entries, err
+		return 0, err
 	}
 
-	for rows.Next() {
-		var e Entry
+	return id, insertParcelCoords(tx, uint(id), p.Coords)
+}
 
-		err := rows.Scan(&e.ID, &e.Name, &e.Timestamp, &e.ATAK, &e.KAEK, &e.Size, &e.Type, &e.Rent, &e.Start, &e.End)
+func insertParcelCoords(tx *sql.Tx, parcelID uint, coords []Coordinates) error {
+	for _, c := range numberShape(shapeRings(coords)) {
+		if c.Latitude == 0 && c.Longitude == 0 {
+			continue
+		}
+		_, err := tx.Exec(`
+			INSERT INTO parcel_coordinates (parcel_id, latitude, longitude, part, ring, seq)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			parcelID, c.Latitude, c.Longitude, c.Part, c.Ring, c.Seq)
 		if err != nil {
-			return entries, err
+			return err
 		}
-		entries = append(entries, e)
 	}
 
-	return entries, nil
+	return nil
 }
 
-func updateOwner(db *sql.DB, o OwnerDetails) error {
-	tx, err := db.Begin()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
-		}
-	}()
-
-	res, err := tx.Exec(`
-		UPDATE ownerDetails
-		SET firstName = ?, lastName = ?, fathersName = ?, afm = ?, adt = ?, e9 = ?, homeAddress = ?, phoneNumber = ?, email = ?, accountantInfo = ?, notes = ?
-		WHERE id = ?`,
-		o.FirstName, o.LastName, o.FathersName, o.AFM, o.ADT, o.E9, o.HomeAddress,
-		o.PhoneNumber, o.Email, o.AccountantInfo, o.Notes, o.ID)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
+// Saves a new parcel or the changes of an existing one
+func saveParcel(db *sql.DB, p Parcel) error {
+	if err := validateParcel(p); err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("owner with id %d not found", o.ID)
-	}
-
-	return tx.Commit()
-}
 
-func updateRenter(db *sql.DB, r RenterDetails) error {
 	tx, err := db.Begin()
 	if err != nil {
 		return err
@@ -758,183 +2875,249 @@ func updateRenter(db *sql.DB, r RenterDetails) error {
 		}
 	}()
 
-	res, err := tx.Exec(`
-		UPDATE renterDetails
-		SET firstName = ?, lastName = ?, fathersName = ?, afm = ?, adt = ?, e9 = ?, notes = ?
-		WHERE id = ?`,
-		r.FirstName, r.LastName, r.FathersName, r.AFM, r.ADT, r.E9, r.Notes, r.ID)
-	if err != nil {
-		return err
+	if p.ID == 0 {
+		if _, err := insertParcel(tx, p); err != nil {
+			return err
+		}
+		return tx.Commit()
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	_, err = tx.Exec(`
+		UPDATE parcels
+		SET kaek = ?, atak = ?, area = ?, municipality = ?, notes = ?
+		WHERE id = ?`,
+		p.KAEK, p.ATAK, p.Area, p.Municipality, p.Notes, p.ID)
 	if err != nil {
-		return err
+		return fmt.Errorf("error updating parcel %d: %v", p.ID, err)
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("renter with id %d not found", r.ID)
+	if err := replaceParcelCoords(tx, p.ID, p.Coords); err != nil {
+		return err
 	}
 
 	return tx.Commit()
 }
 
-func deleteOwner(db *sql.DB, id int64) error {
-	tx, err := db.Begin()
+// The fields are drawn on the parcels, the corners of a contract are the
+// parts of its parcels copied for the map, the overlap checks and the
+// R*Tree. A contract whose parcels aren't drawn keeps its own.
+
+// Replaces the corners of the parcel and gives the contracts on it the new
+// field
+func replaceParcelCoords(tx *sql.Tx, parcelID uint, coords []Coordinates) error {
+	_, err := tx.Exec(`DELETE FROM parcel_coordinates WHERE parcel_id = ?`, parcelID)
 	if err != nil {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
-		}
-		return fmt.Errorf("error starting transaction: %v", err)
+		return err
+	}
+	if err := insertParcelCoords(tx, parcelID, coords); err != nil {
+		return err
 	}
 
-	_, err = tx.Exec(`DELETE FROM entries_owner WHERE owner_id = ?`, id)
+	rows, err := tx.Query(`SELECT entry_id FROM entries_parcel WHERE parcel_id = ? ORDER BY entry_id`, parcelID)
 	if err != nil {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
+		return err
+	}
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return err
 		}
-		return fmt.Errorf("error deleting from entries_owner: %v", err)
+		ids = append(ids, id)
+	}
+	if err := rows.Close(); err != nil {
+		log.Println("rows.Close() error: ", err)
 	}
 
-	res, err := tx.Exec(`DELETE FROM ownerDetails WHERE id = ?`, id)
-	if err != nil {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
+	for _, id := range ids {
+		if err := deriveEntryCoords(tx, id); err != nil {
+			return fmt.Errorf("error updating the field of %d: %v", id, err)
 		}
-		return fmt.Errorf("error deleting owner: %v", err)
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	return nil
+}
+
+// After the corners of the contract were saved. A contract on a single
+// parcel draws it, so the parcel and the other contracts on it get the
+// corners too unless there are none. A contract on several parcels can't
+// be split between them, it gets the parts of its parcels instead.
+func syncEntryShape(tx *sql.Tx, entryID uint, coords []Coordinates) error {
+	rows, err := tx.Query(`SELECT parcel_id FROM entries_parcel WHERE entry_id = ? ORDER BY parcel_id`, entryID)
 	if err != nil {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
-		}
-		return fmt.Errorf("error checking rows affected: %v", err)
+		return err
 	}
-	if rowsAffected == 0 {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
+	var parcelIDs []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return err
 		}
-		return fmt.Errorf("owner with id %d not found", id)
+		parcelIDs = append(parcelIDs, id)
+	}
+	if err := rows.Close(); err != nil {
+		log.Println("rows.Close() error: ", err)
 	}
 
-	return tx.Commit()
+	drawn := false
+	for _, c := range coords {
+		drawn = drawn || c.Latitude != 0 || c.Longitude != 0
+	}
+	if len(parcelIDs) == 1 && drawn {
+		return replaceParcelCoords(tx, parcelIDs[0], coords)
+	}
+
+	return deriveEntryCoords(tx, entryID)
 }
 
-func deleteRenter(db *sql.DB, id int64) error {
-	tx, err := db.Begin()
+// The corners of the contract become the parts of its parcels one after the
+// other, they stay as they are when none of its parcels is drawn
+func deriveEntryCoords(tx *sql.Tx, entryID uint) error {
+	rows, err := tx.Query(`
+		SELECT pc.parcel_id, pc.latitude, pc.longitude, pc.part, pc.ring, pc.seq
+		FROM parcel_coordinates pc
+		JOIN entries_parcel ep ON pc.parcel_id = ep.parcel_id
+		WHERE ep.entry_id = ?
+		ORDER BY pc.parcel_id, pc.part, pc.ring, pc.seq, pc.id`,
+		entryID)
 	if err != nil {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
-		}
-		return fmt.Errorf("error starting transaction: %v", err)
+		return err
 	}
 
-	_, err = tx.Exec(`DELETE FROM entries_renter WHERE renter_id = ?`, id)
-	if err != nil {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
+	var parcelIDs []uint
+	byParcel := map[uint][]Coordinates{}
+	for rows.Next() {
+		var id uint
+		var c Coordinates
+		if err := rows.Scan(&id, &c.Latitude, &c.Longitude, &c.Part, &c.Ring, &c.Seq); err != nil {
+			_ = rows.Close()
+			return err
 		}
-		return fmt.Errorf("error deleting from entries_renter: %v", err)
+		if _, ok := byParcel[id]; !ok {
+			parcelIDs = append(parcelIDs, id)
+		}
+		byParcel[id] = append(byParcel[id], c)
+	}
+	if err := rows.Close(); err != nil {
+		log.Println("rows.Close() error: ", err)
+	}
+	if len(parcelIDs) == 0 {
+		return nil
 	}
 
-	res, err := tx.Exec(`DELETE FROM renterDetails WHERE id = ?`, id)
+	var parts [][]ring
+	for _, id := range parcelIDs {
+		parts = append(parts, shapeRings(byParcel[id])...)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM coordinates WHERE entry_id = ?`, entryID); err != nil {
+		return err
+	}
+
+	return insertCoords(tx, entryID, numberShape(parts))
+}
+
+// Deletes a parcel that no contract leases
+func delParcel(db *sql.DB, id uint) error {
+	var leased int
+	err := db.QueryRow(`SELECT COUNT(*) FROM entries_parcel WHERE parcel_id = ?`, id).Scan(&leased)
 	if err != nil {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
-		}
-		return fmt.Errorf("error deleting renter: %v", err)
+		return err
+	}
+	if leased > 0 {
+		return fmt.Errorf("the parcel is leased by %d contracts", leased)
 	}
 
+	res, err := db.Exec(`DELETE FROM parcels WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
-		}
-		return fmt.Errorf("error checking rows affected: %v", err)
+		return err
 	}
 	if rowsAffected == 0 {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("tx rollback error: %v", err)
-		}
-		return fmt.Errorf("renter with id %d not found", id)
+		return fmt.Errorf("parcel with id %d not found", id)
 	}
 
-	return tx.Commit()
+	return nil
 }
 
-func getOwner(db *sql.DB, id uint) (OwnerDetails, error) {
-	var o OwnerDetails
-
-	err := db.QueryRow(`SELECT * FROM ownerDetails WHERE id = ?`, id).Scan(
-		&o.ID, &o.FirstName, &o.LastName, &o.FathersName, &o.AFM, &o.ADT, &o.E9,
-		&o.HomeAddress, &o.PhoneNumber, &o.Email, &o.AccountantInfo, &o.Notes)
+// Replaces the parcels of the contract, the new ones (without an id) are
+// created first
+func linkParcels(tx *sql.Tx, entryID uint, parcels []Parcel) error {
+	_, err := tx.Exec(`DELETE FROM entries_parcel WHERE entry_id = ?`, entryID)
 	if err != nil {
-		return o, err
+		return err
 	}
 
-	return o, nil
-}
+	for _, p := range parcels {
+		parcelID := int64(p.ID)
+		if parcelID == 0 {
+			parcelID, err = insertParcel(tx, p)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(`
+			INSERT OR IGNORE INTO entries_parcel (entry_id, parcel_id)
+			VALUES (?, ?)`,
+			entryID, parcelID)
+		if err != nil {
+			return err
+		}
+	}
 
-func getRenter(db *sql.DB, id uint) (RenterDetails, error) {
-	var r RenterDetails
+	return nil
+}
 
-	err := db.QueryRow(`SELECT * FROM renterDetails WHERE id = ?`, id).Scan(
-		&r.ID, &r.FirstName, &r.LastName, &r.FathersName, &r.AFM, &r.ADT, &r.E9, &r.Notes)
+func scanParcels(db *sql.DB, query string, args ...any) ([]Parcel, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
-		return r, err
+		return nil, err
 	}
 
-	return r, err
-}
-
-func getYearRange(db *sql.DB) (oldestYear, newestYear int, err error) {
-	query := `
-		SELECT 
-            MIN(year) AS oldest,
-            MAX(year) AS newest
-        FROM (
-            SELECT substr(startDate, 7, 4) AS year FROM entries
-            UNION ALL
-            SELECT substr(endDate,   7, 4) AS year FROM entries
-        )
-        WHERE year GLOB '[0-9][0-9][0-9][0-9]'   -- basic protection against bad data
-	`
+	var parcels []Parcel
+	for rows.Next() {
+		var p Parcel
+		var kaek, municipality, notes sql.NullString
+		var atak sql.NullInt64
 
-	var oldest, newest sql.NullInt64
+		if err := rows.Scan(&p.ID, &kaek, &atak, &p.Area, &municipality, &notes); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		p.KAEK = kaek.String
+		p.ATAK = uint(atak.Int64)
+		p.Municipality = municipality.String
+		p.Notes = notes.String
 
-	err = db.QueryRow(query).Scan(&oldest, &newest)
-	if err == sql.ErrNoRows || !oldest.Valid && !newest.Valid {
-		return 0, 0, fmt.Errorf("no valid date entries found")
+		parcels = append(parcels, p)
 	}
-	if err != nil {
-		return 0, 0, err
-	}
-
-	if !oldest.Valid {
-		return 0, 0, fmt.Errorf("no valid oldest year found")
+	if err := rows.Close(); err != nil {
+		log.Println("rows.Close() error: ", err)
 	}
 
-	if !newest.Valid {
-		return 0, 0, fmt.Errorf("no valid newest year found")
+	for i := range parcels {
+		parcels[i].Coords, err = getParcelCoords(db, parcels[i].ID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return int(oldest.Int64), int(newest.Int64), nil
+	return parcels, nil
 }
 
-func getAllEntriesByYear(db *sql.DB, year string) ([]Entry, error) {
-	var entries []Entry
-
-	// not very safe in case if bad date format
-	query := `
-		SELECT *
-		FROM entries
-		WHERE substr(startDate, 7, 4) <= ? AND substr(endDate, 7, 4) >= ? 
-		ORDER BY startDate ASC
-	`
-
-	rows, err := db.Query(query, year, year)
+func getParcelCoords(db *sql.DB, parcelID uint) ([]Coordinates, error) {
+	rows, err := db.Query(`
+		SELECT id, latitude, longitude, part, ring, seq
+		FROM parcel_coordinates
+		WHERE parcel_id = ?
+		ORDER BY part, ring, seq, id`,
+		parcelID)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get entries of the year %s: %v", year, err)
+		return nil, err
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
@@ -942,99 +3125,108 @@ func getAllEntriesByYear(db *sql.DB, year string) ([]Entry, error) {
 		}
 	}()
 
+	var coords []Coordinates
 	for rows.Next() {
-		var e Entry
-
-		err := rows.Scan(&e.ID, &e.Name, &e.Timestamp, &e.ATAK, &e.KAEK, &e.Size, &e.Type, &e.Rent, &e.Start, &e.End, &e.emisth)
-		if err != nil {
+		var c Coordinates
+		if err := rows.Scan(&c.ID, &c.Latitude, &c.Longitude, &c.Part, &c.Ring, &c.Seq); err != nil {
 			return nil, err
 		}
+		coords = append(coords, c)
+	}
 
-		// get the owners for the entry
-		ownerRows, err := db.Query(`
-			SELECT o.id, o.firstName, o.lastName, o.fathersName, o.afm, o.adt, o.e9, o.homeAddress, o.phoneNumber, o.email, o.accountantInfo, o.notes 
-			FROM ownerDetails o
-			JOIN entries_owner eo ON o.id = eo.owner_id
-			WHERE eo.entry_id = ?`,
-			e.ID)
-		if err != nil {
-			return nil, err
-		}
+	return coords, rows.Err()
+}
 
-		for ownerRows.Next() {
-			var o OwnerDetails
+func getParcels(db *sql.DB) ([]Parcel, error) {
+	return scanParcels(db, `
+		SELECT id, kaek, atak, area, municipality, notes
+		FROM parcels
+		ORDER BY kaek, atak, id`)
+}
 
-			err := ownerRows.Scan(&o.ID, &o.FirstName, &o.LastName, &o.FathersName, &o.AFM, &o.ADT, &o.E9, &o.HomeAddress, &o.PhoneNumber, &o.Email, &o.AccountantInfo, &o.Notes)
-			if err != nil {
-				if err := ownerRows.Close(); err != nil {
-					log.Println("ownerRows.Close() error: ", err)
-				}
-				return nil, err
-			}
-			e.Owners = append(e.Owners, o)
-		}
-		if err := ownerRows.Close(); err != nil {
-			log.Println("ownerRows.Close() error: ", err)
+func getEntryParcels(db *sql.DB, entryID uint) ([]Parcel, error) {
+	return scanParcels(db, `
+		SELECT p.id, p.kaek, p.atak, p.area, p.municipality, p.notes
+		FROM parcels p
+		JOIN entries_parcel ep ON p.id = ep.parcel_id
+		WHERE ep.entry_id = ?
+		ORDER BY p.id`,
+		entryID)
+}
+
+// The contracts whose field has the point in its box, from the R*Tree, the
+// field itself may still miss it
+func getEntryIDsAt(db *sql.DB, lat, lon float64) ([]uint, error) {
+	rows, err := db.Query(`
+		SELECT id FROM entry_bounds
+		WHERE min_lat <= ? AND max_lat >= ? AND min_lon <= ? AND max_lon >= ?
+		ORDER BY id`,
+		lat, lat, lon, lon)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
 		}
+	}()
 
-		// get the renters for the entry
-		renterRows, err := db.Query(`
-			SELECT r.id, r.firstName, r.lastName, r.fathersName, r.afm, r.adt, r.e9, r.notes
-			FROM renterDetails r
-			JOIN entries_renter er ON r.id = er.renter_id
-			WHERE er.entry_id = ?`,
-			e.ID)
-		if err != nil {
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
+		ids = append(ids, id)
+	}
 
-		for renterRows.Next() {
-			var r RenterDetails
+	return ids, rows.Err()
+}
 
-			err := renterRows.Scan(&r.ID, &r.FirstName, &r.LastName, &r.FathersName, &r.AFM, &r.ADT, &r.E9, &r.Notes)
-			if err != nil {
-				if err := renterRows.Close(); err != nil {
-					log.Println("renterRows.Close() error: ", err)
-				}
-				return nil, err
-			}
-			e.Renters = append(e.Renters, r)
-		}
-		if err := renterRows.Close(); err != nil {
-			log.Println("renterRows.Close() error: ", err)
-		}
+// The parcels whose drawing has the point in its box, from the R*Tree, the
+// drawing itself may still miss it
+func getParcelsAt(db *sql.DB, lat, lon float64) ([]Parcel, error) {
+	return scanParcels(db, `
+		SELECT p.id, p.kaek, p.atak, p.area, p.municipality, p.notes
+		FROM parcels p
+		JOIN parcel_bounds b ON p.id = b.id
+		WHERE b.min_lat <= ? AND b.max_lat >= ? AND b.min_lon <= ? AND b.max_lon >= ?
+		ORDER BY p.id`,
+		lat, lat, lon, lon)
+}
 
-		// get the coordinates for the entry
-		coordRows, err := db.Query(`
-			SELECT *
-			FROM coordinates
-			WHERE entry_id = ?`,
-			e.ID)
+func getEntriesByID(db *sql.DB, ids []uint) ([]Entry, error) {
+	entries := make([]Entry, 0, len(ids))
+	for _, id := range ids {
+		e, err := getEntry(db, id)
 		if err != nil {
 			return nil, err
 		}
-
-		for coordRows.Next() {
-			var c Coordinates
-			err := coordRows.Scan(&c.ID, &c.EntryID, &c.Latitude, &c.Longitude)
-			if err != nil {
-				if err := coordRows.Close(); err != nil {
-					log.Println("coordRows.Close() error: ", err)
-				}
-				return nil, err
-			}
-			e.Coords = append(e.Coords, c)
-		}
-		if err := coordRows.Close(); err != nil {
-			log.Println("coordRows.Close() error: ", err)
-		}
-
 		entries = append(entries, e)
 	}
-	err = rows.Err()
+
+	return entries, nil
+}
+
+// The contracts that lease the parcel
+func getParcelEntries(db *sql.DB, parcelID uint) ([]Entry, error) {
+	rows, err := db.Query(`SELECT entry_id FROM entries_parcel WHERE parcel_id = ? ORDER BY entry_id`, parcelID)
 	if err != nil {
 		return nil, err
 	}
 
-	return entries, nil
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Close(); err != nil {
+		log.Println("rows.Close() error: ", err)
+	}
+
+	return getEntriesByID(db, ids)
 }
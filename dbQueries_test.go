@@ -1,8 +1,10 @@
 package main
 
 import (
+	"path/filepath"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 )
@@ -64,13 +66,14 @@ func TestGetCoords_ReturnsExpectedCoordinates(t *testing.T) {
 		}
 	}()
 
-	cols := []string{"id", "entry_id", "latitude", "longitude"}
-	mockRows := sqlmock.NewRows(cols).AddRow(10, 1, 37.1234, 23.4567)
+	cols := []string{"id", "entry_id", "latitude", "longitude", "part", "ring", "seq"}
+	mockRows := sqlmock.NewRows(cols).AddRow(10, 1, 37.1234, 23.4567, 0, 1, 2)
 
 	query := `
-			SELECT id, entry_id, latitude, longitude
+			SELECT id, entry_id, latitude, longitude, part, ring, seq
 			FROM coordinates
-			WHERE entry_id = ?`
+			WHERE entry_id = ?
+			ORDER BY part, ring, seq, id`
 
 	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(mockRows)
 
@@ -81,7 +84,7 @@ func TestGetCoords_ReturnsExpectedCoordinates(t *testing.T) {
 	if len(got) != 1 {
 		t.Fatalf("expected 1 coordinate, got %d", len(got))
 	}
-	if got[0].Latitude != 37.1234 || got[0].Longitude != 23.4567 {
+	if got[0].Latitude != 37.1234 || got[0].Longitude != 23.4567 || got[0].Ring != 1 || got[0].Seq != 2 {
 		t.Fatalf("unexpected coordinate returned: %+v", got[0])
 	}
 }
@@ -211,3 +214,207 @@ func TestGetAllOwnersAndRenters_ScanMapping(t *testing.T) {
 		t.Fatalf("unexpected renters result: %+v", renters)
 	}
 }
+
+func TestGetPayments_NullOwnerMeansAllOwners(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error creating sqlmock: %v", err)
+	}
+	defer func() {
+		mock.ExpectClose()
+		if err := db.Close(); err != nil {
+			t.Fatalf("unexpected error closing the DB: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet sqlmock expectations: %v", err)
+		}
+	}()
+
+	cols := []string{"id", "entry_id", "owner_id", "renter_id", "paidDate", "amount", "notes"}
+	mockRows := sqlmock.NewRows(cols).
+		AddRow(1, 7, nil, 3, "15-10-2025", 1200.5, nil).
+		AddRow(2, 7, 4, nil, "20-10-2025", 100.0, "μετρητά")
+
+	mock.ExpectQuery("(?s)SELECT id, entry_id, owner_id, renter_id, paidDate, amount, notes.*FROM payments.*WHERE entry_id = \\?").
+		WithArgs(7).WillReturnRows(mockRows)
+
+	got, err := getPayments(db, 7)
+	if err != nil {
+		t.Fatalf("getPayments returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 payments, got %d", len(got))
+	}
+	if got[0].OwnerID != 0 || got[0].RenterID != 3 || got[0].Amount != 1200.5 {
+		t.Fatalf("unexpected first payment: %+v", got[0])
+	}
+	if got[1].OwnerID != 4 || got[1].RenterID != 0 || got[1].Notes != "μετρητά" {
+		t.Fatalf("unexpected second payment: %+v", got[1])
+	}
+}
+
+func TestGetReceiptForPayment_NoReceiptYet(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error creating sqlmock: %v", err)
+	}
+	defer func() {
+		mock.ExpectClose()
+		if err := db.Close(); err != nil {
+			t.Fatalf("unexpected error closing the DB: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet sqlmock expectations: %v", err)
+		}
+	}()
+
+	cols := []string{"id", "payment_id", "series", "year", "number", "issued", "attachment_id"}
+	mock.ExpectQuery("(?s)FROM receipts.*WHERE payment_id = \\?").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows(cols))
+
+	_, ok, err := getReceiptForPayment(db, 3)
+	if err != nil {
+		t.Fatalf("getReceiptForPayment returned error: %v", err)
+	}
+	if ok {
+		t.Fatalf("expected no receipt for the payment")
+	}
+
+	mock.ExpectQuery("(?s)FROM receipts.*WHERE payment_id = \\?").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 4, "Α", 2025, 12, "01-02-2025", 9))
+
+	r, ok, err := getReceiptForPayment(db, 4)
+	if err != nil || !ok {
+		t.Fatalf("expected a receipt, ok: %v err: %v", ok, err)
+	}
+	if receiptName(r) != "Α-12/2025" || r.AttachmentID != 9 {
+		t.Fatalf("unexpected receipt: %+v", r)
+	}
+}
+
+func TestGetEntryIDsAt_QueriesTheBoxes(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error creating sqlmock: %v", err)
+	}
+	defer func() {
+		mock.ExpectClose()
+		if err := db.Close(); err != nil {
+			t.Fatalf("unexpected error closing the DB: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet sqlmock expectations: %v", err)
+		}
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM entry_bounds`)).
+		WithArgs(38.5, 38.5, 23.5, 23.5).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(7))
+
+	got, err := getEntryIDsAt(db, 38.5, 23.5)
+	if err != nil {
+		t.Fatalf("getEntryIDsAt returned error: %v", err)
+	}
+	if len(got) != 2 || got[0] != 3 || got[1] != 7 {
+		t.Fatalf("ids = %v, want [3 7]", got)
+	}
+}
+
+func TestCreateReceipt_NumbersOfDeletedPaymentsStayTaken(t *testing.T) {
+	t.Parallel()
+
+	db, err := initDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("unexpected error closing the DB: %v", err)
+		}
+	}()
+
+	entry := Entry{Name: "Κάμπος", KAEK: "123", Start: "01-01-2025", End: "31-12-2027", Timestamp: time.Now()}
+	if err := saveEntry(db, entry, defaultNumberingScheme); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := getAllEntries(db)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("entries = %v, %v", entries, err)
+	}
+	render := func(Receipt) ([]byte, error) { return []byte("%PDF"), nil }
+	receipt := func() Receipt {
+		t.Helper()
+		if err := savePayment(db, Payment{EntryID: entries[0].ID, Date: "10-05-2025", Amount: 500}); err != nil {
+			t.Fatal(err)
+		}
+		payments, err := getPayments(db, entries[0].ID)
+		if err != nil || len(payments) == 0 {
+			t.Fatalf("payments = %v, %v", payments, err)
+		}
+		r, err := createReceipt(db, payments[len(payments)-1], "Α", "11-05-2025", render)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	first := receipt()
+	if err := delPayment(db, first.PaymentID); err != nil {
+		t.Fatal(err)
+	}
+	if second := receipt(); second.Number != first.Number+1 {
+		t.Errorf("the receipt after the deleted one got number %d, want %d", second.Number, first.Number+1)
+	}
+
+	var kept int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM receipts WHERE payment_id IS NULL AND attachment_id IS NOT NULL`).Scan(&kept); err != nil || kept != 1 {
+		t.Errorf("the receipt of the deleted payment = %d, %v, want it kept with its document", kept, err)
+	}
+}
+
+func TestUpdateEntry_OverridesAreNotRepeated(t *testing.T) {
+	t.Parallel()
+
+	db, err := initDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("unexpected error closing the DB: %v", err)
+		}
+	}()
+
+	for _, name := range []string{"Κάμπος", "Λόγγος"} {
+		e := Entry{Name: name, KAEK: "123", Start: "01-01-2025", End: "31-12-2027", Timestamp: time.Now()}
+		if err := saveEntry(db, e, defaultNumberingScheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := getAllEntries(db)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("entries = %v, %v", entries, err)
+	}
+
+	e := entries[0]
+	for _, reason := range []string{"the same land in two parts", "checked again"} {
+		e.Overrides = []OverlapOverride{{OtherID: entries[1].ID, Reason: reason}}
+		if err := updateEntry(db, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	overrides, err := getOverrides(db, e.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 1 || overrides[0].Reason != "checked again" {
+		t.Errorf("overrides = %+v, want the last reason once", overrides)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Kinds of deposits
+const (
+	depositCash      = "cash"
+	depositGuarantee = "guarantee"
+)
+
+var depositLabels = map[string]string{
+	depositCash:      "Χρηματική εγγύηση",
+	depositGuarantee: "Εγγυητική επιστολή",
+}
+
+func (d Deposit) Held() bool {
+	return d.Returned == ""
+}
+
+// Total of the deposits that are still held
+func heldDepositsAmount(deposits []Deposit) float64 {
+	var total float64
+	for _, d := range deposits {
+		if d.Held() {
+			total += d.Amount
+		}
+	}
+
+	return TruncateFloatTo2Decimals(total)
+}
+
+// Reminders for the contracts that ended (up to now) while deposits of them
+// are still held
+func depositReminders(entries []Entry, deposits []Deposit, now time.Time) []string {
+	held := make(map[uint][]Deposit)
+	for _, d := range deposits {
+		if d.Held() {
+			held[d.EntryID] = append(held[d.EntryID], d)
+		}
+	}
+
+	var reminders []string
+	for _, e := range entries {
+		if len(held[e.ID]) == 0 {
+			continue
+		}
+		end, err := parseDate(e.ActualEnd())
+		if err != nil || end.After(now) {
+			continue
+		}
+
+		reminders = append(reminders, fmt.Sprintf("%s ended on %s, deposit of %.2f€ not returned",
+			contractLabel(e), e.ActualEnd(), heldDepositsAmount(held[e.ID])))
+	}
+	sort.Strings(reminders)
+
+	return reminders
+}
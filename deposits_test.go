@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestHeldDepositsAmount(t *testing.T) {
+	t.Parallel()
+
+	deposits := []Deposit{
+		{Amount: 500},
+		{Amount: 250.5},
+		{Amount: 1000, Returned: "01-01-2025"},
+	}
+
+	if got := heldDepositsAmount(deposits); got != 750.5 {
+		t.Fatalf("heldDepositsAmount() = %v, want 750.5", got)
+	}
+}
+
+func TestDepositReminders_OnlyEndedContractsWithHeldDeposits(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{ID: 1, Name: "Κάμπος", End: "31-12-2024"},
+		{ID: 2, Name: "Λόφος", End: "31-12-2024"},
+		{ID: 3, Name: "Ρέμα", End: "31-12-2026"},
+		{ID: 4, Name: "Αλώνι", End: "31-12-2024"},
+	}
+	deposits := []Deposit{
+		{EntryID: 1, Amount: 300},
+		{EntryID: 1, Amount: 200},
+		{EntryID: 2, Amount: 300, Returned: "05-01-2025"},
+		{EntryID: 3, Amount: 300},
+	}
+
+	got := depositReminders(entries, deposits, date("15-01-2025"))
+	want := "Κάμπος ended on 31-12-2024, deposit of 500.00€ not returned"
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("depositReminders() = %q, want [%q]", got, want)
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ΕΓΣΑ87 (GGRS87, EPSG:2100), the Greek grid of the cadastre and the
+// surveyors' plans: a transverse Mercator on GRS80 with its own datum, which
+// sits that many metres off WGS84 (the towgs84 of EPSG:2100).
+const (
+	grs80A = 6378137.0
+	grs80F = 1 / 298.257222101
+
+	egsa87Lon0     = 24.0
+	egsa87K0       = 0.9996
+	egsa87FalseE   = 500000.0
+	egsa87ShiftX   = -199.87
+	egsa87ShiftY   = 74.79
+	egsa87ShiftZ   = 246.62
+	egsa87Decimals = 3
+)
+
+// Where the grid is used, with some room around Greece. Anything outside
+// is a typo or degrees in the wrong box.
+const (
+	egsa87MinE = 50000.0
+	egsa87MaxE = 1050000.0
+	egsa87MinN = 3800000.0
+	egsa87MaxN = 4700000.0
+)
+
+// The series of the transverse Mercator after Krüger, as in Karney,
+// Transverse Mercator with an accuracy of a few nanometers (2011), to the
+// fourth order of n, good to well under a millimetre in Greece
+var (
+	tmN     = grs80F / (2 - grs80F)
+	tmA     = grs80A / (1 + tmN) * (1 + tmN*tmN/4 + math.Pow(tmN, 4)/64)
+	tmAlpha = [4]float64{
+		tmN/2 - 2*tmN*tmN/3 + 5*math.Pow(tmN, 3)/16 + 41*math.Pow(tmN, 4)/180,
+		13*tmN*tmN/48 - 3*math.Pow(tmN, 3)/5 + 557*math.Pow(tmN, 4)/1440,
+		61*math.Pow(tmN, 3)/240 - 103*math.Pow(tmN, 4)/140,
+		49561 * math.Pow(tmN, 4) / 161280,
+	}
+	tmBeta = [4]float64{
+		tmN/2 - 2*tmN*tmN/3 + 37*math.Pow(tmN, 3)/96 - math.Pow(tmN, 4)/360,
+		tmN*tmN/48 + math.Pow(tmN, 3)/15 - 437*math.Pow(tmN, 4)/1440,
+		17*math.Pow(tmN, 3)/480 - 37*math.Pow(tmN, 4)/840,
+		4397 * math.Pow(tmN, 4) / 161280,
+	}
+	tmDelta = [4]float64{
+		2*tmN - 2*tmN*tmN/3 - 2*math.Pow(tmN, 3) + 116*math.Pow(tmN, 4)/45,
+		7*tmN*tmN/3 - 8*math.Pow(tmN, 3)/5 - 227*math.Pow(tmN, 4)/45,
+		56*math.Pow(tmN, 3)/15 - 136*math.Pow(tmN, 4)/35,
+		4279 * math.Pow(tmN, 4) / 630,
+	}
+)
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
+
+// Latitude and longitude on the ellipsoid to earth centred X, Y, Z at height 0
+func toECEF(lat, lon, a, f float64) (x, y, z float64) {
+	e2 := f * (2 - f)
+	phi, lambda := radians(lat), radians(lon)
+	n := a / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
+
+	return n * math.Cos(phi) * math.Cos(lambda), n * math.Cos(phi) * math.Sin(lambda), n * (1 - e2) * math.Sin(phi)
+}
+
+// Earth centred X, Y, Z to latitude and longitude on the ellipsoid, the
+// height (a few tens of metres after the shift) is dropped
+func fromECEF(x, y, z, a, f float64) (lat, lon float64) {
+	e2 := f * (2 - f)
+	p := math.Hypot(x, y)
+	phi := math.Atan2(z, p*(1-e2))
+	for range 10 {
+		n := a / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
+		h := p/math.Cos(phi) - n
+		phi = math.Atan2(z, p*(1-e2*n/(n+h)))
+	}
+
+	return degrees(phi), degrees(math.Atan2(y, x))
+}
+
+// Latitude and longitude on GRS80 to the grid
+func tmForward(lat, lon float64) (e, n float64) {
+	phi, lambda := radians(lat), radians(lon-egsa87Lon0)
+	c := 2 * math.Sqrt(tmN) / (1 + tmN)
+	t := math.Sinh(math.Atanh(math.Sin(phi)) - c*math.Atanh(c*math.Sin(phi)))
+	xi0 := math.Atan2(t, math.Cos(lambda))
+	eta0 := math.Atanh(math.Sin(lambda) / math.Sqrt(1+t*t))
+
+	xi, eta := xi0, eta0
+	for j, a := range tmAlpha {
+		k := 2 * float64(j+1)
+		xi += a * math.Sin(k*xi0) * math.Cosh(k*eta0)
+		eta += a * math.Cos(k*xi0) * math.Sinh(k*eta0)
+	}
+
+	return egsa87FalseE + egsa87K0*tmA*eta, egsa87K0 * tmA * xi
+}
+
+// The grid to latitude and longitude on GRS80
+func tmInverse(e, n float64) (lat, lon float64) {
+	xi := n / (egsa87K0 * tmA)
+	eta := (e - egsa87FalseE) / (egsa87K0 * tmA)
+
+	xi0, eta0 := xi, eta
+	for j, b := range tmBeta {
+		k := 2 * float64(j+1)
+		xi0 -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		eta0 -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	chi := math.Asin(math.Sin(xi0) / math.Cosh(eta0))
+	phi := chi
+	for j, d := range tmDelta {
+		phi += d * math.Sin(2*float64(j+1)*chi)
+	}
+
+	return degrees(phi), egsa87Lon0 + degrees(math.Atan2(math.Sinh(eta0), math.Cos(xi0)))
+}
+
+// WGS84 degrees to ΕΓΣΑ87 metres
+func wgs84ToEGSA87(lat, lon float64) (e, n float64) {
+	x, y, z := toECEF(lat, lon, wgs84A, wgs84F)
+	lat, lon = fromECEF(x-egsa87ShiftX, y-egsa87ShiftY, z-egsa87ShiftZ, grs80A, grs80F)
+
+	return tmForward(lat, lon)
+}
+
+// ΕΓΣΑ87 metres to WGS84 degrees. The grid has no height, taking it as 0
+// on GRS80 is about a millimetre off the other direction, which a couple of
+// corrections take back so the two agree.
+func egsa87ToWGS84(e, n float64) (lat, lon float64) {
+	shifted := func(e, n float64) (float64, float64) {
+		lat, lon := tmInverse(e, n)
+		x, y, z := toECEF(lat, lon, grs80A, grs80F)
+		return fromECEF(x+egsa87ShiftX, y+egsa87ShiftY, z+egsa87ShiftZ, wgs84A, wgs84F)
+	}
+
+	firstLat, firstLon := shifted(e, n)
+	lat, lon = firstLat, firstLon
+	for range 2 {
+		backLat, backLon := shifted(wgs84ToEGSA87(lat, lon))
+		lat, lon = lat+firstLat-backLat, lon+firstLon-backLon
+	}
+
+	return lat, lon
+}
+
+func validEGSA87(e, n float64) error {
+	if e < egsa87MinE || e > egsa87MaxE || n < egsa87MinN || n > egsa87MaxN {
+		return fmt.Errorf("%.3f, %.3f is not in ΕΓΣΑ87, expected X between %.0f and %.0f and Y between %.0f and %.0f metres",
+			e, n, egsa87MinE, egsa87MaxE, egsa87MinN, egsa87MaxN)
+	}
+	return nil
+}
+
+// A corner from the text of its ΕΓΣΑ87 X (easting) and Y (northing) in
+// metres, with a decimal point or comma
+func parseGridCorner(x, y string) (Coordinates, error) {
+	e, err := parseMetres("X", x)
+	if err != nil {
+		return Coordinates{}, err
+	}
+	n, err := parseMetres("Y", y)
+	if err != nil {
+		return Coordinates{}, err
+	}
+	if err := validEGSA87(e, n); err != nil {
+		return Coordinates{}, err
+	}
+
+	lat, lon := egsa87ToWGS84(e, n)
+	return Coordinates{Latitude: lat, Longitude: lon}, nil
+}
+
+func parseMetres(axis, s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", "."), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q is not a number of metres", axis, s)
+	}
+	return v, nil
+}
+
+// The ΕΓΣΑ87 X and Y of the corner as text, to the millimetre
+func formatGridCorner(c Coordinates) (x, y string) {
+	e, n := wgs84ToEGSA87(c.Latitude, c.Longitude)
+	return strconv.FormatFloat(e, 'f', egsa87Decimals, 64), strconv.FormatFloat(n, 'f', egsa87Decimals, 64)
+}
+
+// The coordinates as ΕΓΣΑ87 lines, the same layout as shapeLines
+func egsa87Lines(coords []Coordinates) []string {
+	parts := shapeRings(coords)
+	single := len(parts) == 1 && len(parts[0]) == 1
+
+	var lines []string
+	for p, rings := range parts {
+		for r, corners := range rings {
+			if !single {
+				if r == 0 {
+					lines = append(lines, fmt.Sprintf("Τμήμα %d:", p+1))
+				} else {
+					lines = append(lines, fmt.Sprintf("Οπή %d:", r))
+				}
+			}
+			for i, c := range corners {
+				x, y := formatGridCorner(c)
+				lines = append(lines, fmt.Sprintf("%d. X %s, Y %s", i+1, x, y))
+			}
+		}
+	}
+
+	return lines
+}
+
+// A list of corners as surveyors hand them out, one per line as "X Y" in
+// ΕΓΣΑ87 metres or as WGS84 degrees in any of the ways parseLatLon takes,
+// split by spaces, tabs, commas or semicolons, with or without a point name
+// or number in front. Lines without numbers, like headings, are skipped.
+func parseCornerList(text string) ([]Coordinates, error) {
+	var r ring
+	for i, line := range strings.Split(text, "\n") {
+		if c, ok := listLatLon(line); ok {
+			r = append(r, c)
+			continue
+		}
+
+		numbers := listNumbers(line)
+		if len(numbers) == 0 {
+			continue
+		}
+		if len(numbers) < 2 {
+			return nil, fmt.Errorf("line %d: expected two coordinates, got %q", i+1, strings.TrimSpace(line))
+		}
+
+		c, ok := numbersCorner(numbers)
+		if !ok {
+			// metres with decimal commas, 476000,25 4205000,50
+			if numbers := listNumbers(decimalComma.ReplaceAllString(line, "$1.$2")); len(numbers) >= 2 {
+				c, ok = numbersCorner(numbers)
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("line %d: %q is neither WGS84 degrees nor ΕΓΣΑ87 metres", i+1, strings.TrimSpace(line))
+		}
+		r = append(r, c)
+	}
+	if len(r) == 0 {
+		return nil, errors.New("there are no corners in the file")
+	}
+
+	coords := numberShape([][]ring{{openRing(r)}})
+	return coords, validateShape(coords)
+}
+
+var decimalComma = regexp.MustCompile(`(\d),(\d)`)
+
+// A line of the list in degrees, after the point name or number if there is
+// one
+func listLatLon(line string) (Coordinates, bool) {
+	line = strings.TrimSpace(line)
+	if c, err := parseLatLon(line); err == nil {
+		return c, true
+	}
+	if i := strings.IndexAny(line, " \t;,"); i > 0 {
+		if c, err := parseLatLon(line[i+1:]); err == nil {
+			return c, true
+		}
+	}
+
+	return Coordinates{}, false
+}
+
+// The numbers of a line of the list up to the first that isn't one, after
+// the point name
+func listNumbers(line string) []float64 {
+	fields := strings.FieldsFunc(line, func(c rune) bool {
+		return c == ',' || c == ';' || c == '\t' || c == ' ' || c == '\r'
+	})
+	// a point name like Σ1 in front
+	if len(fields) > 0 {
+		if _, err := strconv.ParseFloat(fields[0], 64); err != nil {
+			fields = fields[1:]
+		}
+	}
+
+	var numbers []float64
+	for _, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, v)
+	}
+
+	return numbers
+}
+
+// The corner of the numbers of a line, maybe with a point number in front
+// of the pair
+func numbersCorner(numbers []float64) (Coordinates, bool) {
+	if len(numbers) >= 3 && numbers[0] == math.Trunc(numbers[0]) {
+		if c, ok := listCorner(numbers[1], numbers[2]); ok {
+			return c, true
+		}
+	}
+
+	return listCorner(numbers[0], numbers[1])
+}
+
+// The corner of a pair of numbers from a list, degrees if they fit, else
+// ΕΓΣΑ87 X and Y in either order
+func listCorner(a, b float64) (Coordinates, bool) {
+	switch {
+	case math.Abs(a) <= 90 && math.Abs(b) <= 180:
+		return Coordinates{Latitude: a, Longitude: b}, true
+	case validEGSA87(a, b) == nil:
+		lat, lon := egsa87ToWGS84(a, b)
+		return Coordinates{Latitude: lat, Longitude: lon}, true
+	case validEGSA87(b, a) == nil:
+		lat, lon := egsa87ToWGS84(b, a)
+		return Coordinates{Latitude: lat, Longitude: lon}, true
+	}
+
+	return Coordinates{}, false
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// The meridian arc of GRS80 from the equator, by Simpson's rule on the
+// radius of curvature, independent of the series
+func meridianArc(lat float64) float64 {
+	e2 := grs80F * (2 - grs80F)
+	m := func(phi float64) float64 {
+		s := math.Sin(phi)
+		return grs80A * (1 - e2) / math.Pow(1-e2*s*s, 1.5)
+	}
+
+	const steps = 10000
+	h := radians(lat) / steps
+	sum := m(0) + m(radians(lat))
+	for i := 1; i < steps; i++ {
+		w := 2.0
+		if i%2 == 1 {
+			w = 4
+		}
+		sum += w * m(float64(i)*h)
+	}
+
+	return sum * h / 3
+}
+
+func TestTMForward_CentralMeridian(t *testing.T) {
+	t.Parallel()
+
+	for _, lat := range []float64{35, 38.5, 41.7} {
+		e, n := tmForward(lat, egsa87Lon0)
+		if math.Abs(e-egsa87FalseE) > 1e-6 {
+			t.Fatalf("easting on the central meridian = %f", e)
+		}
+		if want := egsa87K0 * meridianArc(lat); math.Abs(n-want) > 1e-3 {
+			t.Fatalf("northing at %f = %f, want %f", lat, n, want)
+		}
+	}
+}
+
+// Snyder, Map Projections - A Working Manual (1987), 8-9 and 8-10, a series
+// good to the millimetre a few degrees off the central meridian
+func snyderTM(lat, lon float64) (float64, float64) {
+	e2 := grs80F * (2 - grs80F)
+	ep2 := e2 / (1 - e2)
+	phi := radians(lat)
+	n := grs80A / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
+	t := math.Tan(phi) * math.Tan(phi)
+	c := ep2 * math.Cos(phi) * math.Cos(phi)
+	a := radians(lon-egsa87Lon0) * math.Cos(phi)
+	m := meridianArc(lat)
+
+	x := egsa87K0 * n * (a + (1-t+c)*math.Pow(a, 3)/6 + (5-18*t+t*t+72*c-58*ep2)*math.Pow(a, 5)/120)
+	y := egsa87K0 * (m + n*math.Tan(phi)*(a*a/2+(5-t+9*c+4*c*c)*math.Pow(a, 4)/24+(61-58*t+t*t+600*c-330*ep2)*math.Pow(a, 6)/720))
+
+	return egsa87FalseE + x, y
+}
+
+func TestTMForward_OffTheMeridian(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range [][2]float64{{38, 22}, {40.6, 22.9}, {35.3, 25.1}} {
+		e, n := tmForward(p[0], p[1])
+		we, wn := snyderTM(p[0], p[1])
+		if math.Abs(e-we) > 2e-3 || math.Abs(n-wn) > 2e-3 {
+			t.Fatalf("tmForward(%v) = %f, %f, want %f, %f", p, e, n, we, wn)
+		}
+	}
+}
+
+func TestEGSA87_DatumShift(t *testing.T) {
+	t.Parallel()
+
+	// without the shift the two grids would be the same, with it the
+	// difference is the shift seen from the point, east and north
+	lat, lon := 38.0, 24.0
+	e, n := wgs84ToEGSA87(lat, lon)
+	plainE, plainN := tmForward(lat, lon)
+
+	phi, lambda := radians(lat), radians(lon)
+	dx, dy, dz := -egsa87ShiftX, -egsa87ShiftY, -egsa87ShiftZ
+	wantE := -math.Sin(lambda)*dx + math.Cos(lambda)*dy
+	wantN := -math.Sin(phi)*math.Cos(lambda)*dx - math.Sin(phi)*math.Sin(lambda)*dy + math.Cos(phi)*dz
+
+	if math.Abs(e-plainE-wantE) > 0.5 || math.Abs(n-plainN-wantN) > 0.5 {
+		t.Fatalf("the shift is %f, %f, want %f, %f", e-plainE, n-plainN, wantE, wantN)
+	}
+}
+
+// The standard Molodensky formulas, WGS84 degrees to GGRS87 with the
+// parameters EPSG publishes for the transformation (EPSG:1272, towgs84
+// -199.87, 74.79, 246.62, good to about a metre), written out here rather
+// than taken from the constants they are to check
+func molodenskyToGGRS87(lat, lon float64) (float64, float64) {
+	dx, dy, dz := 199.87, -74.79, -246.62
+	f := 1 / 298.257223563
+	df := 1/298.257222101 - f
+	a := 6378137.0
+	e2 := f * (2 - f)
+
+	phi, lambda := radians(lat), radians(lon)
+	sinPhi, cosPhi := math.Sin(phi), math.Cos(phi)
+	w := math.Sqrt(1 - e2*sinPhi*sinPhi)
+	m := a * (1 - e2) / (w * w * w)
+	n := a / w
+	b := a * (1 - f)
+
+	dPhi := (-dx*sinPhi*math.Cos(lambda) - dy*sinPhi*math.Sin(lambda) + dz*cosPhi +
+		df*(m*a/b+n*b/a)*sinPhi*cosPhi) / m
+	dLambda := (-dx*math.Sin(lambda) + dy*math.Cos(lambda)) / (n * cosPhi)
+
+	return lat + degrees(dPhi), lon + degrees(dLambda)
+}
+
+func TestEGSA87_PublishedTransformation(t *testing.T) {
+	t.Parallel()
+
+	// Athens, Thessaloniki, Heraklion, Corfu and Rhodes
+	for _, p := range [][2]float64{{37.9715, 23.7257}, {40.6401, 22.9444}, {35.3387, 25.1442}, {39.6243, 19.9217}, {36.4341, 28.2176}} {
+		e, n := wgs84ToEGSA87(p[0], p[1])
+		we, wn := snyderTM(molodenskyToGGRS87(p[0], p[1]))
+		if math.Abs(e-we) > 1 || math.Abs(n-wn) > 1 {
+			t.Fatalf("wgs84ToEGSA87(%v) = %.3f, %.3f, want %.3f, %.3f", p, e, n, we, wn)
+		}
+
+		lat, lon := egsa87ToWGS84(we, wn)
+		// 1e-5 degrees is about a metre
+		if math.Abs(lat-p[0]) > 1e-5 || math.Abs(lon-p[1]) > 1e-5 {
+			t.Fatalf("egsa87ToWGS84(%.3f, %.3f) = %f, %f, want %v", we, wn, lat, lon, p)
+		}
+	}
+}
+
+func TestEGSA87_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for lat := 34.8; lat <= 41.8; lat += 0.7 {
+		for lon := 19.4; lon <= 29.7; lon += 1.1 {
+			e, n := wgs84ToEGSA87(lat, lon)
+			if err := validEGSA87(e, n); err != nil {
+				t.Fatalf("%f, %f: %v", lat, lon, err)
+			}
+			backLat, backLon := egsa87ToWGS84(e, n)
+			// 1e-8 degrees is about a millimetre
+			if math.Abs(backLat-lat) > 1e-8 || math.Abs(backLon-lon) > 1e-8 {
+				t.Fatalf("%f, %f came back as %f, %f", lat, lon, backLat, backLon)
+			}
+		}
+	}
+}
+
+func TestParseGridCorner(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseGridCorner(" 476000.5 ", "4205000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := formatGridCorner(c)
+	if x != "476000.500" || y != "4205000.000" {
+		t.Fatalf("formatGridCorner() = %s, %s", x, y)
+	}
+
+	for _, bad := range [][2]string{{"abc", "4205000"}, {"476000", ""}, {"38.1", "23.7"}, {"4205000", "476000"}} {
+		if _, err := parseGridCorner(bad[0], bad[1]); err == nil {
+			t.Fatalf("expected an error for %v", bad)
+		}
+	}
+}
+
+func TestParseCornerList(t *testing.T) {
+	t.Parallel()
+
+	plan := `Α/Α	X	Y
+Σ1	476000.00	4205000.00
+Σ2	476100.00	4205000.00
+3	476100.00	4205100.00
+4205100.00;476000.00
+Σ1	476000.00	4205000.00
+`
+	coords, err := parseCornerList(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coords) != 4 {
+		t.Fatalf("expected 4 corners without the closing one, got %d", len(coords))
+	}
+	// a 100m square
+	if a := ellipsoidalArea(coords); math.Abs(a-10000) > 10 {
+		t.Fatalf("the area of the plan is %f m²", a)
+	}
+
+	degrees, err := parseCornerList("38, 23\n38, 23.01\n38.01, 23.01\n")
+	if err != nil || len(degrees) != 3 || degrees[1].Longitude != 23.01 {
+		t.Fatalf("unexpected corners in degrees: %+v %v", degrees, err)
+	}
+
+	pasted := "Σ1 37°58'12\"N 23°43'39\"E\n" +
+		"2\tN 37 58.3 E 23 43.7\n" +
+		"3; 37,9720; 23,7290\n" +
+		"23.7285E 37.9705N\n"
+	dms, err := parseCornerList(pasted)
+	if err != nil || len(dms) != 4 {
+		t.Fatalf("unexpected corners from the pasted degrees: %+v %v", dms, err)
+	}
+	if math.Abs(dms[0].Latitude-37.97) > 1e-9 || math.Abs(dms[0].Longitude-(23+43.0/60+39.0/3600)) > 1e-9 {
+		t.Errorf("the first corner = %+v", dms[0])
+	}
+	if dms[2].Latitude != 37.972 || dms[3].Longitude != 23.7285 {
+		t.Errorf("the decimal commas and the letters = %+v %+v", dms[2], dms[3])
+	}
+
+	commas, err := parseCornerList("Σ1 476000,00 4205000,00\nΣ2 476100,00 4205000,00\nΣ3 476100,50 4205100,50\n")
+	if err != nil || len(commas) != 3 {
+		t.Fatalf("unexpected corners from metres with decimal commas: %+v %v", commas, err)
+	}
+	if x, _ := formatGridCorner(commas[2]); x != "476100.500" {
+		t.Errorf("the third corner is at X %s", x)
+	}
+
+	if _, err := parseCornerList("476000 4205000\n12\n"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected an error on line 2, got %v", err)
+	}
+	if _, err := parseCornerList("X Y\n"); err == nil {
+		t.Fatal("expected an error without corners")
+	}
+}
+
+func TestEGSA87Lines(t *testing.T) {
+	t.Parallel()
+
+	c, _ := parseGridCorner("476000", "4205000")
+	lines := egsa87Lines([]Coordinates{c})
+	if len(lines) != 1 || lines[0] != "1. X 476000.000, Y 4205000.000" {
+		t.Fatalf("unexpected lines: %v", lines)
+	}
+}
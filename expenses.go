@@ -0,0 +1,151 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+var expenseCategories = []string{
+	"Φόρος ακινήτου (ΕΝΦΙΑ)",
+	"ΤΟΕΒ / Άρδευση",
+	"Τοπογράφος",
+	"Συμβολαιογράφος",
+	"Βελτιώσεις",
+	"Άλλο",
+}
+
+// Who pays for an expense
+const (
+	bearerOwner  = "owner"
+	bearerRenter = "renter"
+)
+
+var bearerLabels = map[string]string{
+	bearerOwner:  "Εκμισθωτής",
+	bearerRenter: "Μισθωτής",
+}
+
+// Net position of a contract for a year. The rent is the part of the yearly
+// rent that corresponds to the months of the year the contract was running.
+type ContractCostRow struct {
+	EntryID       uint
+	Name          string
+	Months        int
+	Rent          float64
+	OwnerExpense  float64
+	RenterExpense float64
+}
+
+// What the owner keeps after his expenses
+func (r ContractCostRow) OwnerNet() float64 {
+	return TruncateFloatTo2Decimals(r.Rent - r.OwnerExpense)
+}
+
+// What the land really costs the renter
+func (r ContractCostRow) RenterCost() float64 {
+	return TruncateFloatTo2Decimals(r.Rent + r.RenterExpense)
+}
+
+func buildContractCostRows(entries []Entry, expenses []Expense, year int) ([]ContractCostRow, error) {
+	var rows []ContractCostRow
+
+	for _, e := range entries {
+		from, to, ok, err := leasePeriodInYear(e.Start, e.ActualEnd(), year)
+		if err != nil {
+			return nil, fmt.Errorf("contract %s: %v", e.Name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		months := monthsInPeriod(from, to)
+		row := ContractCostRow{
+			EntryID: e.ID,
+			Name:    contractLabel(e),
+			Months:  months,
+			Rent:    proratedRent(e, from, to),
+		}
+
+		for _, x := range expenses {
+			if x.EntryID != e.ID {
+				continue
+			}
+			d, err := parseDate(x.Date)
+			if err != nil || d.Year() != year {
+				continue
+			}
+
+			switch x.Bearer {
+			case bearerOwner:
+				row.OwnerExpense += x.Amount
+			case bearerRenter:
+				row.RenterExpense += x.Amount
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func contractCostReport(db *sql.DB, year int, statuses []string) ([]ContractCostRow, error) {
+	y := strconv.Itoa(year)
+
+	entries, err := getAllEntriesByYear(db, y)
+	if err != nil {
+		return nil, err
+	}
+	entries = filterByStatus(entries, statuses)
+	expenses, err := getExpensesByYear(db, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildContractCostRows(entries, expenses, year)
+}
+
+var contractCostHeader = []string{"Συμβόλαιο", "Μήνες", "Μίσθωμα (€)", "Έξοδα εκμισθωτή (€)", "Έξοδα μισθωτή (€)", "Καθαρό εκμισθωτή (€)", "Κόστος μισθωτή (€)"}
+
+func contractCostRecords(rows []ContractCostRow) [][]string {
+	records := make([][]string, 0, len(rows)+1)
+
+	var total ContractCostRow
+	for _, r := range rows {
+		records = append(records, []string{
+			r.Name,
+			strconv.Itoa(r.Months),
+			fmt.Sprintf("%.2f", r.Rent),
+			fmt.Sprintf("%.2f", r.OwnerExpense),
+			fmt.Sprintf("%.2f", r.RenterExpense),
+			fmt.Sprintf("%.2f", r.OwnerNet()),
+			fmt.Sprintf("%.2f", r.RenterCost()),
+		})
+		total.Rent += r.Rent
+		total.OwnerExpense += r.OwnerExpense
+		total.RenterExpense += r.RenterExpense
+	}
+	records = append(records, []string{
+		"Σύνολο",
+		"",
+		fmt.Sprintf("%.2f", total.Rent),
+		fmt.Sprintf("%.2f", total.OwnerExpense),
+		fmt.Sprintf("%.2f", total.RenterExpense),
+		fmt.Sprintf("%.2f", total.OwnerNet()),
+		fmt.Sprintf("%.2f", total.RenterCost()),
+	})
+
+	return records
+}
+
+func contractCostCSV(rows []ContractCostRow) ([]byte, error) {
+	return csvBytes(contractCostHeader, contractCostRecords(rows))
+}
+
+func contractCostPDF(year int, rows []ContractCostRow) ([]byte, error) {
+	pdf := newReportPDF("L", fmt.Sprintf("Μισθώματα και έξοδα ανά συμβόλαιο - %d", year))
+	pdfTable(pdf, contractCostHeader, []float64{60, 17, 30, 42, 42, 43, 43}, contractCostRecords(rows))
+
+	return pdfBytes(pdf)
+}
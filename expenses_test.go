@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBuildContractCostRows(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{ID: 1, Name: "Κάμπος", Rent: 1200, Start: "01-07-2024", End: "30-06-2026"},
+		{ID: 2, Name: "Λόφος", Rent: 600, Start: "01-01-2020", End: "31-12-2022"},
+	}
+	expenses := []Expense{
+		{EntryID: 1, Date: "10-03-2025", Amount: 100, Bearer: bearerOwner},
+		{EntryID: 1, Date: "15-09-2025", Amount: 40, Bearer: bearerRenter},
+		{EntryID: 1, Date: "15-09-2024", Amount: 999, Bearer: bearerOwner},
+		{EntryID: 2, Date: "01-05-2025", Amount: 50, Bearer: bearerOwner},
+	}
+
+	rows, err := buildContractCostRows(entries, expenses, 2025)
+	if err != nil {
+		t.Fatalf("buildContractCostRows returned error: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected only the contract running in 2025, got %+v", rows)
+	}
+
+	r := rows[0]
+	if r.Months != 12 || r.Rent != 1200 || r.OwnerExpense != 100 || r.RenterExpense != 40 {
+		t.Fatalf("unexpected row: %+v", r)
+	}
+	if r.OwnerNet() != 1100 || r.RenterCost() != 1240 {
+		t.Fatalf("unexpected net: owner %v, renter %v", r.OwnerNet(), r.RenterCost())
+	}
+
+	rows, err = buildContractCostRows(entries, expenses, 2026)
+	if err != nil {
+		t.Fatalf("buildContractCostRows returned error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Months != 6 || rows[0].Rent != 600 || rows[0].OwnerExpense != 0 {
+		t.Fatalf("expected a pro-rated half year without expenses, got %+v", rows)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GeoJSON as in RFC 7946, only as much of it as fields need
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string           `json:"type"`
+	Properties map[string]any   `json:"properties"`
+	Geometry   *geoJSONGeometry `json:"geometry"`
+}
+
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// The contracts that have coordinates as a FeatureCollection, one
+// MultiPolygon per contract with its terms on the day
+func contractsGeoJSON(entries []Entry, day time.Time) ([]byte, error) {
+	fc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for _, e := range entries {
+		parts := exportRings(e.Coords)
+		if len(parts) == 0 {
+			continue
+		}
+		coordinates, err := json.Marshal(parts)
+		if err != nil {
+			return nil, err
+		}
+
+		terms := e.AsOf(day)
+		fc.Features = append(fc.Features, geoJSONFeature{
+			Type: "Feature",
+			Properties: map[string]any{
+				"id":         e.ID,
+				"number":     e.Number,
+				"contract":   e.Name,
+				"kaek":       terms.KAEK,
+				"atak":       terms.ATAK,
+				"owners":     strings.Join(ownerNames(terms.Owners), ", "),
+				"renters":    strings.Join(renterNames(terms.Renters), ", "),
+				"crop":       terms.Type,
+				"start":      terms.Start,
+				"end":        terms.End,
+				"status":     e.Status,
+				"size":       terms.Size,
+				"drawn_size": drawnSize(e.Coords),
+			},
+			Geometry: &geoJSONGeometry{Type: "MultiPolygon", Coordinates: coordinates},
+		})
+	}
+
+	return json.MarshalIndent(fc, "", "  ")
+}
+
+// The fields of a FeatureCollection, a Feature or a bare geometry. Only
+// polygons are fields, the other features come back with an error.
+func parseGeoJSON(data []byte) ([]ImportedShape, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("not a GeoJSON file: %v", err)
+	}
+
+	var features []geoJSONFeature
+	switch head.Type {
+	case "FeatureCollection":
+		var fc geoJSONFeatureCollection
+		if err := json.Unmarshal(data, &fc); err != nil {
+			return nil, fmt.Errorf("not a GeoJSON file: %v", err)
+		}
+		features = fc.Features
+	case "Feature":
+		var f geoJSONFeature
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("not a GeoJSON file: %v", err)
+		}
+		features = []geoJSONFeature{f}
+	case "":
+		return nil, errors.New("not a GeoJSON file: no type")
+	default:
+		var g geoJSONGeometry
+		if err := json.Unmarshal(data, &g); err != nil {
+			return nil, fmt.Errorf("not a GeoJSON file: %v", err)
+		}
+		features = []geoJSONFeature{{Type: "Feature", Geometry: &g}}
+	}
+
+	shapes := make([]ImportedShape, 0, len(features))
+	for i, f := range features {
+		s := ImportedShape{
+			Name: geoJSONProperty(f.Properties, "contract", "name", "Name", "NAME"),
+			KAEK: geoJSONProperty(f.Properties, "kaek", "KAEK"),
+		}
+		if s.Name == "" && s.KAEK == "" {
+			s.Name = fmt.Sprintf("feature %d", i+1)
+		}
+		s.Coords, s.Err = geoJSONCoords(f.Geometry)
+		shapes = append(shapes, s)
+	}
+
+	return shapes, nil
+}
+
+// The first of the keys the properties have, as text
+func geoJSONProperty(properties map[string]any, keys ...string) string {
+	for _, k := range keys {
+		v, ok := properties[k]
+		if !ok || v == nil {
+			continue
+		}
+		if s := strings.TrimSpace(fmt.Sprint(v)); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func geoJSONCoords(g *geoJSONGeometry) ([]Coordinates, error) {
+	if g == nil {
+		return nil, errors.New("the feature has no geometry")
+	}
+
+	var parts [][][][]float64
+	switch g.Type {
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &rings); err != nil {
+			return nil, fmt.Errorf("bad polygon: %v", err)
+		}
+		parts = [][][][]float64{rings}
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &parts); err != nil {
+			return nil, fmt.Errorf("bad multipolygon: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("a %s is not a field, only polygons are", g.Type)
+	}
+
+	var shape [][]ring
+	for _, rings := range parts {
+		var part []ring
+		for _, positions := range rings {
+			var corners ring
+			for _, p := range positions {
+				if len(p) < 2 {
+					return nil, errors.New("a position needs a longitude and a latitude")
+				}
+				corners = append(corners, Coordinates{Latitude: p[1], Longitude: p[0]})
+			}
+			part = append(part, openRing(corners))
+		}
+		shape = append(shape, part)
+	}
+
+	coords := numberShape(shape)
+	if len(coords) == 0 {
+		return nil, errors.New("the polygon has no corners")
+	}
+
+	return coords, validateShape(coords)
+}
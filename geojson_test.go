@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// a field with a hole, the outer ring clockwise
+func fieldWithHole() []Coordinates {
+	outer := corners(0, 0, 38, 23, 38.01, 23, 38.01, 23.01, 38, 23.01)
+	hole := corners(0, 1, 38.002, 23.002, 38.002, 23.004, 38.004, 23.004, 38.004, 23.002)
+	return append(outer, hole...)
+}
+
+func TestContractsGeoJSON_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{
+			ID: 7, Number: "2025/7", Name: "Κάτω χωράφι", KAEK: "123456789012", Type: "Σιτάρι",
+			Start: "01-10-2025", End: "30-09-2030", Status: statusActive, Size: 99,
+			Owners:  []OwnerDetails{{FirstName: "Γιώργος", LastName: "Παππάς"}},
+			Renters: []RenterDetails{{FirstName: "Μαρία", LastName: "Νικολάου"}},
+			Coords:  fieldWithHole(),
+		},
+		{ID: 8, Name: "no drawing"},
+	}
+
+	data, err := contractsGeoJSON(entries, date("01-01-2026"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fc struct {
+		Features []struct {
+			Properties map[string]any
+			Geometry   struct {
+				Type        string
+				Coordinates [][][][2]float64
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &fc); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Features) != 1 {
+		t.Fatalf("expected only the contract with coordinates, got %d features", len(fc.Features))
+	}
+	f := fc.Features[0]
+	if f.Properties["kaek"] != "123456789012" || f.Properties["crop"] != "Σιτάρι" ||
+		f.Properties["owners"] != "Γιώργος Παππάς" || f.Properties["renters"] != "Μαρία Νικολάου" {
+		t.Fatalf("unexpected properties: %v", f.Properties)
+	}
+
+	// longitude first, closed, the outer ring counter-clockwise and the hole clockwise
+	rings := f.Geometry.Coordinates[0]
+	if f.Geometry.Type != "MultiPolygon" || len(rings) != 2 || len(rings[0]) != 5 || rings[0][0] != rings[0][4] {
+		t.Fatalf("unexpected geometry: %+v", f.Geometry)
+	}
+	if rings[0][0] != [2]float64{23, 38} {
+		t.Fatalf("expected longitude first, got %v", rings[0][0])
+	}
+	toPoints := func(positions [][2]float64) []point {
+		var pts []point
+		for _, p := range positions[:len(positions)-1] {
+			pts = append(pts, point{p[0], p[1]})
+		}
+		return pts
+	}
+	if signedArea(toPoints(rings[0])) <= 0 || signedArea(toPoints(rings[1])) >= 0 {
+		t.Fatal("the rings don't follow the right hand rule")
+	}
+
+	shapes, err := parseGeoJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shapes) != 1 || shapes[0].Err != nil || shapes[0].Name != "Κάτω χωράφι" || shapes[0].KAEK != "123456789012" {
+		t.Fatalf("unexpected shapes: %+v", shapes)
+	}
+	if got, want := ellipsoidalArea(shapes[0].Coords), ellipsoidalArea(entries[0].Coords); math.Abs(got-want) > 1e-6 {
+		t.Fatalf("the area changed on the way: %f and %f", got, want)
+	}
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	t.Parallel()
+
+	polygon := `{"type": "Feature", "properties": {"name": "Αλώνι"},
+		"geometry": {"type": "Polygon", "coordinates": [[[23, 38, 120], [23.01, 38, 121], [23.01, 38.01, 119], [23, 38]]]}}`
+	shapes, err := parseGeoJSON([]byte(polygon))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shapes) != 1 || shapes[0].Err != nil || shapes[0].Name != "Αλώνι" || len(shapes[0].Coords) != 3 {
+		t.Fatalf("unexpected shapes: %+v", shapes)
+	}
+	if c := shapes[0].Coords[1]; c.Latitude != 38 || c.Longitude != 23.01 || c.Seq != 1 {
+		t.Fatalf("unexpected corner: %+v", c)
+	}
+
+	bare := `{"type": "Polygon", "coordinates": [[[23, 38], [23.01, 38], [23.01, 38.01], [23, 38]]]}`
+	if shapes, err := parseGeoJSON([]byte(bare)); err != nil || len(shapes) != 1 || shapes[0].Name != "feature 1" {
+		t.Fatalf("unexpected bare geometry: %+v %v", shapes, err)
+	}
+
+	point := `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {}, "geometry": {"type": "Point", "coordinates": [23, 38]}}]}`
+	if shapes, err := parseGeoJSON([]byte(point)); err != nil || shapes[0].Err == nil {
+		t.Fatalf("expected the point to be refused: %+v %v", shapes, err)
+	}
+
+	crossed := `{"type": "Polygon", "coordinates": [[[23, 38], [23.01, 38.01], [23.01, 38], [23, 38.01], [23, 38]]]}`
+	if shapes, err := parseGeoJSON([]byte(crossed)); err != nil || shapes[0].Err == nil {
+		t.Fatalf("expected the bow tie to be refused: %+v %v", shapes, err)
+	}
+
+	if _, err := parseGeoJSON([]byte("<kml/>")); err == nil {
+		t.Fatal("expected an error for a file that isn't JSON")
+	}
+}
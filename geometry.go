@@ -0,0 +1,401 @@
+package main
+
+import "math"
+
+// Parcels are small enough to treat latitude and longitude as plane
+// coordinates when all we need is whether two of them meet.
+
+type point struct {
+	x, y float64
+}
+
+// The corners of a parcel, the missing ones saved as 0,0 are left out
+func polygonPoints(coords []Coordinates) []point {
+	var pts []point
+	for _, c := range coords {
+		if c.Latitude == 0 && c.Longitude == 0 {
+			continue
+		}
+		pts = append(pts, point{c.Longitude, c.Latitude})
+	}
+
+	return pts
+}
+
+// Which side of the line a-b c is on: >0 left, <0 right, 0 on the line
+func orientation(a, b, c point) float64 {
+	return (b.x-a.x)*(c.y-a.y) - (b.y-a.y)*(c.x-a.x)
+}
+
+func onSegment(a, b, p point) bool {
+	return min(a.x, b.x) <= p.x && p.x <= max(a.x, b.x) &&
+		min(a.y, b.y) <= p.y && p.y <= max(a.y, b.y)
+}
+
+// Segments a-b and c-d share at least one point
+func segmentsIntersect(a, b, c, d point) bool {
+	o1 := orientation(a, b, c)
+	o2 := orientation(a, b, d)
+	o3 := orientation(c, d, a)
+	o4 := orientation(c, d, b)
+
+	if ((o1 > 0 && o2 < 0) || (o1 < 0 && o2 > 0)) && ((o3 > 0 && o4 < 0) || (o3 < 0 && o4 > 0)) {
+		return true
+	}
+
+	return (o1 == 0 && onSegment(a, b, c)) ||
+		(o2 == 0 && onSegment(a, b, d)) ||
+		(o3 == 0 && onSegment(c, d, a)) ||
+		(o4 == 0 && onSegment(c, d, b))
+}
+
+// Ray casting, points on the boundary may go either way
+func pointInPolygon(p point, poly []point) bool {
+	inside := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if (a.y > p.y) != (b.y > p.y) && p.x < (b.x-a.x)*(p.y-a.y)/(b.y-a.y)+a.x {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// Shoelace, positive when the corners go counter-clockwise
+func signedArea(poly []point) float64 {
+	var a float64
+	for i := range poly {
+		j := (i + 1) % len(poly)
+		a += poly[i].x*poly[j].y - poly[j].x*poly[i].y
+	}
+
+	return a / 2
+}
+
+func polygonArea(poly []point) float64 {
+	a := signedArea(poly)
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Splits a simple polygon into triangles by clipping ears
+func triangulate(poly []point) [][]point {
+	if len(poly) < 3 {
+		return nil
+	}
+
+	// work counter-clockwise
+	pts := make([]point, len(poly))
+	copy(pts, poly)
+	if signedArea(pts) < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+
+	var triangles [][]point
+	for len(pts) > 3 {
+		clipped := false
+		for i := range pts {
+			prev := pts[(i+len(pts)-1)%len(pts)]
+			cur := pts[i]
+			next := pts[(i+1)%len(pts)]
+			if orientation(prev, cur, next) <= 0 {
+				continue // reflex or flat corner
+			}
+
+			ear := true
+			for j, p := range pts {
+				if j == i || j == (i+1)%len(pts) || j == (i+len(pts)-1)%len(pts) {
+					continue
+				}
+				if orientation(prev, cur, p) >= 0 && orientation(cur, next, p) >= 0 && orientation(next, prev, p) >= 0 {
+					ear = false
+					break
+				}
+			}
+			if !ear {
+				continue
+			}
+
+			triangles = append(triangles, []point{prev, cur, next})
+			pts = append(pts[:i:i], pts[i+1:]...)
+			clipped = true
+			break
+		}
+		// not a simple polygon, take what is left as is
+		if !clipped {
+			break
+		}
+	}
+	triangles = append(triangles, pts)
+
+	return triangles
+}
+
+// Sutherland-Hodgman, clip has to be convex and counter-clockwise
+func clipConvex(subject, clip []point) []point {
+	out := subject
+	for i := range clip {
+		if len(out) == 0 {
+			break
+		}
+		a, b := clip[i], clip[(i+1)%len(clip)]
+		in := out
+		out = nil
+
+		for j := range in {
+			cur := in[j]
+			prev := in[(j+len(in)-1)%len(in)]
+			curIn := orientation(a, b, cur) >= 0
+			prevIn := orientation(a, b, prev) >= 0
+
+			if curIn != prevIn {
+				out = append(out, lineIntersection(prev, cur, a, b))
+			}
+			if curIn {
+				out = append(out, cur)
+			}
+		}
+	}
+
+	return out
+}
+
+// Where the line through p1-p2 meets the line through p3-p4
+func lineIntersection(p1, p2, p3, p4 point) point {
+	d := (p1.x-p2.x)*(p3.y-p4.y) - (p1.y-p2.y)*(p3.x-p4.x)
+	if d == 0 {
+		return p2
+	}
+	t := ((p1.x-p3.x)*(p3.y-p4.y) - (p1.y-p3.y)*(p3.x-p4.x)) / d
+
+	return point{p1.x + t*(p2.x-p1.x), p1.y + t*(p2.y-p1.y)}
+}
+
+// Area shared by two simple polygons, in the units of the points squared
+func intersectionArea(a, b []point) float64 {
+	if len(a) < 3 || len(b) < 3 {
+		return 0
+	}
+
+	var area float64
+	for _, ta := range triangulate(a) {
+		for _, tb := range triangulate(b) {
+			if signedArea(tb) < 0 {
+				tb = []point{tb[2], tb[1], tb[0]}
+			}
+			if clipped := clipConvex(ta, tb); len(clipped) >= 3 {
+				area += polygonArea(clipped)
+			}
+		}
+	}
+
+	return area
+}
+
+// The polygons share some area, touching along an edge or at a corner
+// doesn't count
+func polygonsOverlap(a, b []point) bool {
+	if !polygonsIntersect(a, b) {
+		return false
+	}
+
+	smaller := min(polygonArea(a), polygonArea(b))
+	return intersectionArea(a, b) > smaller*1e-6
+}
+
+// The polygons share some area or touch, false if either has less than 3 corners
+func polygonsIntersect(a, b []point) bool {
+	if len(a) < 3 || len(b) < 3 {
+		return false
+	}
+
+	for i := range a {
+		for j := range b {
+			if segmentsIntersect(a[i], a[(i+1)%len(a)], b[j], b[(j+1)%len(b)]) {
+				return true
+			}
+		}
+	}
+
+	// no edges cross so either one is inside the other or they are apart
+	return pointInPolygon(a[0], b) || pointInPolygon(b[0], a)
+}
+
+// A part of a field, the outer ring with its holes
+type polygon struct {
+	outer []point
+	holes [][]point
+}
+
+// The parts of a field, the rings with less than 3 corners are left out
+func shapePolygons(coords []Coordinates) []polygon {
+	var polys []polygon
+	for _, rings := range shapeRings(coords) {
+		outer := polygonPoints(openRing(rings[0]))
+		if len(outer) < 3 {
+			continue
+		}
+		poly := polygon{outer: outer}
+		for _, r := range rings[1:] {
+			if hole := polygonPoints(openRing(r)); len(hole) >= 3 {
+				poly.holes = append(poly.holes, hole)
+			}
+		}
+		polys = append(polys, poly)
+	}
+
+	return polys
+}
+
+// The point is in one of the parts and not in its holes
+func shapeContains(polys []polygon, p point) bool {
+	for _, poly := range polys {
+		if !pointInPolygon(p, poly.outer) {
+			continue
+		}
+		inHole := false
+		for _, h := range poly.holes {
+			inHole = inHole || pointInPolygon(p, h)
+		}
+		if !inHole {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The area of the parts without their holes
+func shapeArea(polys []polygon) float64 {
+	var area float64
+	for _, p := range polys {
+		area += polygonArea(p.outer)
+		for _, h := range p.holes {
+			area -= polygonArea(h)
+		}
+	}
+
+	return area
+}
+
+// Area shared by two fields. The holes are inside their outer ring and apart
+// from each other, so what the outer rings share less what the holes take out
+// of it is |A∩B| - |HA∩B| - |A∩HB| + |HA∩HB| with A, B the outer rings.
+func shapesIntersectionArea(a, b []polygon) float64 {
+	var area float64
+	for _, pa := range a {
+		for _, pb := range b {
+			if !polygonsIntersect(pa.outer, pb.outer) {
+				continue
+			}
+			area += intersectionArea(pa.outer, pb.outer)
+			for _, ha := range pa.holes {
+				area -= intersectionArea(ha, pb.outer)
+			}
+			for _, hb := range pb.holes {
+				area -= intersectionArea(pa.outer, hb)
+				for _, ha := range pa.holes {
+					area += intersectionArea(ha, hb)
+				}
+			}
+		}
+	}
+
+	return max(area, 0)
+}
+
+// The fields share some area, a field in the hole of the other doesn't
+func shapesOverlap(a, b []polygon) bool {
+	shared := shapesIntersectionArea(a, b)
+	if shared == 0 {
+		return false
+	}
+
+	return shared > min(shapeArea(a), shapeArea(b))*1e-6
+}
+
+// Metres per degree of latitude and of longitude at the latitude, the series
+// of the WGS84 meridian and parallel, good to a centimetre per kilometre
+func metresPerDegree(lat float64) (float64, float64) {
+	phi := radians(lat)
+	perLat := 111132.954 - 559.822*math.Cos(2*phi) + 1.175*math.Cos(4*phi)
+	perLon := 111412.84*math.Cos(phi) - 93.5*math.Cos(3*phi) + 0.118*math.Cos(5*phi)
+
+	return perLat, perLon
+}
+
+// The fields in metres east and north of the origin, near enough for
+// lengths and areas at the size of a few neighbouring fields
+func localMetres(polys []polygon, origin point) []polygon {
+	perLat, perLon := metresPerDegree(origin.y)
+	project := func(ring []point) []point {
+		out := make([]point, len(ring))
+		for i, p := range ring {
+			out[i] = point{(p.x - origin.x) * perLon, (p.y - origin.y) * perLat}
+		}
+		return out
+	}
+
+	local := make([]polygon, len(polys))
+	for i, p := range polys {
+		local[i] = polygon{outer: project(p.outer)}
+		for _, h := range p.holes {
+			local[i].holes = append(local[i].holes, project(h))
+		}
+	}
+
+	return local
+}
+
+// How much of c-d runs along a-b, no farther than tol from it
+func segmentOverlap(a, b, c, d point, tol float64) float64 {
+	length := math.Hypot(b.x-a.x, b.y-a.y)
+	if length == 0 {
+		return 0
+	}
+	ux, uy := (b.x-a.x)/length, (b.y-a.y)/length
+
+	// distance from the line and position along it
+	off := func(p point) float64 { return math.Abs((p.x-a.x)*uy - (p.y-a.y)*ux) }
+	along := func(p point) float64 { return (p.x-a.x)*ux + (p.y-a.y)*uy }
+	if off(c) > tol || off(d) > tol {
+		return 0
+	}
+
+	from, to := along(c), along(d)
+	if from > to {
+		from, to = to, from
+	}
+	return max(min(to, length)-max(from, 0), 0)
+}
+
+func polygonRings(p polygon) [][]point {
+	return append([][]point{p.outer}, p.holes...)
+}
+
+// Length of the boundary the fields share, their edges no farther apart than
+// tol, in the units of the points. Fields drawn apart never match exactly,
+// the tolerance takes up the slack. Corners that only touch share nothing.
+func sharedBoundary(a, b []polygon, tol float64) float64 {
+	var shared float64
+	for _, pa := range a {
+		for _, pb := range b {
+			for _, ra := range polygonRings(pa) {
+				for _, rb := range polygonRings(pb) {
+					for i := range ra {
+						for j := range rb {
+							shared += segmentOverlap(ra[i], ra[(i+1)%len(ra)], rb[j], rb[(j+1)%len(rb)], tol)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return shared
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func square(x, y, side float64) []point {
+	return []point{{x, y}, {x + side, y}, {x + side, y + side}, {x, y + side}}
+}
+
+func TestSegmentsIntersect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		a, b, c, d point
+		want       bool
+	}{
+		{"crossing", point{0, 0}, point{2, 2}, point{0, 2}, point{2, 0}, true},
+		{"apart", point{0, 0}, point{1, 0}, point{0, 1}, point{1, 1}, false},
+		{"touching at an end", point{0, 0}, point{1, 1}, point{1, 1}, point{2, 0}, true},
+		{"collinear overlapping", point{0, 0}, point{2, 0}, point{1, 0}, point{3, 0}, true},
+		{"collinear apart", point{0, 0}, point{1, 0}, point{2, 0}, point{3, 0}, false},
+	}
+
+	for _, tt := range tests {
+		if got := segmentsIntersect(tt.a, tt.b, tt.c, tt.d); got != tt.want {
+			t.Errorf("%s: segmentsIntersect = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	t.Parallel()
+
+	// an L shape
+	poly := []point{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}
+	if !pointInPolygon(point{0.5, 1.5}, poly) {
+		t.Fatalf("expected the point in the L")
+	}
+	if pointInPolygon(point{1.5, 1.5}, poly) {
+		t.Fatalf("expected the point in the notch to be outside")
+	}
+}
+
+func TestIntersectionArea(t *testing.T) {
+	t.Parallel()
+
+	got := intersectionArea(square(0, 0, 2), square(1, 1, 2))
+	if math.Abs(got-1) > 1e-9 {
+		t.Fatalf("expected an area of 1, got %v", got)
+	}
+
+	// a concave polygon, the notch of the L doesn't count
+	l := []point{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}
+	got = intersectionArea(l, square(1, 1, 1))
+	if math.Abs(got) > 1e-9 {
+		t.Fatalf("expected no shared area, got %v", got)
+	}
+	got = intersectionArea(l, square(0, 0, 2))
+	if math.Abs(got-3) > 1e-9 {
+		t.Fatalf("expected an area of 3, got %v", got)
+	}
+}
+
+func TestPolygonsOverlap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		a, b      []point
+		intersect bool
+		overlap   bool
+	}{
+		{"overlapping", square(0, 0, 2), square(1, 1, 2), true, true},
+		{"one inside the other", square(0, 0, 4), square(1, 1, 1), true, true},
+		{"sharing an edge", square(0, 0, 1), square(1, 0, 1), true, false},
+		{"sharing a corner", square(0, 0, 1), square(1, 1, 1), true, false},
+		{"apart", square(0, 0, 1), square(3, 3, 1), false, false},
+		{"too few corners", square(0, 0, 1)[:2], square(0, 0, 1), false, false},
+	}
+
+	for _, tt := range tests {
+		if got := polygonsIntersect(tt.a, tt.b); got != tt.intersect {
+			t.Errorf("%s: polygonsIntersect = %v, want %v", tt.name, got, tt.intersect)
+		}
+		if got := polygonsOverlap(tt.a, tt.b); got != tt.overlap {
+			t.Errorf("%s: polygonsOverlap = %v, want %v", tt.name, got, tt.overlap)
+		}
+	}
+}
+
+func TestPolygonPoints_SkipsMissingCorners(t *testing.T) {
+	t.Parallel()
+
+	got := polygonPoints([]Coordinates{{Latitude: 38, Longitude: 23}, {}, {Latitude: 38.1, Longitude: 23.1}})
+	if len(got) != 2 || got[1] != (point{23.1, 38.1}) {
+		t.Fatalf("unexpected points: %+v", got)
+	}
+}
+
+func TestShapesIntersectionArea_TakesOutTheHoles(t *testing.T) {
+	t.Parallel()
+
+	// a 10x10 field with a 4x4 hole and a 2x2 field in the hole
+	outer := append(corners(0, 0, 1, 1, 1, 11, 11, 11, 11, 1), corners(0, 1, 4, 4, 4, 8, 8, 8, 8, 4)...)
+	field := shapePolygons(outer)
+	enclave := shapePolygons(corners(0, 0, 5, 5, 5, 7, 7, 7, 7, 5))
+
+	if got := shapeArea(field); math.Abs(got-84) > 1e-9 {
+		t.Fatalf("shapeArea() = %v, want 84", got)
+	}
+	if got := shapesIntersectionArea(field, enclave); got > 1e-9 {
+		t.Fatalf("the enclave is in the hole, got %v", got)
+	}
+	if shapesOverlap(field, enclave) {
+		t.Fatal("the enclave doesn't overlap the field")
+	}
+
+	// half in the hole, half on the field
+	straddling := shapePolygons(corners(0, 0, 3, 5, 3, 7, 5, 7, 5, 5))
+	if got := shapesIntersectionArea(field, straddling); math.Abs(got-2) > 1e-9 {
+		t.Fatalf("shapesIntersectionArea() = %v, want 2", got)
+	}
+
+	// two parts, the second one overlapping
+	twoParts := shapePolygons(append(corners(0, 0, 20, 20, 20, 21, 21, 21, 21, 20), corners(1, 0, 10, 10, 10, 12, 12, 12, 12, 10)...))
+	if got := shapesIntersectionArea(field, twoParts); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("shapesIntersectionArea() = %v, want 1", got)
+	}
+}
+
+func TestSegmentOverlap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		a, b, c, d point
+		want       float64
+	}{
+		{"same edge the other way", point{0, 0}, point{10, 0}, point{10, 0}, point{0, 0}, 10},
+		{"part of it", point{0, 0}, point{10, 0}, point{4, 0.2}, point{20, 0.3}, 6},
+		{"too far", point{0, 0}, point{10, 0}, point{0, 1}, point{10, 1}, 0},
+		{"crossing", point{0, 0}, point{10, 0}, point{5, -5}, point{5, 5}, 0},
+		{"past the end", point{0, 0}, point{10, 0}, point{11, 0}, point{15, 0}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := segmentOverlap(tt.a, tt.b, tt.c, tt.d, 0.5); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: segmentOverlap = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSharedBoundary(t *testing.T) {
+	t.Parallel()
+
+	a := []polygon{{outer: square(0, 0, 100)}}
+	// east of a, a little apart and only half as tall
+	b := []polygon{{outer: square(100.3, 20, 50)}}
+	if got := sharedBoundary(a, b, 0.5); math.Abs(got-50) > 1e-9 {
+		t.Errorf("shared by neighbours = %v, want 50", got)
+	}
+	if got := sharedBoundary(b, a, 0.5); math.Abs(got-50) > 1e-9 {
+		t.Errorf("shared the other way = %v, want 50", got)
+	}
+
+	// only the corners meet
+	c := []polygon{{outer: square(100, 100, 10)}}
+	if got := sharedBoundary(a, c, 0.5); got != 0 {
+		t.Errorf("shared at a corner = %v, want 0", got)
+	}
+
+	// in a hole, along one of its sides
+	holed := []polygon{{outer: square(0, 0, 100), holes: [][]point{square(40, 40, 20)}}}
+	inHole := []polygon{{outer: square(40, 40, 10)}}
+	if got := sharedBoundary(holed, inHole, 0.5); math.Abs(got-20) > 1e-9 {
+		t.Errorf("shared with the hole = %v, want 20", got)
+	}
+}
+
+func TestLocalMetres(t *testing.T) {
+	t.Parallel()
+
+	// a thousandth of a degree each way near Larisa
+	origin := point{22.4, 39.6}
+	local := localMetres([]polygon{{outer: []point{origin, {22.401, 39.6}, {22.401, 39.601}, {22.4, 39.601}}}}, origin)
+	got := local[0].outer[2]
+	// N·cosφ·π/180 = 85.89 m east and 111.03 m north at 39.6°
+	if math.Abs(got.x-85.89) > 0.05 || math.Abs(got.y-111.03) > 0.05 {
+		t.Errorf("corner = %v", got)
+	}
+	if area := shapeArea(local); math.Abs(area-ellipsoidalArea(corners(0, 0, 39.6, 22.4, 39.6, 22.401, 39.601, 22.401, 39.601, 22.4))) > 1 {
+		t.Errorf("area = %v, not the one on the ellipsoid", area)
+	}
+}
@@ -6,7 +6,9 @@ require (
 	fyne.io/fyne/v2 v2.7.3
 	fyne.io/x/fyne v0.0.0-20250411124620-88582bf2dfa6
 	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/mattn/go-sqlite3 v1.14.27
+	golang.org/x/image v0.41.0
 )
 
 require (
@@ -37,7 +39,6 @@ require (
 	github.com/srwiley/rasterx v0.0.0-20220730225603-2ab79fcdd4ef // indirect
 	github.com/stretchr/testify v1.11.1 // indirect
 	github.com/yuin/goldmark v1.7.8 // indirect
-	golang.org/x/image v0.41.0 // indirect
 	golang.org/x/net v0.55.0 // indirect
 	golang.org/x/sys v0.45.0 // indirect
 	golang.org/x/text v0.37.0 // indirect
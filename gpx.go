@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// A corner of the simplified track may be that many metres off the walked
+// line, about what a phone or a handheld GPS gets right
+const defaultGPXTolerance = 3.0
+
+// GPX 1.0 and 1.1, only the tracks and the routes, the names match whatever
+// the namespace
+type gpxFile struct {
+	Tracks []gpxTrack `xml:"trk"`
+	Routes []gpxRoute `xml:"rte"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxRoute struct {
+	Name   string     `xml:"name"`
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxPoint struct {
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+// A walk around a field as it was logged, every point of it
+type GPXTrack struct {
+	Name   string
+	Points []point
+}
+
+// The tracks and routes of a GPX file, the segments of a track one after
+// the other
+func readGPX(data []byte) ([]GPXTrack, error) {
+	var f gpxFile
+	if err := xml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("not a GPX file: %v", err)
+	}
+
+	var tracks []GPXTrack
+	add := func(name string, pts []gpxPoint) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = fmt.Sprintf("track %d", len(tracks)+1)
+		}
+		t := GPXTrack{Name: name}
+		for _, p := range pts {
+			t.Points = append(t.Points, point{p.Lon, p.Lat})
+		}
+		tracks = append(tracks, t)
+	}
+	for _, trk := range f.Tracks {
+		var pts []gpxPoint
+		for _, s := range trk.Segments {
+			pts = append(pts, s.Points...)
+		}
+		add(trk.Name, pts)
+	}
+	for _, rte := range f.Routes {
+		add(rte.Name, rte.Points)
+	}
+	if len(tracks) == 0 {
+		return nil, errors.New("there are no tracks in the GPX file")
+	}
+
+	return tracks, nil
+}
+
+// The fields walked in a GPX file, each track closed and simplified so no
+// corner is more than tolerance metres off it
+func parseGPX(data []byte, tolerance float64) ([]ImportedShape, error) {
+	tracks, err := readGPX(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return trackShapes(tracks, tolerance), nil
+}
+
+func trackShapes(tracks []GPXTrack, tolerance float64) []ImportedShape {
+	shapes := make([]ImportedShape, 0, len(tracks))
+	for _, t := range tracks {
+		s := ImportedShape{Name: t.Name}
+		s.Coords, s.Err = trackShape(t.Points, tolerance)
+		shapes = append(shapes, s)
+	}
+
+	return shapes
+}
+
+// The field around the track. The walk ends where it comes closest to its
+// start, past it is the walker going on to the gate, then it is closed
+// back to the start and its corners cut down with Douglas-Peucker.
+func trackShape(track []point, tolerance float64) ([]Coordinates, error) {
+	var pts []point
+	for _, p := range track {
+		if len(pts) == 0 || p != pts[len(pts)-1] {
+			pts = append(pts, p)
+		}
+	}
+	if len(pts) < 3 {
+		return nil, errors.New("the track is too short for a field, it needs at least 3 points")
+	}
+
+	local := localMetres([]polygon{{outer: pts}}, pts[0])[0].outer
+	end := len(local) - 1
+	for i := len(local) / 2; i < len(local); i++ {
+		if distance(local[i], local[0]) < distance(local[end], local[0]) {
+			end = i
+		}
+	}
+	pts, local = pts[:end+1], local[:end+1]
+	if distance(local[end], local[0]) <= tolerance {
+		pts, local = pts[:end], local[:end]
+	}
+
+	var r ring
+	for _, i := range simplifyRing(local, tolerance) {
+		r = append(r, Coordinates{Latitude: pts[i].y, Longitude: pts[i].x})
+	}
+	if len(r) < 3 {
+		return nil, fmt.Errorf("the track is narrower than %.1f m, there is no field around it", tolerance)
+	}
+
+	coords := numberShape([][]ring{{r}})
+	return coords, validateShape(coords)
+}
+
+func distance(a, b point) float64 {
+	return math.Hypot(b.x-a.x, b.y-a.y)
+}
+
+// How far p is from the segment a-b
+func segmentDistance(p, a, b point) float64 {
+	l := distance(a, b)
+	if l == 0 {
+		return distance(p, a)
+	}
+	t := ((p.x-a.x)*(b.x-a.x) + (p.y-a.y)*(b.y-a.y)) / (l * l)
+	t = max(min(t, 1), 0)
+
+	return distance(p, point{a.x + t*(b.x-a.x), a.y + t*(b.y-a.y)})
+}
+
+// Douglas-Peucker, marks the points of the line that stay, the two ends
+// always do
+func douglasPeucker(pts []point, from, to int, tolerance float64, keep []bool) {
+	keep[from], keep[to] = true, true
+	farthest, far := -1, tolerance
+	for i := from + 1; i < to; i++ {
+		if d := segmentDistance(pts[i], pts[from], pts[to]); d > far {
+			farthest, far = i, d
+		}
+	}
+	if farthest < 0 {
+		return
+	}
+	douglasPeucker(pts, from, farthest, tolerance, keep)
+	douglasPeucker(pts, farthest, to, tolerance, keep)
+}
+
+// The indexes of the corners of the closed ring that stay. The ring is cut
+// at the start and at the point farthest from it, Douglas-Peucker needs two
+// ends that are apart.
+func simplifyRing(pts []point, tolerance float64) []int {
+	if len(pts) < 4 {
+		indexes := make([]int, len(pts))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes
+	}
+
+	opposite := 0
+	for i := range pts {
+		if distance(pts[i], pts[0]) > distance(pts[opposite], pts[0]) {
+			opposite = i
+		}
+	}
+
+	// back to the start at the end
+	closed := append(append([]point{}, pts...), pts[0])
+	keep := make([]bool, len(closed))
+	douglasPeucker(closed, 0, opposite, tolerance, keep)
+	douglasPeucker(closed, opposite, len(closed)-1, tolerance, keep)
+
+	var indexes []int
+	for i, k := range keep[:len(pts)] {
+		if k {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
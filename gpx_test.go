@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// A walk around a 100 by 50 metre field near Larisa, a point every metre a
+// little off the edges, going on 5 metres past the start
+func walkedField() []point {
+	perLat, perLon := metresPerDegree(39.6)
+	corners := []point{{0, 0}, {100, 0}, {100, 50}, {0, 50}, {0, 0}, {5, 0}}
+
+	var walk []point
+	n := 0
+	for i := 0; i+1 < len(corners); i++ {
+		a, b := corners[i], corners[i+1]
+		steps := int(distance(a, b))
+		for s := range steps {
+			t := float64(s) / float64(steps)
+			// the GPS wanders up to a metre
+			jitter := math.Sin(float64(n)*1.7) * 0.8
+			n++
+			walk = append(walk, point{
+				22.4 + (a.x+t*(b.x-a.x)+jitter)/perLon,
+				39.6 + (a.y+t*(b.y-a.y)-jitter)/perLat,
+			})
+		}
+	}
+
+	return walk
+}
+
+func gpxOf(tracks ...[]point) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">`)
+	for i, t := range tracks {
+		fmt.Fprintf(&b, "<trk><name>Χωράφι %d</name><trkseg>", i+1)
+		for j, p := range t {
+			// a second segment after a pause
+			if j == len(t)/2 {
+				b.WriteString("</trkseg><trkseg>")
+			}
+			fmt.Fprintf(&b, `<trkpt lat="%.8f" lon="%.8f"><ele>80</ele></trkpt>`, p.y, p.x)
+		}
+		b.WriteString("</trkseg></trk>")
+	}
+	b.WriteString(`<rte><rtept lat="39.6" lon="22.4"/><rtept lat="39.601" lon="22.4"/></rte></gpx>`)
+
+	return b.String()
+}
+
+func TestReadGPX(t *testing.T) {
+	t.Parallel()
+
+	walk := walkedField()
+	tracks, err := readGPX([]byte(gpxOf(walk)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 2 || tracks[0].Name != "Χωράφι 1" || tracks[1].Name != "track 2" {
+		t.Fatalf("tracks = %d, names %q", len(tracks), []string{tracks[0].Name})
+	}
+	if len(tracks[0].Points) != len(walk) || len(tracks[1].Points) != 2 {
+		t.Errorf("points = %d and %d, want %d and 2", len(tracks[0].Points), len(tracks[1].Points), len(walk))
+	}
+
+	if _, err := readGPX([]byte(`<gpx version="1.1"></gpx>`)); err == nil {
+		t.Error("expected an error for a file without tracks")
+	}
+	if _, err := readGPX([]byte(`{"type": "Feature"}`)); err == nil {
+		t.Error("expected an error for a file that is not GPX")
+	}
+}
+
+func TestTrackShape(t *testing.T) {
+	t.Parallel()
+
+	coords, err := trackShape(walkedField(), defaultGPXTolerance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coords) != 4 {
+		t.Errorf("corners = %d, want the 4 of the field: %+v", len(coords), coords)
+	}
+	if size := drawnSize(coords); math.Abs(size-5) > 0.15 {
+		t.Errorf("size = %.3f στρ., want about 5", size)
+	}
+
+	// a finer tolerance keeps more of the wandering
+	fine, err := trackShape(walkedField(), 0.2)
+	if len(fine) <= len(coords) {
+		t.Errorf("corners at 0.2 m = %d, want more than %d (%v)", len(fine), len(coords), err)
+	}
+
+	// there and back along a line
+	line := []point{{22.4, 39.6}, {22.401, 39.6}, {22.402, 39.6}, {22.401, 39.6}}
+	if _, err := trackShape(line, defaultGPXTolerance); err == nil {
+		t.Error("expected an error for a track without a field around it")
+	}
+	if _, err := trackShape(line[:2], defaultGPXTolerance); err == nil {
+		t.Error("expected an error for a track of two points")
+	}
+}
+
+func TestSimplifyRing(t *testing.T) {
+	t.Parallel()
+
+	// a square with points along its sides
+	var ring []point
+	for i := range 10 {
+		ring = append(ring, point{float64(i), 0})
+	}
+	for i := range 10 {
+		ring = append(ring, point{10, float64(i)})
+	}
+	for i := range 10 {
+		ring = append(ring, point{10 - float64(i), 10})
+	}
+	for i := range 10 {
+		ring = append(ring, point{0, 10 - float64(i)})
+	}
+
+	got := simplifyRing(ring, 0.5)
+	if fmt.Sprint(got) != "[0 10 20 30]" {
+		t.Errorf("corners = %v, want [0 10 20 30]", got)
+	}
+}
+
+func TestParseMapFile_GPX(t *testing.T) {
+	t.Parallel()
+
+	shapes, err := parseMapFile("Περπάτημα.GPX", []byte(gpxOf(walkedField())), defaultGPXTolerance)
+	if err != nil || len(shapes) != 2 {
+		t.Fatalf("shapes = %v, %v", shapes, err)
+	}
+	if shapes[0].Err != nil || shapes[0].Name != "Χωράφι 1" || len(shapes[0].Coords) != 4 {
+		t.Errorf("the walked field = %+v", shapes[0])
+	}
+	// the route is only two points
+	if shapes[1].Err == nil {
+		t.Error("expected an error for the route")
+	}
+	coords, err := mergeShapes(shapes)
+	if err != nil || len(coords) != 4 {
+		t.Errorf("merged = %v, %v", coords, err)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+// Numbers written out in greek for receipts and contracts. Euro and λεπτά
+// are neuter but thousands (χιλιάδες) are feminine, so the few numerals that
+// change with gender have a feminine variant.
+
+var greekUnits = []string{"", "ένα", "δύο", "τρία", "τέσσερα", "πέντε", "έξι", "επτά", "οκτώ", "εννέα",
+	"δέκα", "έντεκα", "δώδεκα", "δεκατρία", "δεκατέσσερα", "δεκαπέντε", "δεκαέξι", "δεκαεπτά", "δεκαοκτώ", "δεκαεννέα"}
+
+var greekUnitsFeminine = map[int]string{1: "μία", 3: "τρεις", 4: "τέσσερις", 13: "δεκατρείς", 14: "δεκατέσσερις"}
+
+var greekTens = []string{"", "", "είκοσι", "τριάντα", "σαράντα", "πενήντα", "εξήντα", "εβδομήντα", "ογδόντα", "ενενήντα"}
+
+var greekHundreds = []string{"", "εκατό", "διακόσια", "τριακόσια", "τετρακόσια", "πεντακόσια", "εξακόσια", "επτακόσια", "οκτακόσια", "εννιακόσια"}
+
+// 0 < n < 1000
+func greekBelowThousand(n int, feminine bool) string {
+	var words []string
+
+	h := n / 100
+	rest := n % 100
+	if h > 0 {
+		w := greekHundreds[h]
+		switch {
+		case h == 1 && rest > 0:
+			w = "εκατόν"
+		case h > 1 && feminine:
+			w = strings.TrimSuffix(w, "α") + "ες"
+		}
+		words = append(words, w)
+	}
+
+	if rest > 0 {
+		t := rest / 10
+		u := rest % 10
+		if rest < 20 {
+			t, u = 0, rest
+		}
+		if t > 0 {
+			words = append(words, greekTens[t])
+		}
+		if u > 0 {
+			w := greekUnits[u]
+			if f, ok := greekUnitsFeminine[u]; ok && feminine {
+				w = f
+			}
+			words = append(words, w)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Integer written out in greek (neuter)
+func greekNumberWords(n int64) string {
+	if n == 0 {
+		return "μηδέν"
+	}
+	if n < 0 {
+		return "μείον " + greekNumberWords(-n)
+	}
+
+	var words []string
+
+	if billions := n / 1_000_000_000; billions > 0 {
+		if billions == 1 {
+			words = append(words, "ένα δισεκατομμύριο")
+		} else {
+			words = append(words, greekNumberWords(billions)+" δισεκατομμύρια")
+		}
+		n %= 1_000_000_000
+	}
+
+	if millions := int(n / 1_000_000); millions > 0 {
+		if millions == 1 {
+			words = append(words, "ένα εκατομμύριο")
+		} else {
+			words = append(words, greekBelowThousand(millions, false)+" εκατομμύρια")
+		}
+		n %= 1_000_000
+	}
+
+	if thousands := int(n / 1000); thousands > 0 {
+		if thousands == 1 {
+			words = append(words, "χίλια")
+		} else {
+			words = append(words, greekBelowThousand(thousands, true)+" χιλιάδες")
+		}
+		n %= 1000
+	}
+
+	if n > 0 {
+		words = append(words, greekBelowThousand(int(n), false))
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Amount in euro written out in greek, ex. "εκατόν είκοσι ευρώ και πενήντα λεπτά"
+func greekAmountWords(amount float64) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	euros := cents / 100
+	cents %= 100
+
+	words := greekNumberWords(euros) + " ευρώ"
+	if cents > 0 {
+		if cents == 1 {
+			words += " και ένα λεπτό"
+		} else {
+			words += " και " + greekNumberWords(cents) + " λεπτά"
+		}
+	}
+	if amount < 0 {
+		words = "μείον " + words
+	}
+
+	return words
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestGreekNumberWords(t *testing.T) {
+	t.Parallel()
+
+	cases := map[int64]string{
+		0:       "μηδέν",
+		1:       "ένα",
+		13:      "δεκατρία",
+		21:      "είκοσι ένα",
+		100:     "εκατό",
+		101:     "εκατόν ένα",
+		314:     "τριακόσια δεκατέσσερα",
+		1000:    "χίλια",
+		1250:    "χίλια διακόσια πενήντα",
+		3000:    "τρεις χιλιάδες",
+		14000:   "δεκατέσσερις χιλιάδες",
+		21400:   "είκοσι μία χιλιάδες τετρακόσια",
+		200000:  "διακόσιες χιλιάδες",
+		1000000: "ένα εκατομμύριο",
+		2300004: "δύο εκατομμύρια τριακόσιες χιλιάδες τέσσερα",
+	}
+
+	for n, want := range cases {
+		if got := greekNumberWords(n); got != want {
+			t.Errorf("greekNumberWords(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestGreekAmountWords(t *testing.T) {
+	t.Parallel()
+
+	cases := map[float64]string{
+		1200.5: "χίλια διακόσια ευρώ και πενήντα λεπτά",
+		0.01:   "μηδέν ευρώ και ένα λεπτό",
+		350:    "τριακόσια πενήντα ευρώ",
+		99.99:  "ενενήντα εννέα ευρώ και ενενήντα εννέα λεπτά",
+	}
+
+	for amount, want := range cases {
+		if got := greekAmountWords(amount); got != want {
+			t.Errorf("greekAmountWords(%v) = %q, want %q", amount, got, want)
+		}
+	}
+}
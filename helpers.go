@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"image/color"
+	"io"
 	"log"
 	"math"
 	"net/http"
@@ -15,13 +17,28 @@ import (
 	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
+// Every date in the database and the forms is stored like that
+const dateLayout = "02-01-2006"
+
+// Parse a date the way it's stored in the database
+func parseDate(s string) (time.Time, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: %v", s, err)
+	}
+
+	return t, nil
+}
+
 // Parse a string to d amount of decimals to float
 func ParseFloatToXDecimals(n string, d int) (float64, error) {
 	if d < 0 || d > 15 {
@@ -52,14 +69,19 @@ func TruncateFloatTo2Decimals(f float64) float64 {
 }
 
 func openFile(e Entry, appState *AppState) error {
-	if len(e.emisth) == 0 {
+	return openBlob(appState, e.Name, e.emisth)
+}
+
+// Writes the data to a temporary file and opens it with the default app
+func openBlob(appState *AppState, name string, data []byte) error {
+	if len(data) == 0 {
 		dialog.ShowInformation("Empty file", "No file data!", appState.window)
 		return nil
 	}
 
 	var guessedExt string
 
-	mimeType := http.DetectContentType(e.emisth)
+	mimeType := http.DetectContentType(data)
 
 	extMap := map[string]string{
 		"image/jpeg":      ".jpg",
@@ -69,6 +91,8 @@ func openFile(e Entry, appState *AppState) error {
 
 	if ext, ok := extMap[mimeType]; ok {
 		guessedExt = ext
+	} else if ext := officeDocumentExt(data); mimeType == "application/zip" && ext != "" {
+		guessedExt = ext
 	} else {
 		dialog.ShowInformation("Unsupported file", "file type not supported", appState.window)
 		return nil
@@ -77,8 +101,8 @@ func openFile(e Entry, appState *AppState) error {
 	if fyne.CurrentDevice().IsMobile() {
 		storage := fyne.CurrentApp().Storage()
 
-		name := "temp-open-" + e.Name + guessedExt
-		writerCloser, err := storage.Create(name)
+		tmpName := "temp-open-" + name + guessedExt
+		writerCloser, err := storage.Create(tmpName)
 		if err != nil {
 			return err
 		}
@@ -88,7 +112,7 @@ func openFile(e Entry, appState *AppState) error {
 			}
 		}()
 
-		_, err = writerCloser.Write(e.emisth)
+		_, err = writerCloser.Write(data)
 		if err != nil {
 			return err
 		}
@@ -100,20 +124,20 @@ func openFile(e Entry, appState *AppState) error {
 		}
 
 		time.AfterFunc(60*time.Second, func() {
-			_ = storage.Remove(name)
+			_ = storage.Remove(tmpName)
 		})
 
 		err = fyne.CurrentApp().OpenURL(u)
 		if err != nil {
 			dialog.ShowInformation("Failed to show the file", "File created but failed to open.", appState.window)
-			_ = storage.Remove(name)
+			_ = storage.Remove(tmpName)
 			return err
 		}
 
 		return nil
 	}
 
-	base := strings.TrimSuffix(filepath.Base(e.Name), guessedExt)
+	base := strings.TrimSuffix(filepath.Base(name), guessedExt)
 	if base == "" {
 		base = "blobfile"
 	}
@@ -123,7 +147,7 @@ func openFile(e Entry, appState *AppState) error {
 		return fmt.Errorf("failed to create temp file: %v", err)
 	}
 
-	_, err = tmpFile.Write(e.emisth)
+	_, err = tmpFile.Write(data)
 	if err != nil {
 		return fmt.Errorf("failed to write temp file: %v", err)
 	}
@@ -211,6 +235,760 @@ func buildList(appState *AppState, data []any) *widget.List {
 	return list
 }
 
+// Small coloured label with the status of a contract
+func newStatusBadge() *fyne.Container {
+	bg := canvas.NewRectangle(color.Transparent)
+	bg.CornerRadius = 6
+	text := canvas.NewText("", color.White)
+	text.TextSize = 12
+	text.TextStyle.Bold = true
+	text.Alignment = fyne.TextAlignCenter
+
+	return container.NewStack(bg, container.NewPadded(text))
+}
+
+func setStatusBadge(badge *fyne.Container, status string) {
+	bg := badge.Objects[0].(*canvas.Rectangle)
+	text := badge.Objects[1].(*fyne.Container).Objects[0].(*canvas.Text)
+	bg.FillColor = statusColors[status]
+	text.Text = statusLabels[status]
+	badge.Refresh()
+}
+
+// Check boxes for the statuses shared by the contract list and the reports,
+// onChanged runs after appState.statuses is updated
+func newStatusFilter(appState *AppState, onChanged func()) *widget.CheckGroup {
+	var opts []string
+	for _, s := range contractStatuses {
+		opts = append(opts, statusLabels[s])
+	}
+
+	group := widget.NewCheckGroup(opts, nil)
+	group.Horizontal = true
+	if appState.statuses == nil {
+		group.Selected = opts
+	} else {
+		for _, s := range appState.statuses {
+			group.Selected = append(group.Selected, statusLabels[s])
+		}
+	}
+
+	group.OnChanged = func(selected []string) {
+		if len(selected) == len(opts) {
+			appState.statuses = nil
+		} else {
+			// an empty slice, not nil, so nothing is shown
+			appState.statuses = []string{}
+			for _, s := range contractStatuses {
+				for _, l := range selected {
+					if statusLabels[s] == l {
+						appState.statuses = append(appState.statuses, s)
+					}
+				}
+			}
+		}
+		log.Printf("Status filter changed to: %v", appState.statuses)
+		if onChanged != nil {
+			onChanged()
+		}
+	}
+
+	return group
+}
+
+// Choice of the contract type with the share of the crop that only a
+// sharecropping has. The rent is set to 0 and locked for the types without
+// rent. The getters return the chosen type and the share.
+func newContractTypeSelect(contractType string, share float64, rentInput *widget.Entry) (*widget.Select, *widget.Entry, func() string, func() (float64, error)) {
+	if contractType == "" {
+		contractType = contractLease
+	}
+
+	var opts []string
+	for _, t := range contractTypes {
+		opts = append(opts, contractTypeLabels[t])
+	}
+
+	shareInput := NewFilteredEntry(`[^0-9.]`, "Ποσοστό σοδειάς %")
+	if share != 0 {
+		shareInput.SetText(strconv.FormatFloat(share, 'f', -1, 64))
+	}
+
+	typeSelect := widget.NewSelect(opts, nil)
+	typeSelect.OnChanged = func(string) {
+		i := typeSelect.SelectedIndex()
+		if i < 0 {
+			return
+		}
+		rules := contractTypeRules[contractTypes[i]]
+		if rules.Share {
+			shareInput.Show()
+		} else {
+			shareInput.SetText("")
+			shareInput.Hide()
+		}
+		if rules.Rent {
+			rentInput.Enable()
+		} else {
+			rentInput.SetText("0")
+			rentInput.Disable()
+		}
+	}
+	for i, t := range contractTypes {
+		if t == contractType {
+			typeSelect.SetSelectedIndex(i)
+		}
+	}
+
+	getType := func() string {
+		i := typeSelect.SelectedIndex()
+		if i < 0 {
+			return contractLease
+		}
+		return contractTypes[i]
+	}
+	getShare := func() (float64, error) {
+		if !shareInput.Visible() {
+			return 0, nil
+		}
+		return ParseFloatToXDecimals(shareInput.Text, 2)
+	}
+
+	return typeSelect, shareInput, getType, getShare
+}
+
+// Inputs of the notice clause: months of notice, months it renews itself for
+// (empty for none) and who can give notice. The getter returns them in that order.
+func newNoticeInputs(noticeMonths, autoRenewMonths int, party string) (fyne.CanvasObject, func() (int, int, string, error)) {
+	noticeInput := NewFilteredEntry(`[^0-9]`, "Μήνες προειδοποίησης")
+	if noticeMonths != 0 {
+		noticeInput.SetText(strconv.Itoa(noticeMonths))
+	}
+	renewInput := NewFilteredEntry(`[^0-9]`, "Σιωπηρή παράταση (μήνες)")
+	if autoRenewMonths != 0 {
+		renewInput.SetText(strconv.Itoa(autoRenewMonths))
+	}
+
+	var opts []string
+	for _, p := range noticeParties {
+		opts = append(opts, noticePartyLabels[p])
+	}
+	partySelect := widget.NewSelect(opts, nil)
+	partySelect.PlaceHolder = "Καταγγελία από"
+	for i, p := range noticeParties {
+		if p == party {
+			partySelect.SetSelectedIndex(i)
+		}
+	}
+	if partySelect.SelectedIndex() < 0 {
+		partySelect.SetSelectedIndex(0)
+	}
+
+	get := func() (int, int, string, error) {
+		atoi := func(s string) (int, error) {
+			if s == "" {
+				return 0, nil
+			}
+			return strconv.Atoi(s)
+		}
+		notice, err := atoi(noticeInput.Text)
+		if err != nil {
+			return 0, 0, "", fmt.Errorf("invalid notice period: %v", err)
+		}
+		renew, err := atoi(renewInput.Text)
+		if err != nil {
+			return 0, 0, "", fmt.Errorf("invalid renewal term: %v", err)
+		}
+
+		return notice, renew, noticeParties[max(partySelect.SelectedIndex(), 0)], nil
+	}
+
+	return container.NewGridWithColumns(3, noticeInput, renewInput, partySelect), get
+}
+
+// Button that picks the parcels a contract leases out of the saved ones. When
+// none is picked the contract, new or edited, gets one from its own KAEK, ATAK
+// and size. The getter returns the picked parcels.
+func newParcelsPicker(appState *AppState, selected []Parcel) (*widget.Button, func() []Parcel) {
+	picked := append([]Parcel(nil), selected...)
+
+	button := widget.NewButtonWithIcon("", theme.ListIcon(), nil)
+	setText := func() {
+		if len(picked) == 0 {
+			button.SetText("Αγροτεμάχια: από το ΚΑΕΚ")
+			return
+		}
+		var labels []string
+		for _, p := range picked {
+			labels = append(labels, parcelLabel(p))
+		}
+		button.SetText("Αγροτεμάχια: " + strings.Join(labels, ", "))
+	}
+	setText()
+
+	button.OnTapped = func() {
+		parcels, err := getParcels(appState.db)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+
+		var opts, checked []string
+		byLabel := make(map[string]Parcel, len(parcels))
+		for _, p := range parcels {
+			label := fmt.Sprintf("%s  %.3f στρ. #%d", parcelLabel(p), p.Area, p.ID)
+			opts = append(opts, label)
+			byLabel[label] = p
+			for _, s := range picked {
+				if s.ID == p.ID {
+					checked = append(checked, label)
+				}
+			}
+		}
+		group := widget.NewCheckGroup(opts, nil)
+		group.SetSelected(checked)
+
+		dlg := dialog.NewCustomConfirm("Αγροτεμάχια", "OK", "Cancel", container.NewVScroll(group), func(ok bool) {
+			if !ok {
+				return
+			}
+			picked = nil
+			for _, label := range group.Selected {
+				picked = append(picked, byLabel[label])
+			}
+			setText()
+		}, appState.window)
+		dlg.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.66))
+		dlg.Show()
+	}
+
+	return button, func() []Parcel {
+		return picked
+	}
+}
+
+// Button that opens the editor of the corners of a field. A field has one or
+// more separate parts, each with an outer ring and maybe holes, and the
+// corners of every ring can be added, removed and moved up or down. The
+// getter returns the corners as they were last accepted with OK.
+func newShapeEditor(appState *AppState, coords []Coordinates) (*widget.Button, func() []Coordinates) {
+	accepted := numberShape(shapeRings(coords))
+
+	button := widget.NewButtonWithIcon("", theme.ContentAddIcon(), nil)
+	setText := func() {
+		parts := shapeRings(accepted)
+		button.SetText(fmt.Sprintf("Συντεταγμένες: %d κορυφές, %d τμήματα", len(accepted), len(parts)))
+	}
+	setText()
+
+	button.OnTapped = func() {
+		type corner struct{ lat, lon string }
+
+		// the corners are typed in WGS84 degrees or in ΕΓΣΑ87 metres, the
+		// choice is remembered
+		grid := appState.app.Preferences().Bool("coords_egsa87")
+		format := func(c Coordinates, grid bool) corner {
+			if grid {
+				x, y := formatGridCorner(c)
+				return corner{x, y}
+			}
+			return corner{
+				strconv.FormatFloat(c.Latitude, 'f', -1, 64),
+				strconv.FormatFloat(c.Longitude, 'f', -1, 64),
+			}
+		}
+		parse := func(c corner, grid bool) (Coordinates, error) {
+			if grid {
+				return parseGridCorner(c.lat, c.lon)
+			}
+			return parseCorner(c.lat, c.lon)
+		}
+
+		// the text of the corners of every ring of every part
+		var parts [][][]corner
+		load := func(coords []Coordinates) {
+			parts = nil
+			for _, rings := range shapeRings(coords) {
+				var part [][]corner
+				for _, r := range rings {
+					var corners []corner
+					for _, c := range r {
+						corners = append(corners, format(c, grid))
+					}
+					part = append(part, corners)
+				}
+				parts = append(parts, part)
+			}
+			if len(parts) == 0 {
+				parts = [][][]corner{{{}}}
+			}
+		}
+		load(accepted)
+		curPart, curRing := 0, 0
+
+		ringSelect := widget.NewSelect(nil, nil)
+		cornersBox := container.NewVBox()
+
+		var render func()
+		options := func() {
+			var opts []string
+			selected := 0
+			for p, rings := range parts {
+				for r := range rings {
+					if p == curPart && r == curRing {
+						selected = len(opts)
+					}
+					if r == 0 {
+						opts = append(opts, fmt.Sprintf("Τμήμα %d", p+1))
+					} else {
+						opts = append(opts, fmt.Sprintf("Τμήμα %d - Οπή %d", p+1, r))
+					}
+				}
+			}
+			ringSelect.OnChanged = nil
+			ringSelect.Options = opts
+			ringSelect.SetSelectedIndex(selected)
+			ringSelect.OnChanged = func(string) {
+				i := ringSelect.SelectedIndex()
+				for p, rings := range parts {
+					if i < len(rings) {
+						curPart, curRing = p, i
+						break
+					}
+					i -= len(rings)
+				}
+				render()
+			}
+		}
+		render = func() {
+			cornersBox.RemoveAll()
+			corners := parts[curPart][curRing]
+			for i := range corners {
+				lat := widget.NewEntry()
+				lat.SetPlaceHolder("Πλάτος")
+				lat.SetText(corners[i].lat)
+				lon := widget.NewEntry()
+				lon.SetPlaceHolder("Μήκος")
+				lon.SetText(corners[i].lon)
+				if grid {
+					lat.SetPlaceHolder("X")
+					lon.SetPlaceHolder("Y")
+				}
+
+				lat.Validator = func(s string) error {
+					switch {
+					case strings.TrimSpace(s) == "":
+						return nil
+					case grid:
+						_, err := parseMetres("X", s)
+						return err
+					case isLatLonPair(s):
+						return nil
+					}
+					_, err := parseLatitude(s)
+					return err
+				}
+				lon.Validator = func(s string) error {
+					switch {
+					case strings.TrimSpace(s) == "":
+						return nil
+					case grid:
+						_, err := parseMetres("Y", s)
+						return err
+					}
+					_, err := parseLongitude(s)
+					return err
+				}
+				lat.OnChanged = func(s string) {
+					parts[curPart][curRing][i].lat = s
+					// a pair pasted from a map app fills both
+					if !grid && isLatLonPair(s) {
+						if c, err := parseLatLon(s); err == nil {
+							f := format(c, false)
+							lat.SetText(f.lat)
+							lon.SetText(f.lon)
+						}
+					}
+				}
+				lon.OnChanged = func(s string) { parts[curPart][curRing][i].lon = s }
+
+				up := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+					if i > 0 {
+						corners[i-1], corners[i] = corners[i], corners[i-1]
+						render()
+					}
+				})
+				down := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+					if i < len(corners)-1 {
+						corners[i+1], corners[i] = corners[i], corners[i+1]
+						render()
+					}
+				})
+				remove := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), func() {
+					parts[curPart][curRing] = append(corners[:i:i], corners[i+1:]...)
+					render()
+				})
+
+				cornersBox.Add(container.NewBorder(nil, nil,
+					widget.NewLabel(fmt.Sprintf("%d.", i+1)),
+					container.NewHBox(up, down, remove),
+					container.NewGridWithColumns(2, lat, lon)))
+			}
+			cornersBox.Refresh()
+		}
+
+		addCorner := widget.NewButtonWithIcon("Κορυφή", theme.ContentAddIcon(), func() {
+			parts[curPart][curRing] = append(parts[curPart][curRing], corner{})
+			render()
+		})
+		addPart := widget.NewButtonWithIcon("Τμήμα", theme.ContentAddIcon(), func() {
+			parts = append(parts, [][]corner{{}})
+			curPart, curRing = len(parts)-1, 0
+			options()
+			render()
+		})
+		addHole := widget.NewButtonWithIcon("Οπή", theme.ContentAddIcon(), func() {
+			parts[curPart] = append(parts[curPart], []corner{})
+			curRing = len(parts[curPart]) - 1
+			options()
+			render()
+		})
+		// removing the outer ring removes the whole part
+		removeRing := widget.NewButtonWithIcon("Διαγραφή", theme.DeleteIcon(), func() {
+			if curRing == 0 {
+				parts = append(parts[:curPart:curPart], parts[curPart+1:]...)
+				if len(parts) == 0 {
+					parts = [][][]corner{{{}}}
+				}
+				curPart = max(curPart-1, 0)
+			} else {
+				parts[curPart] = append(parts[curPart][:curRing:curRing], parts[curPart][curRing+1:]...)
+			}
+			curRing = 0
+			options()
+			render()
+		})
+
+		// a field drawn in Google Earth or a GIS tool replaces the corners
+		fileButton := widget.NewButtonWithIcon("Αρχείο", theme.FolderOpenIcon(), func() {
+			dlg := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+				if err != nil || reader == nil {
+					return
+				}
+				defer func() {
+					if err := reader.Close(); err != nil {
+						log.Println("reader.Close() error: ", err)
+					}
+				}()
+
+				data, err := io.ReadAll(reader)
+				if err != nil {
+					dialog.ShowError(err, appState.window)
+					return
+				}
+				loadFile := func(coords []Coordinates) {
+					load(coords)
+					curPart, curRing = 0, 0
+					options()
+					render()
+				}
+				// a walked track is simplified as much as the user likes
+				if strings.EqualFold(filepath.Ext(reader.URI().Name()), ".gpx") {
+					showGPXPreview(appState, data, loadFile)
+					return
+				}
+				shapes, err := parseMapFile(reader.URI().Name(), data, gpxTolerance(appState))
+				if err != nil {
+					dialog.ShowError(err, appState.window)
+					return
+				}
+				coords, err := mergeShapes(shapes)
+				if err != nil {
+					dialog.ShowError(err, appState.window)
+					return
+				}
+				loadFile(coords)
+			}, appState.window)
+
+			dlg.SetFilter(storage.NewExtensionFileFilter(mapFileExtensions))
+			dlg.Show()
+		})
+
+		// switching converts the corners that are filled in, the ones that
+		// don't parse are left as they are
+		systemSelect := widget.NewSelect([]string{"WGS84", "ΕΓΣΑ87"}, nil)
+		if grid {
+			systemSelect.SetSelectedIndex(1)
+		} else {
+			systemSelect.SetSelectedIndex(0)
+		}
+		systemSelect.OnChanged = func(string) {
+			toGrid := systemSelect.SelectedIndex() == 1
+			if toGrid == grid {
+				return
+			}
+			for _, rings := range parts {
+				for _, corners := range rings {
+					for i, c := range corners {
+						if coord, err := parse(c, grid); err == nil {
+							corners[i] = format(coord, toGrid)
+						}
+					}
+				}
+			}
+			grid = toGrid
+			appState.app.Preferences().SetBool("coords_egsa87", grid)
+			render()
+		}
+
+		okButton := widget.NewButton("OK", nil)
+		cancelButton := widget.NewButton("Cancel", nil)
+
+		content := container.NewBorder(
+			container.NewBorder(nil, nil, systemSelect, container.NewHBox(addPart, addHole, removeRing, fileButton), ringSelect),
+			container.NewVBox(addCorner, container.NewGridWithColumns(2, cancelButton, okButton)),
+			nil,
+			nil,
+			container.NewVScroll(cornersBox),
+		)
+		popup := widget.NewModalPopUp(content, appState.window.Canvas())
+
+		okButton.OnTapped = func() {
+			var shape [][]ring
+			for p, rings := range parts {
+				var part []ring
+				for r, corners := range rings {
+					var rr ring
+					for i, c := range corners {
+						// rows left empty are dropped, not saved as 0,0
+						if strings.TrimSpace(c.lat) == "" && strings.TrimSpace(c.lon) == "" {
+							continue
+						}
+						coord, err := parse(c, grid)
+						if err != nil {
+							dialog.ShowError(fmt.Errorf("%s corner %d: %v", ringName(p, r), i+1, err), appState.window)
+							return
+						}
+						rr = append(rr, coord)
+					}
+					part = append(part, rr)
+				}
+				shape = append(shape, part)
+			}
+
+			coords := numberShape(shape)
+			if err := validateShape(coords); err != nil {
+				dialog.ShowError(err, appState.window)
+				return
+			}
+			accepted = coords
+			setText()
+			popup.Hide()
+		}
+		cancelButton.OnTapped = func() {
+			popup.Hide()
+		}
+
+		options()
+		render()
+		popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.8, appState.window.Canvas().Size().Height*0.8))
+		popup.Show()
+	}
+
+	return button, func() []Coordinates {
+		return accepted
+	}
+}
+
+// Choice of the contract a contract subleases from, the first option is none.
+// The getter returns the id of the chosen contract or 0.
+func newParentSelect(appState *AppState, selfID, parentID uint) (*widget.Select, func() uint) {
+	entries, err := getAllEntries(appState.db)
+	if err != nil {
+		log.Printf("Error getting the contracts for the parent select: %v", err)
+	}
+
+	opts := []string{"Όχι υπομίσθωση"}
+	ids := []uint{0}
+	for _, e := range entries {
+		if e.ID == selfID {
+			continue
+		}
+		opts = append(opts, contractLabel(e))
+		ids = append(ids, e.ID)
+	}
+
+	parentSelect := widget.NewSelect(opts, nil)
+	parentSelect.SetSelectedIndex(0)
+	for i, id := range ids {
+		if id == parentID {
+			parentSelect.SetSelectedIndex(i)
+		}
+	}
+
+	return parentSelect, func() uint {
+		i := parentSelect.SelectedIndex()
+		if i < 0 {
+			return 0
+		}
+		return ids[i]
+	}
+}
+
+// The numbering scheme of new contracts from the preferences
+func numberingScheme(appState *AppState) NumberingScheme {
+	prefs := appState.app.Preferences()
+
+	return NumberingScheme{
+		Prefix: prefs.StringWithFallback("numbering_prefix", defaultNumberingScheme.Prefix),
+		Year:   prefs.BoolWithFallback("numbering_year", defaultNumberingScheme.Year),
+		Reset:  prefs.BoolWithFallback("numbering_reset", defaultNumberingScheme.Reset),
+		Digits: prefs.IntWithFallback("numbering_digits", defaultNumberingScheme.Digits),
+	}
+}
+
+// How many percent the declared size can be off the drawn area before the
+// contract shows up in the report
+func areaTolerance(appState *AppState) float64 {
+	return appState.app.Preferences().FloatWithFallback("area_tolerance", defaultAreaTolerance)
+}
+
+func gpxTolerance(appState *AppState) float64 {
+	return appState.app.Preferences().FloatWithFallback("gpx_tolerance", defaultGPXTolerance)
+}
+
+// The field of a GPX track with its corners and area for the tolerance the
+// user picks, onLoad gets it on OK
+func showGPXPreview(appState *AppState, data []byte, onLoad func([]Coordinates)) {
+	tracks, err := readGPX(data)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+	points := 0
+	for _, t := range tracks {
+		points += len(t.Points)
+	}
+
+	toleranceInput := NewFilteredEntry(`[^0-9.]`, "Ανοχή μ.")
+	infoLabel := widget.NewLabel("")
+	infoLabel.Wrapping = fyne.TextWrapWord
+
+	var coords []Coordinates
+	update := func() {
+		coords = nil
+		tolerance, err := strconv.ParseFloat(toleranceInput.Text, 64)
+		if err != nil || tolerance < 0 {
+			infoLabel.SetText(fmt.Sprintf("invalid tolerance: %s", toleranceInput.Text))
+			return
+		}
+
+		merged, err := mergeShapes(trackShapes(tracks, tolerance))
+		if merged == nil {
+			infoLabel.SetText(err.Error())
+			return
+		}
+		coords = merged
+
+		text := fmt.Sprintf("Σημεία: %d, κορυφές: %d\nΣτρέμματα: %.3f", points, len(coords), drawnSize(coords))
+		if err != nil {
+			// it loads, the corners can be fixed in the editor
+			text += "\n" + err.Error()
+		}
+		infoLabel.SetText(text)
+	}
+	// after the filter of the entry
+	filter := toleranceInput.OnChanged
+	toleranceInput.OnChanged = func(s string) {
+		filter(s)
+		update()
+	}
+	toleranceInput.SetText(strconv.FormatFloat(gpxTolerance(appState), 'f', -1, 64))
+
+	content := container.NewVBox(
+		widget.NewLabel("Ανοχή απλοποίησης (μέτρα):"),
+		toleranceInput,
+		infoLabel,
+	)
+	dlg := dialog.NewCustomConfirm("GPX", "OK", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if coords == nil {
+			dialog.ShowError(fmt.Errorf("%s", infoLabel.Text), appState.window)
+			return
+		}
+		if tolerance, err := strconv.ParseFloat(toleranceInput.Text, 64); err == nil {
+			appState.app.Preferences().SetFloat("gpx_tolerance", tolerance)
+		}
+		onLoad(coords)
+	}, appState.window)
+	dlg.Resize(fyne.NewSize(400, 300))
+	dlg.Show()
+}
+
+// Changes the numbering of the contracts that will be created, the ones that
+// have a number keep it
+func showNumberingForm(appState *AppState) {
+	scheme := numberingScheme(appState)
+
+	prefixInput := widget.NewEntry()
+	prefixInput.SetText(scheme.Prefix)
+	yearCheck := widget.NewCheck("Με το έτος", nil)
+	yearCheck.SetChecked(scheme.Year)
+	resetCheck := widget.NewCheck("Νέα αρίθμηση κάθε έτος", nil)
+	resetCheck.SetChecked(scheme.Reset)
+	digitsSelect := widget.NewSelect([]string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}, nil)
+	digitsSelect.SetSelected(strconv.Itoa(scheme.Digits))
+	example := widget.NewLabel("")
+
+	read := func() (NumberingScheme, error) {
+		digits, err := strconv.Atoi(digitsSelect.Selected)
+		if err != nil {
+			return NumberingScheme{}, fmt.Errorf("invalid digits: %s", digitsSelect.Selected)
+		}
+		s := NumberingScheme{Prefix: strings.TrimSpace(prefixInput.Text), Year: yearCheck.Checked, Reset: resetCheck.Checked, Digits: digits}
+		return s, s.Validate()
+	}
+	update := func() {
+		s, err := read()
+		if err != nil {
+			example.SetText(err.Error())
+			return
+		}
+		example.SetText("π.χ. " + s.Format(time.Now().Year(), 7))
+	}
+	prefixInput.OnChanged = func(string) { update() }
+	yearCheck.OnChanged = func(bool) { update() }
+	resetCheck.OnChanged = func(bool) { update() }
+	digitsSelect.OnChanged = func(string) { update() }
+	update()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Πρόθεμα", prefixInput),
+		widget.NewFormItem("", yearCheck),
+		widget.NewFormItem("", resetCheck),
+		widget.NewFormItem("Ψηφία", digitsSelect),
+		widget.NewFormItem("", example),
+	}
+	dialog.ShowForm("Αρίθμηση συμβολαίων", "OK", "Cancel", items, func(b bool) {
+		if !b {
+			return
+		}
+		s, err := read()
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		prefs := appState.app.Preferences()
+		prefs.SetString("numbering_prefix", s.Prefix)
+		prefs.SetBool("numbering_year", s.Year)
+		prefs.SetBool("numbering_reset", s.Reset)
+		prefs.SetInt("numbering_digits", s.Digits)
+		log.Printf("Numbering scheme changed to: %+v", s)
+	}, appState.window)
+}
+
 func newEntryWithLabel(ph string) *widget.Entry {
 	entry := widget.NewEntry()
 	entry.SetPlaceHolder(ph)
@@ -271,3 +1049,27 @@ func askForName(appState *AppState) {
 		dlg.Show()
 	}
 }
+
+// Asks where to save and writes the data there
+func saveFileDialog(appState *AppState, fileName string, data []byte) {
+	dlg := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		defer func() {
+			if err := writer.Close(); err != nil {
+				log.Println("writer.Close() error: ", err)
+			}
+		}()
+
+		_, err = writer.Write(data)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		log.Printf("Saved file: %s", writer.URI().Path())
+	}, appState.window)
+
+	dlg.SetFileName(fileName)
+	dlg.Show()
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KML 2.2 as Google Earth writes it, only as much of it as fields need.
+// The decoder matches the elements by their local name, so the same types
+// read files with or without the namespace.
+type kmlFile struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr,omitempty"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name       string         `xml:"name"`
+	Styles     []kmlStyle     `xml:"Style"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlStyle struct {
+	ID        string  `xml:"id,attr"`
+	LineColor string  `xml:"LineStyle>color"`
+	LineWidth float64 `xml:"LineStyle>width"`
+	PolyColor string  `xml:"PolyStyle>color"`
+}
+
+type kmlPlacemark struct {
+	Name        string             `xml:"name"`
+	Description string             `xml:"description,omitempty"`
+	StyleURL    string             `xml:"styleUrl,omitempty"`
+	Data        []kmlData          `xml:"ExtendedData>Data"`
+	SimpleData  []kmlSimpleData    `xml:"ExtendedData>SchemaData>SimpleData"`
+	Polygons    []kmlPolygon       `xml:"Polygon"`
+	Multi       []kmlMultiGeometry `xml:"MultiGeometry"`
+	Lines       []kmlLine          `xml:"LineString"`
+	Points      []kmlLine          `xml:"Point"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlSimpleData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type kmlMultiGeometry struct {
+	Polygons []kmlPolygon       `xml:"Polygon"`
+	Multi    []kmlMultiGeometry `xml:"MultiGeometry"`
+	Lines    []kmlLine          `xml:"LineString"`
+}
+
+type kmlPolygon struct {
+	Outer kmlBoundary   `xml:"outerBoundaryIs"`
+	Inner []kmlBoundary `xml:"innerBoundaryIs"`
+}
+
+type kmlBoundary struct {
+	Coordinates string `xml:"LinearRing>coordinates"`
+}
+
+type kmlLine struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// How the fields are coloured in the export
+const (
+	kmlColourByStatus = "status"
+	kmlColourByRenter = "renter"
+)
+
+var kmlColourByLabels = map[string]string{
+	kmlColourByStatus: "Κατάσταση",
+	kmlColourByRenter: "Μισθωτής",
+}
+
+// The renters take these colours in the order they first show up
+var renterPalette = []color.NRGBA{
+	{R: 0xe5, G: 0x39, B: 0x35, A: 0xff},
+	{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff},
+	{R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+	{R: 0xfb, G: 0x8c, B: 0x00, A: 0xff},
+	{R: 0x8e, G: 0x24, B: 0xaa, A: 0xff},
+	{R: 0x00, G: 0xac, B: 0xc1, A: 0xff},
+	{R: 0xfd, G: 0xd8, B: 0x35, A: 0xff},
+	{R: 0x6d, G: 0x4c, B: 0x41, A: 0xff},
+}
+
+// KML colours are aabbggrr in hex
+func kmlColour(c color.NRGBA, alpha uint8) string {
+	return fmt.Sprintf("%02x%02x%02x%02x", alpha, c.B, c.G, c.R)
+}
+
+func kmlCoordinates(positions [][2]float64) string {
+	tuples := make([]string, 0, len(positions))
+	for _, p := range positions {
+		tuples = append(tuples, strconv.FormatFloat(p[0], 'f', -1, 64)+","+strconv.FormatFloat(p[1], 'f', -1, 64)+",0")
+	}
+	return strings.Join(tuples, " ")
+}
+
+// The balloon Google Earth shows when the field is clicked
+func kmlDescription(e Entry, terms Entry) string {
+	rows := [][2]string{
+		{"Συμβόλαιο", contractLabel(e)},
+		{"ΚΑΕΚ", terms.KAEK},
+		{"Εκμισθωτές", strings.Join(ownerNames(terms.Owners), ", ")},
+		{"Μισθωτές", strings.Join(renterNames(terms.Renters), ", ")},
+		{"Καλλιέργεια", terms.Type},
+		{"Από", terms.Start},
+		{"Έως", terms.End},
+		{"Κατάσταση", statusLabels[e.Status]},
+		{"Στρέμματα", fmt.Sprintf("%.3f (σχέδιο: %.3f)", terms.Size, drawnSize(e.Coords))},
+		{"Μίσθωμα", fmt.Sprintf("%.2f€", terms.Rent)},
+	}
+
+	var b strings.Builder
+	b.WriteString("<table>")
+	for _, r := range rows {
+		fmt.Fprintf(&b, "<tr><th align=\"left\">%s</th><td>%s</td></tr>", r[0], html.EscapeString(r[1]))
+	}
+	b.WriteString("</table>")
+
+	return b.String()
+}
+
+// The contracts that have coordinates as placemarks with a balloon of their
+// terms on the day, coloured by status or by the first renter
+func contractsKML(entries []Entry, day time.Time, colourBy string) ([]byte, error) {
+	doc := kmlDocument{Name: "Συμβόλαια"}
+	styles := make(map[string]bool)
+	renterColours := make(map[string]int)
+
+	style := func(id string, c color.NRGBA) {
+		if !styles[id] {
+			styles[id] = true
+			doc.Styles = append(doc.Styles, kmlStyle{ID: id, LineColor: kmlColour(c, 0xff), LineWidth: 2, PolyColor: kmlColour(c, 0x66)})
+		}
+	}
+
+	for _, e := range entries {
+		parts := exportRings(e.Coords)
+		if len(parts) == 0 {
+			continue
+		}
+		terms := e.AsOf(day)
+
+		var styleID string
+		switch colourBy {
+		case kmlColourByRenter:
+			renter := strings.Join(renterNames(terms.Renters), ", ")
+			i, ok := renterColours[renter]
+			if !ok {
+				i = len(renterColours)
+				renterColours[renter] = i
+			}
+			styleID = fmt.Sprintf("renter-%d", i)
+			style(styleID, renterPalette[i%len(renterPalette)])
+		default:
+			styleID = "status-" + e.Status
+			style(styleID, statusColors[e.Status])
+		}
+
+		var multi kmlMultiGeometry
+		for _, rings := range parts {
+			poly := kmlPolygon{Outer: kmlBoundary{kmlCoordinates(rings[0])}}
+			for _, hole := range rings[1:] {
+				poly.Inner = append(poly.Inner, kmlBoundary{kmlCoordinates(hole)})
+			}
+			multi.Polygons = append(multi.Polygons, poly)
+		}
+
+		doc.Placemarks = append(doc.Placemarks, kmlPlacemark{
+			Name:        contractLabel(e),
+			Description: kmlDescription(e, terms),
+			StyleURL:    "#" + styleID,
+			Data: []kmlData{
+				{Name: "id", Value: strconv.FormatUint(uint64(e.ID), 10)},
+				{Name: "kaek", Value: terms.KAEK},
+				{Name: "contract", Value: e.Name},
+			},
+			Multi: []kmlMultiGeometry{multi},
+		})
+	}
+
+	data, err := xml.MarshalIndent(kmlFile{Xmlns: "http://www.opengis.net/kml/2.2", Document: doc}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// The KML zipped as doc.kml, the way Google Earth saves a KMZ
+func contractsKMZ(entries []Entry, day time.Time, colourBy string) ([]byte, error) {
+	data, err := contractsKML(entries, day, colourBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("doc.kml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// The fields of every placemark in the file, in whatever folders they are.
+// A path drawn around the field counts as its outer ring, the placemarks
+// without a polygon or a path come back with an error.
+func parseKML(data []byte) ([]ImportedShape, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+
+	var shapes []ImportedShape
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("not a KML file: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+
+		var pm kmlPlacemark
+		if err := dec.DecodeElement(&pm, &start); err != nil {
+			return nil, fmt.Errorf("not a KML file: %v", err)
+		}
+		s := ImportedShape{Name: strings.TrimSpace(pm.Name)}
+		for _, d := range pm.Data {
+			if strings.EqualFold(d.Name, "kaek") {
+				s.KAEK = strings.TrimSpace(d.Value)
+			}
+		}
+		for _, d := range pm.SimpleData {
+			if strings.EqualFold(d.Name, "kaek") {
+				s.KAEK = strings.TrimSpace(d.Value)
+			}
+		}
+		if s.Name == "" && s.KAEK == "" {
+			s.Name = fmt.Sprintf("placemark %d", len(shapes)+1)
+		}
+		s.Coords, s.Err = kmlCoords(pm)
+		shapes = append(shapes, s)
+	}
+	if len(shapes) == 0 {
+		return nil, errors.New("there are no placemarks in the file")
+	}
+
+	return shapes, nil
+}
+
+// The KML in the KMZ, doc.kml or else the first one at the top
+func parseKMZ(data []byte) ([]ImportedShape, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a KMZ file: %v", err)
+	}
+
+	var kml *zip.File
+	for _, f := range zr.File {
+		if f.Name == "doc.kml" {
+			kml = f
+			break
+		}
+		if kml == nil && strings.EqualFold(path.Ext(f.Name), ".kml") && !strings.Contains(f.Name, "/") {
+			kml = f
+		}
+	}
+	if kml == nil {
+		return nil, errors.New("there is no KML in the KMZ file")
+	}
+
+	r, err := kml.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			log.Println("r.Close() error: ", err)
+		}
+	}()
+	doc, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseKML(doc)
+}
+
+func kmlCoords(pm kmlPlacemark) ([]Coordinates, error) {
+	multi := kmlMultiGeometry{Polygons: pm.Polygons, Multi: pm.Multi, Lines: pm.Lines}
+
+	var shape [][]ring
+	var collect func(m kmlMultiGeometry) error
+	collect = func(m kmlMultiGeometry) error {
+		for _, p := range m.Polygons {
+			outer, err := parseKMLCoordinates(p.Outer.Coordinates)
+			if err != nil {
+				return err
+			}
+			part := []ring{outer}
+			for _, b := range p.Inner {
+				hole, err := parseKMLCoordinates(b.Coordinates)
+				if err != nil {
+					return err
+				}
+				part = append(part, hole)
+			}
+			shape = append(shape, part)
+		}
+		for _, l := range m.Lines {
+			outer, err := parseKMLCoordinates(l.Coordinates)
+			if err != nil {
+				return err
+			}
+			shape = append(shape, []ring{outer})
+		}
+		for _, sub := range m.Multi {
+			if err := collect(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := collect(multi); err != nil {
+		return nil, err
+	}
+
+	coords := numberShape(shape)
+	if len(coords) == 0 {
+		if len(pm.Points) > 0 {
+			return nil, errors.New("a point is not a field, draw a polygon")
+		}
+		return nil, errors.New("the placemark has no polygon")
+	}
+
+	return coords, validateShape(coords)
+}
+
+// The "lon,lat[,alt]" tuples of a KML ring, without the closing corner
+func parseKMLCoordinates(text string) (ring, error) {
+	var r ring
+	for _, tuple := range strings.Fields(text) {
+		fields := strings.Split(tuple, ",")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("bad coordinates: %s", tuple)
+		}
+		lon, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad coordinates: %s", tuple)
+		}
+		lat, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad coordinates: %s", tuple)
+		}
+		r = append(r, Coordinates{Latitude: lat, Longitude: lon})
+	}
+
+	return openRing(r), nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestContractsKML_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	maria := []RenterDetails{{FirstName: "Μαρία", LastName: "Νικολάου"}}
+	entries := []Entry{
+		{ID: 1, Name: "Κάτω <χωράφι>", KAEK: "111", Status: statusActive, Renters: maria, Coords: fieldWithHole()},
+		{ID: 2, Name: "Πάνω", KAEK: "222", Status: statusExpired, Renters: maria,
+			Coords: corners(0, 0, 39, 23, 39, 23.01, 39.01, 23.01)},
+		{ID: 3, Name: "no drawing", Status: statusActive},
+	}
+
+	data, err := contractsKML(entries, date("01-01-2026"), kmlColourByStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kml := string(data)
+	for _, want := range []string{
+		`<Style id="status-active">`,
+		`<Style id="status-expired">`,
+		"<color>66327d2e</color>",
+		"<styleUrl>#status-active</styleUrl>",
+		"&lt;table&gt;",
+		"Κάτω &amp;lt;χωράφι&amp;gt;",
+		"<innerBoundaryIs>",
+	} {
+		if !strings.Contains(kml, want) {
+			t.Fatalf("expected %q in\n%s", want, kml)
+		}
+	}
+
+	byRenter, err := contractsKML(entries, date("01-01-2026"), kmlColourByRenter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(byRenter), "<Style "); n != 1 {
+		t.Fatalf("expected one style for the one renter, got %d", n)
+	}
+
+	kmz, err := contractsKMZ(entries, date("01-01-2026"), kmlColourByStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shapes, err := parseKMZ(kmz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shapes) != 2 || shapes[0].Err != nil || shapes[0].KAEK != "111" || shapes[1].Name != "Πάνω" {
+		t.Fatalf("unexpected shapes: %+v", shapes)
+	}
+	if got, want := ellipsoidalArea(shapes[0].Coords), ellipsoidalArea(entries[0].Coords); math.Abs(got-want) > 1e-6 {
+		t.Fatalf("the area changed on the way: %f and %f", got, want)
+	}
+}
+
+// as Google Earth saves a polygon, a path and a pin in a folder
+const googleEarthKML = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">
+<Document>
+	<name>Χωράφια.kml</name>
+	<Folder>
+		<name>Χωράφια</name>
+		<Placemark>
+			<name>Αλώνι</name>
+			<ExtendedData><SchemaData schemaUrl="#s"><SimpleData name="KAEK">123</SimpleData></SchemaData></ExtendedData>
+			<Polygon>
+				<tessellate>1</tessellate>
+				<outerBoundaryIs>
+					<LinearRing>
+						<coordinates>
+							23,38,0 23.01,38,0 23.01,38.01,0 23,38.01,0 23,38,0
+						</coordinates>
+					</LinearRing>
+				</outerBoundaryIs>
+			</Polygon>
+		</Placemark>
+		<Placemark>
+			<name>Λόγγος</name>
+			<LineString><coordinates>24,39 24.01,39 24.01,39.01</coordinates></LineString>
+		</Placemark>
+		<Placemark>
+			<name>Πηγάδι</name>
+			<Point><coordinates>23.005,38.005,0</coordinates></Point>
+		</Placemark>
+	</Folder>
+</Document>
+</kml>`
+
+func TestParseKML_GoogleEarth(t *testing.T) {
+	t.Parallel()
+
+	shapes, err := parseKML([]byte(googleEarthKML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shapes) != 3 {
+		t.Fatalf("expected 3 placemarks, got %+v", shapes)
+	}
+
+	if s := shapes[0]; s.Err != nil || s.Name != "Αλώνι" || s.KAEK != "123" || len(s.Coords) != 4 {
+		t.Fatalf("unexpected polygon: %+v", s)
+	}
+	if c := shapes[0].Coords[1]; c.Latitude != 38 || c.Longitude != 23.01 {
+		t.Fatalf("expected longitude first in the file, got %+v", c)
+	}
+	if s := shapes[1]; s.Err != nil || len(s.Coords) != 3 {
+		t.Fatalf("the path should be a field: %+v", s)
+	}
+	if s := shapes[2]; s.Err == nil {
+		t.Fatalf("the pin should be refused: %+v", s)
+	}
+
+	if _, err := parseKML([]byte("<kml><Document></Document></kml>")); err == nil {
+		t.Fatal("expected an error without placemarks")
+	}
+	if _, err := parseKMZ([]byte("not a zip")); err == nil {
+		t.Fatal("expected an error for a KMZ that isn't a zip")
+	}
+}
+
+func TestParseMapFile(t *testing.T) {
+	t.Parallel()
+
+	if shapes, err := parseMapFile("Χωράφια.KML", []byte(googleEarthKML), defaultGPXTolerance); err != nil || len(shapes) != 3 {
+		t.Fatalf("unexpected result for KML: %+v %v", shapes, err)
+	}
+	plan := "476000 4205000\n476100 4205000\n476100 4205100\n"
+	if shapes, err := parseMapFile("Αλώνι.csv", []byte(plan), defaultGPXTolerance); err != nil || len(shapes) != 1 || shapes[0].Name != "Αλώνι" || len(shapes[0].Coords) != 3 {
+		t.Fatalf("unexpected result for the list: %+v %v", shapes, err)
+	}
+	if _, err := parseMapFile("fields.shp", nil, defaultGPXTolerance); err == nil {
+		t.Fatal("expected an error for a shapefile")
+	}
+}
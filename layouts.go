@@ -5,7 +5,9 @@ import (
 	"image/color"
 	"io"
 	"log"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -24,6 +26,10 @@ func addForm(appState *AppState) (fyne.CanvasObject, error) {
 	entriesMap := make(map[string]*widget.Entry)
 
 	durationLabel := widget.NewLabel("Διαρκεια")
+	draftCheck := widget.NewCheck(statusLabels[statusDraft], nil)
+	parentSelect, parentID := newParentSelect(appState, 0, 0)
+	noticeInputs, notice := newNoticeInputs(0, 0, noticeBoth)
+	parcelsButton, parcels := newParcelsPicker(appState, nil)
 
 	var landLords []OwnerDetails
 	var renters []RenterDetails
@@ -52,12 +58,7 @@ func addForm(appState *AppState) (fyne.CanvasObject, error) {
 		}
 	}
 
-	for i := range 4 {
-		lat := NewFilteredEntry(`[^0-9.]`, fmt.Sprintf("Πλάτος %d", i+1))
-		entriesMap[fmt.Sprintf("Πλάτος %d", i+1)] = lat
-		long := NewFilteredEntry(`[^0-9.]`, fmt.Sprintf("Μήκος %d", i+1))
-		entriesMap[fmt.Sprintf("Μήκος %d", i+1)] = long
-	}
+	typeSelect, shareInput, contractType, share := newContractTypeSelect(contractLease, 0, entriesMap["Μίσθωμα"])
 
 	// Starting date input and it's button that opens a calendar for easier date choosing
 	startInput := widget.NewEntry()
@@ -94,9 +95,7 @@ func addForm(appState *AppState) (fyne.CanvasObject, error) {
 	})
 
 	// Button to add Geo Coordinates
-	addGeoLocButton := widget.NewButtonWithIcon("Add GeoCoordinates", theme.ContentAddIcon(), func() {
-		showGeoLocForm(appState, entriesMap)
-	})
+	addGeoLocButton, shape := newShapeEditor(appState, nil)
 
 	buttonEmisth := widget.NewButtonWithIcon("Μησθωτήριο", theme.FileIcon(), func() {
 		dlg := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
@@ -123,19 +122,8 @@ func addForm(appState *AppState) (fyne.CanvasObject, error) {
 
 	// Save button
 	saveBtn := widget.NewButton("Αποθήκευση", func() {
-		// Convert to float64 and gather the coordinates
-		coords := make([]Coordinates, 0, 4)
-		for i := range 4 {
-			latValue, errLat := ParseFloatToXDecimals(entriesMap[fmt.Sprintf("Πλάτος %d", i+1)].Text, 5)
-			longValue, errLon := ParseFloatToXDecimals(entriesMap[fmt.Sprintf("Μήκος %d", i+1)].Text, 5)
-
-			if errLat != nil || errLon != nil {
-				log.Printf("Error parsing coordinates")
-				dialog.ShowError(errLat, appState.window)
-			}
-
-			coords = append(coords, Coordinates{Latitude: latValue, Longitude: longValue})
-		}
+		// the coordinates were checked when the editor accepted them
+		coords := shape()
 
 		atak, err := strconv.ParseInt(entriesMap["ATAK"].Text, 10, 32)
 		if err != nil {
@@ -157,6 +145,13 @@ func addForm(appState *AppState) (fyne.CanvasObject, error) {
 		}
 		money = TruncateFloatTo2Decimals(money)
 
+		shareValue, err := share()
+		if err != nil {
+			log.Printf("Error parsing the share of the crop")
+			dialog.ShowError(err, appState.window)
+			return
+		}
+
 		log.Printf("--- landlords: %v", landLords)
 		for _, l := range landLords {
 			log.Printf("--- landlord: %s, %s\n", l.FirstName, l.LastName)
@@ -182,26 +177,51 @@ func addForm(appState *AppState) (fyne.CanvasObject, error) {
 			Start:     startInput.Text,
 			End:       endInput.Text,
 			Rent:      money,
+			ParentID:  parentID(),
 			emisth:    selectedFileBytes,
 		}
-
-		err = saveEntry(appState.db, newEntry)
+		newEntry.ContractType = contractType()
+		newEntry.Share = shareValue
+		newEntry.Parcels = parcels()
+		newEntry.NoticeMonths, newEntry.AutoRenewMonths, newEntry.NoticeParty, err = notice()
 		if err != nil {
-			log.Printf("Error saving entry: %v", err)
 			dialog.ShowError(err, appState.window)
 			return
 		}
-
-		log.Println("Entry saved!")
-		dialog.ShowInformation("Database:", "Saved successfully!", appState.window)
-
-		// return to mainView
-		mainview, err := contractView(appState)
-		if err != nil {
-			log.Printf("error constructing list layout: %v", err)
+		if draftCheck.Checked {
+			newEntry.Status = statusDraft
+		}
+		if err := validateContractType(newEntry); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		if err := validateNotice(newEntry); err != nil {
 			dialog.ShowError(err, appState.window)
+			return
+		}
+		if !checkSublease(appState, newEntry) {
+			return
 		}
-		appState.window.SetContent(container.NewStack(appState.bg, mainview))
+
+		checkOverlaps(appState, newEntry, func(newEntry Entry) {
+			err := saveEntry(appState.db, newEntry, numberingScheme(appState))
+			if err != nil {
+				log.Printf("Error saving entry: %v", err)
+				dialog.ShowError(err, appState.window)
+				return
+			}
+
+			log.Println("Entry saved!")
+			dialog.ShowInformation("Database:", "Saved successfully!", appState.window)
+
+			// return to mainView
+			mainview, err := contractView(appState)
+			if err != nil {
+				log.Printf("error constructing list layout: %v", err)
+				dialog.ShowError(err, appState.window)
+			}
+			appState.window.SetContent(container.NewStack(appState.bg, mainview))
+		})
 	})
 
 	// Cancel button to go back
@@ -233,11 +253,17 @@ func addForm(appState *AppState) (fyne.CanvasObject, error) {
 			entriesMap["KAEK"],
 			entriesMap["Στρέμματα"],
 			entriesMap["Είδος Καλ/γειας"],
+			typeSelect,
+			shareInput,
 			container.NewGridWithColumns(1, entriesMap["Μίσθωμα"], buttonEmisth),
 			layout.NewSpacer(),
 			durationLabel,
 			startDateInput,
 			endDateInput,
+			draftCheck,
+			noticeInputs,
+			parentSelect,
+			parcelsButton,
 		)
 
 		content := container.NewGridWithColumns(2, leftContainer, rightContainer)
@@ -293,11 +319,17 @@ func addForm(appState *AppState) (fyne.CanvasObject, error) {
 		entriesMap["KAEK"],
 		entriesMap["Στρέμματα"],
 		entriesMap["Είδος Καλ/γειας"],
+		typeSelect,
+		shareInput,
 		container.NewGridWithColumns(2, entriesMap["Μίσθωμα"], buttonEmisth),
 		layout.NewSpacer(),
 		durationLabel,
 		startDateInput,
 		endDateInput,
+		draftCheck,
+		noticeInputs,
+		parentSelect,
+		parcelsButton,
 	)
 
 	// Putting both left and right containers on a grid
@@ -327,10 +359,16 @@ func editForm(appState *AppState, id uint) (fyne.CanvasObject, error) {
 		return nil, err
 	}
 
-	landLords := selectedEntry.Owners
-	renters := selectedEntry.Renters
+	// the rent, the area and the parties are edited as they are today, a
+	// change of them is saved as an amendment or as a correction
+	current := selectedEntry.AsOf(time.Now())
+	landLords := current.Owners
+	renters := current.Renters
 
 	durationLabel := widget.NewLabel("Διαρκεια")
+	parentSelect, parentID := newParentSelect(appState, id, selectedEntry.ParentID)
+	noticeInputs, notice := newNoticeInputs(selectedEntry.NoticeMonths, selectedEntry.AutoRenewMonths, selectedEntry.NoticeParty)
+	parcelsButton, parcels := newParcelsPicker(appState, selectedEntry.Parcels)
 
 	labelsEntries := []string{
 		"Όνομα",
@@ -358,19 +396,11 @@ func editForm(appState *AppState, id uint) (fyne.CanvasObject, error) {
 	entriesMap["Όνομα"].SetText(selectedEntry.Name)
 	entriesMap["ATAK"].SetText(strconv.FormatUint(uint64(selectedEntry.ATAK), 10))
 	entriesMap["KAEK"].SetText(selectedEntry.KAEK)
-	entriesMap["Στρέμματα"].SetText(strconv.FormatFloat(selectedEntry.Size, 'f', -1, 64))
+	entriesMap["Στρέμματα"].SetText(strconv.FormatFloat(current.Size, 'f', -1, 64))
 	entriesMap["Είδος Καλ/γειας"].SetText(selectedEntry.Type)
-	entriesMap["Μίσθωμα"].SetText(strconv.FormatFloat(selectedEntry.Rent, 'f', -1, 64))
-
-	for i := range 4 {
-		lat := NewFilteredEntry(`[^0-9.]`, fmt.Sprintf("Πλάτος %d", i+1))
-		lat.SetText(strconv.FormatFloat(selectedEntry.Coords[i].Latitude, 'f', -1, 64))
-		entriesMap[fmt.Sprintf("Πλάτος %d", i+1)] = lat
+	entriesMap["Μίσθωμα"].SetText(strconv.FormatFloat(current.Rent, 'f', -1, 64))
 
-		long := NewFilteredEntry(`[^0-9.]`, fmt.Sprintf("Μήκος %d", i+1))
-		long.SetText(strconv.FormatFloat(selectedEntry.Coords[i].Longitude, 'f', -1, 64))
-		entriesMap[fmt.Sprintf("Μήκος %d", i+1)] = long
-	}
+	typeSelect, shareInput, contractType, share := newContractTypeSelect(selectedEntry.ContractType, current.Share, entriesMap["Μίσθωμα"])
 
 	// Starting date input and it's button that opens a calendar for easier date choosing
 	startInput := widget.NewEntry()
@@ -413,9 +443,7 @@ func editForm(appState *AppState, id uint) (fyne.CanvasObject, error) {
 		})
 	})
 
-	addGeoLocButton := widget.NewButtonWithIcon("Add GeoCoordinates", theme.ContentAddIcon(), func() {
-		showGeoLocForm(appState, entriesMap)
-	})
+	addGeoLocButton, shape := newShapeEditor(appState, selectedEntry.Coords)
 
 	labelEmisth := widget.NewLabel("Μησθωτήριο")
 	buttonEmisth := widget.NewButtonWithIcon("Add Μησθωτήριο", theme.FileIcon(), func() {
@@ -442,24 +470,8 @@ func editForm(appState *AppState, id uint) (fyne.CanvasObject, error) {
 	containerEmisth := container.NewVBox(labelEmisth, buttonEmisth)
 
 	saveBtn := widget.NewButton("Αποθήκευση", func() {
-		// Convert to float64 and gather the coordinates
-		coords := make([]Coordinates, 0, 4)
-		for i := range 4 {
-			latValue, err := ParseFloatToXDecimals(entriesMap[fmt.Sprintf("Πλάτος %d", i+1)].Text, 5)
-			if err != nil {
-				latValue = 0
-				log.Printf("Error parsing coordinates: %v", err)
-				dialog.ShowError(err, appState.window)
-			}
-			longValue, err := ParseFloatToXDecimals(entriesMap[fmt.Sprintf("Μήκος %d", i+1)].Text, 5)
-			if err != nil {
-				longValue = 0
-				log.Printf("Error parsing coordinates: %v", err)
-				dialog.ShowError(err, appState.window)
-			}
-
-			coords = append(coords, Coordinates{Latitude: latValue, Longitude: longValue})
-		}
+		// the coordinates were checked when the editor accepted them
+		coords := shape()
 
 		atak, err := strconv.ParseInt(entriesMap["ATAK"].Text, 10, 32)
 		if err != nil {
@@ -481,6 +493,13 @@ func editForm(appState *AppState, id uint) (fyne.CanvasObject, error) {
 		}
 		money = TruncateFloatTo2Decimals(money)
 
+		shareValue, err := share()
+		if err != nil {
+			log.Printf("Error parsing the share of the crop")
+			dialog.ShowError(err, appState.window)
+			return
+		}
+
 		// We build the new entry here
 		editedEntry := Entry{
 			ID:        id,
@@ -496,19 +515,58 @@ func editForm(appState *AppState, id uint) (fyne.CanvasObject, error) {
 			Start:     startInput.Text,
 			End:       endInput.Text,
 			Rent:      money,
+			ParentID:  parentID(),
 			emisth:    selectedFileBytes,
 		}
-		// editedEntry.LandlordName = append(editedEntry.LandlordName, entriesMap["Εκμισθωτής"].Text)
-
-		err = updateEntry(appState.db, editedEntry)
+		editedEntry.Amendments = selectedEntry.Amendments
+		editedEntry.ContractType = contractType()
+		editedEntry.Share = shareValue
+		editedEntry.Parcels = parcels()
+		editedEntry.NoticeMonths, editedEntry.AutoRenewMonths, editedEntry.NoticeParty, err = notice()
 		if err != nil {
-			log.Printf("Error saving entry: %v", err)
 			dialog.ShowError(err, appState.window)
 			return
 		}
+		if err := validateContractType(editedEntry); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		if err := validateNotice(editedEntry); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		if !checkSublease(appState, editedEntry) {
+			return
+		}
+		// editedEntry.LandlordName = append(editedEntry.LandlordName, entriesMap["Εκμισθωτής"].Text)
 
-		log.Printf("Saved entry: %d", editedEntry.ID)
-		dialog.ShowInformation("Database:", "Saved successfully!", appState.window)
+		save := func(e Entry, amendments []Amendment) {
+			err := amendEntry(appState.db, e, amendments)
+			if err != nil {
+				log.Printf("Error saving entry: %v", err)
+				dialog.ShowError(err, appState.window)
+				return
+			}
+
+			log.Printf("Saved entry: %d with %d amendments", e.ID, len(amendments))
+			dialog.ShowInformation("Database:", "Saved successfully!", appState.window)
+		}
+
+		checkOverlaps(appState, editedEntry, func(editedEntry Entry) {
+			base, amendments := amendmentsFromEdit(selectedEntry, editedEntry, time.Now().Format(dateLayout))
+			if len(amendments) == 0 {
+				save(editedEntry, nil)
+				return
+			}
+			showAmendOrCorrect(appState, len(selectedEntry.Amendments) == 0, func(effective string) {
+				for i := range amendments {
+					amendments[i].Effective = effective
+				}
+				save(base, amendments)
+			}, func() {
+				save(editedEntry, nil)
+			})
+		})
 	})
 
 	backButton := widget.NewButton("Cancel", func() {
@@ -540,12 +598,17 @@ func editForm(appState *AppState, id uint) (fyne.CanvasObject, error) {
 		entriesMap["KAEK"],
 		entriesMap["Στρέμματα"],
 		entriesMap["Είδος Καλ/γειας"],
+		typeSelect,
+		shareInput,
 		entriesMap["Μίσθωμα"],
 		containerEmisth,
 		layout.NewSpacer(),
 		durationLabel,
 		startDateInput,
 		endDateInput,
+		noticeInputs,
+		parentSelect,
+		parcelsButton,
 	)
 
 	// Putting both left and right containters on a grid
@@ -608,6 +671,39 @@ func mainView(appState *AppState) (fyne.CanvasObject, error) {
 		appState.window.SetContent(container.NewStack(appState.bg, view))
 	})
 
+	parcelsButton := widget.NewButton("Αγροτεμάχια", func() {
+		view, err := parcelsView(appState)
+		if err != nil {
+			log.Printf("error constructing parcelsView: %v\n", err)
+			dialog.ShowError(err, appState.window)
+			return
+		}
+
+		appState.window.SetContent(container.NewStack(appState.bg, view))
+	})
+
+	reportsButton := widget.NewButton("Αναφορές", func() {
+		view, err := reportsView(appState)
+		if err != nil {
+			log.Printf("error constructing reportsView: %v\n", err)
+			dialog.ShowError(err, appState.window)
+			return
+		}
+
+		appState.window.SetContent(container.NewStack(appState.bg, view))
+	})
+
+	mapButton := widget.NewButton("Χάρτης", func() {
+		view, err := mapView(appState)
+		if err != nil {
+			log.Printf("error constructing mapView: %v\n", err)
+			dialog.ShowError(err, appState.window)
+			return
+		}
+
+		appState.window.SetContent(container.NewStack(appState.bg, view))
+	})
+
 	// settingsButton := widget.NewButton("Ρυθμίσεις", func() {
 	// 	err := settingsView(appState)
 	// 	if err != nil {
@@ -628,7 +724,7 @@ func mainView(appState *AppState) (fyne.CanvasObject, error) {
 	}
 
 	customLayout := NewCenteredButtonsLayout(200, 60, 20)
-	content := container.New(customLayout, container.NewBorder(yearSelect, nil, nil, nil, nil), listViewButton, landLordButton, renterButton)
+	content := container.New(customLayout, container.NewBorder(yearSelect, nil, nil, nil, nil), listViewButton, landLordButton, renterButton, parcelsButton, reportsButton, mapButton)
 	body := container.NewStack(appState.bg, appState.logo, container.NewBorder(nil, appState.userLabel, nil, nil, content))
 
 	return body, nil
@@ -794,13 +890,307 @@ func ownersView(appState *AppState) (fyne.CanvasObject, error) {
 	return body, nil
 }
 
+func parcelsView(appState *AppState) (fyne.CanvasObject, error) {
+	log.Println("Creating the parcelsView...")
+	parcels, err := getParcels(appState.db)
+	if err != nil {
+		return nil, err
+	}
+	shown := parcels
+
+	searchInput := widget.NewEntry()
+	searchInput.SetPlaceHolder("Αναζήτηση ΚΑΕΚ, ΑΤΑΚ ή δήμου")
+
+	var list *widget.List
+	reload := func() {
+		parcels, err = getParcels(appState.db)
+		if err != nil {
+			log.Printf("Error updating the parcels list: %v", err)
+		}
+		shown = searchParcels(parcels, searchInput.Text)
+		list.Refresh()
+	}
+	searchInput.OnChanged = func(string) {
+		shown = searchParcels(parcels, searchInput.Text)
+		list.Refresh()
+	}
+
+	list = widget.NewList(
+		func() int {
+			return len(shown)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle.Bold = true
+			button := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), nil)
+			return container.NewHBox(label, layout.NewSpacer(), button)
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			if lii < 0 || lii >= len(shown) {
+				return
+			}
+			p := shown[lii]
+			hbox := co.(*fyne.Container)
+			hbox.Objects[0].(*widget.Label).SetText(fmt.Sprintf("%s  %.3f στρ.", parcelLabel(p), p.Area))
+			hbox.Objects[2].(*widget.Button).OnTapped = func() {
+				dialog.ShowConfirm("Επιβεβαίωση Διαγραφής", "Είσαι σίγουρος;", func(b bool) {
+					if !b {
+						return
+					}
+					if err := delParcel(appState.db, p.ID); err != nil {
+						dialog.ShowError(err, appState.window)
+						return
+					}
+					reload()
+				}, appState.window)
+			}
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		if id >= 0 && id < len(shown) {
+			showParcelDetails(appState, shown[id], reload)
+		}
+		list.UnselectAll()
+	}
+
+	var addButton fyne.CanvasObject
+	var backButton fyne.CanvasObject
+
+	back := func() {
+		tmp, err := mainView(appState)
+		if err != nil {
+			log.Printf("error constructing main layout: %v", err)
+		}
+		appState.window.SetContent(tmp)
+	}
+	add := func() {
+		showParcelForm(appState, Parcel{}, reload)
+	}
+	if fyne.CurrentDevice().IsMobile() {
+		addButton = widget.NewButtonWithIcon("", theme.ContentAddIcon(), add)
+		backButton = widget.NewButtonWithIcon("", theme.ContentUndoIcon(), back)
+	} else {
+		addButton = widget.NewButtonWithIcon("Add New Entry", theme.ContentAddIcon(), add)
+		backButton = widget.NewButtonWithIcon("Back", theme.ContentUndoIcon(), back)
+	}
+
+	lookupButton := widget.NewButtonWithIcon("Σημείο", theme.SearchIcon(), func() {
+		showPointLookup(appState)
+	})
+
+	body := container.NewBorder(
+		container.NewBorder(nil, nil, nil, lookupButton, searchInput),
+		container.NewHBox(layout.NewSpacer(), container.NewPadded(backButton), container.NewPadded(addButton)),
+		nil,
+		nil,
+		list,
+	)
+	log.Println("parcelsView created successfully!")
+
+	return body, nil
+}
+
+func showParcelDetails(appState *AppState, parcel Parcel, onChange func()) {
+	entries, err := getAllEntries(appState.db)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+
+	historyContainer := container.NewVBox(widget.NewLabel("Συμβόλαια:"))
+	history := parcelHistory(parcel.ID, entries)
+	if len(history) == 0 {
+		historyContainer.Add(widget.NewLabel("\tΚανένα"))
+	}
+	for _, e := range history {
+		historyContainer.Add(widget.NewLabel(fmt.Sprintf("\t%s  %s - %s  %s  %s",
+			contractLabel(e), e.Start, e.ActualEnd(), statusLabels[e.Status], strings.Join(renterNames(e.AsOf(time.Now()).Renters), ", "))))
+	}
+
+	coordsLabel := widget.NewLabel("Συντεταγμένες:\n" + strings.Join(shapeLines(parcel.Coords), "\n"))
+	notesLabel := widget.NewLabel("Σημειώσεις:\n" + parcel.Notes)
+	notesLabel.Wrapping = fyne.TextWrapWord
+
+	editButton := widget.NewButton("Edit", nil)
+	closeButton := widget.NewButton("Close", nil)
+
+	content := container.NewBorder(
+		nil,
+		container.NewGridWithColumns(2, editButton, closeButton),
+		nil,
+		nil,
+		container.NewVScroll(container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("ΚΑΕΚ: %s", parcel.KAEK)),
+			widget.NewLabel(fmt.Sprintf("ΑΤΑΚ: %d", parcel.ATAK)),
+			widget.NewLabel(fmt.Sprintf("Στρέμματα: %.3f", parcel.Area)),
+			widget.NewLabel(fmt.Sprintf("Δήμος: %s", parcel.Municipality)),
+			historyContainer,
+			coordsLabel,
+			notesLabel,
+		)),
+	)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	editButton.OnTapped = func() {
+		popup.Hide()
+		showParcelForm(appState, parcel, onChange)
+	}
+	closeButton.OnTapped = func() {
+		popup.Hide()
+	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.66))
+	popup.Show()
+}
+
+// Whose land is a point, typed or pasted as a coordinate
+func showPointLookup(appState *AppState) {
+	pointInput := widget.NewEntry()
+	pointInput.SetPlaceHolder("37.9838, 23.7275 ή ΕΓΣΑ87 X Y")
+	results := container.NewVBox()
+
+	search := func() {
+		results.RemoveAll()
+		c, err := parsePoint(pointInput.Text)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		hits, err := lookupPoint(appState.db, c, time.Now())
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		if len(hits) == 0 {
+			results.Add(widget.NewLabel("Κανένα αγροτεμάχιο σε αυτό το σημείο"))
+			return
+		}
+
+		for _, h := range hits {
+			box := container.NewVBox()
+			if len(h.Current) == 0 {
+				box.Add(widget.NewLabel("Κανένα τρέχον συμβόλαιο"))
+			}
+			for _, e := range h.Current {
+				terms := e.AsOf(time.Now())
+				details := widget.NewButtonWithIcon("", theme.InfoIcon(), func() {
+					current := h.Current
+					showDetailsPopup(e, appState, nil, &current)
+				})
+				box.Add(container.NewBorder(nil, nil, nil, details, widget.NewLabel(fmt.Sprintf("%s  %s - %s  %s",
+					contractLabel(e), e.Start, e.ActualEnd(), statusLabels[e.Status]))))
+				box.Add(widget.NewLabel("Ιδιοκτήτες: " + strings.Join(ownerNames(terms.Owners), ", ")))
+				box.Add(widget.NewLabel("Μισθωτές: " + strings.Join(renterNames(terms.Renters), ", ")))
+			}
+			results.Add(widget.NewCard(parcelLabel(h.Parcel), fmt.Sprintf("Στρέμματα: %.3f", h.Parcel.Area), box))
+		}
+	}
+	pointInput.OnSubmitted = func(string) { search() }
+
+	searchButton := widget.NewButtonWithIcon("", theme.SearchIcon(), search)
+	closeButton := widget.NewButton("Close", nil)
+
+	content := container.NewBorder(
+		container.NewVBox(
+			widget.NewLabel("Σημείο:"),
+			container.NewBorder(nil, nil, nil, searchButton, pointInput),
+		),
+		closeButton,
+		nil,
+		nil,
+		container.NewVScroll(results),
+	)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	closeButton.OnTapped = func() {
+		popup.Hide()
+	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.66))
+	popup.Show()
+	appState.window.Canvas().Focus(pointInput)
+}
+
+func showParcelForm(appState *AppState, parcel Parcel, onSaved func()) {
+	kaekInput := widget.NewEntry()
+	kaekInput.SetPlaceHolder("ΚΑΕΚ")
+	kaekInput.SetText(parcel.KAEK)
+	atakInput := NewFilteredEntry(`[^0-9]`, "ΑΤΑΚ")
+	if parcel.ATAK != 0 {
+		atakInput.SetText(strconv.FormatUint(uint64(parcel.ATAK), 10))
+	}
+	areaInput := NewFilteredEntry(`[^0-9.]`, "Στρέμματα")
+	if parcel.Area != 0 {
+		areaInput.SetText(strconv.FormatFloat(parcel.Area, 'f', -1, 64))
+	}
+	municipalityInput := widget.NewEntry()
+	municipalityInput.SetPlaceHolder("Δήμος")
+	municipalityInput.SetText(parcel.Municipality)
+	coordsButton, shape := newShapeEditor(appState, parcel.Coords)
+	notesInput := widget.NewMultiLineEntry()
+	notesInput.SetPlaceHolder("Σημειώσεις")
+	notesInput.SetText(parcel.Notes)
+
+	saveButton := widget.NewButton("Αποθήκευση", nil)
+	cancelButton := widget.NewButton("Cancel", nil)
+
+	content := container.NewBorder(
+		nil,
+		container.NewGridWithColumns(2, cancelButton, saveButton),
+		nil,
+		nil,
+		container.NewVScroll(container.NewVBox(kaekInput, atakInput, areaInput, municipalityInput, coordsButton, notesInput)),
+	)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	saveButton.OnTapped = func() {
+		p := parcel
+		p.KAEK = strings.TrimSpace(kaekInput.Text)
+		p.Municipality = strings.TrimSpace(municipalityInput.Text)
+		p.Notes = notesInput.Text
+
+		var err error
+		if atakInput.Text != "" {
+			atak, err := strconv.ParseUint(atakInput.Text, 10, 64)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("invalid ATAK: %v", err), appState.window)
+				return
+			}
+			p.ATAK = uint(atak)
+		}
+		p.Area = 0
+		if areaInput.Text != "" {
+			p.Area, err = ParseFloatToXDecimals(areaInput.Text, 3)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("invalid area: %v", err), appState.window)
+				return
+			}
+		}
+		p.Coords = shape()
+
+		if err := saveParcel(appState.db, p); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		popup.Hide()
+		onSaved()
+	}
+	cancelButton.OnTapped = func() {
+		popup.Hide()
+	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.8))
+	popup.Show()
+}
+
 func contractView(appState *AppState) (fyne.CanvasObject, error) {
 	log.Printf("Creating the contractView...")
-	entries, err := getAllEntriesByYear(appState.db, appState.year)
+	allEntries, err := getAllEntriesByYear(appState.db, appState.year)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Query Results: %v\n", entries)
+	log.Printf("Query Results: %v\n", allEntries)
+	entries := filterByStatus(allEntries, appState.statuses)
+
+	searchInput := widget.NewEntry()
+	searchInput.SetPlaceHolder("Αναζήτηση αριθμού ή ονόματος")
 
 	list := widget.NewList(
 		func() int {
@@ -812,7 +1202,7 @@ func contractView(appState *AppState) (fyne.CanvasObject, error) {
 			dateLabel := widget.NewLabel("End Date")
 			dateLabel.TextStyle.Italic = true
 
-			return container.NewBorder(nil, nil, nil, dateLabel, nameLabel)
+			return container.NewBorder(nil, nil, newStatusBadge(), dateLabel, nameLabel)
 		},
 		func(lii widget.ListItemID, co fyne.CanvasObject) {
 			log.Printf("Updating item with ID: %d", lii)
@@ -823,8 +1213,10 @@ func contractView(appState *AppState) (fyne.CanvasObject, error) {
 			entry := entries[lii]
 			box := co.(*fyne.Container)
 			nameLabel := box.Objects[0].(*widget.Label)
-			dateLabel := box.Objects[1].(*widget.Label)
-			nameLabel.SetText(entry.Name)
+			badge := box.Objects[1].(*fyne.Container)
+			dateLabel := box.Objects[2].(*widget.Label)
+			nameLabel.SetText(contractLabel(entry))
+			setStatusBadge(badge, entry.Status)
 			dateLabel.SetText(fmt.Sprintf("Λήξη: %s", entry.End))
 		},
 	)
@@ -833,7 +1225,7 @@ func contractView(appState *AppState) (fyne.CanvasObject, error) {
 		log.Printf("Selected item: %d", id)
 		if id >= 0 && id < len(entries) {
 			log.Printf("Showing popup for item: %d\n", entries[id].ID)
-			showDetailsPopup(entries[id], appState, list, &entries, &entries[id].Owners, &entries[id].Renters)
+			showDetailsPopup(entries[id], appState, list, &entries)
 			list.UnselectAll()
 		}
 	}
@@ -888,8 +1280,39 @@ func contractView(appState *AppState) (fyne.CanvasObject, error) {
 		emptyContainer.Hide()
 	}
 
+	refilter := func() {
+		entries = searchEntries(filterByStatus(allEntries, appState.statuses), searchInput.Text)
+		list.UnselectAll()
+		list.Refresh()
+		if list.Length() == 0 {
+			emptyContainer.Show()
+		} else {
+			emptyContainer.Hide()
+		}
+	}
+	searchInput.OnChanged = func(string) {
+		refilter()
+	}
+	statusFilter := newStatusFilter(appState, refilter)
+	numberingButton := widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
+		showNumberingForm(appState)
+	})
+	mapFilesButton := widget.NewButtonWithIcon("", theme.UploadIcon(), func() {
+		showMapFilesPopup(appState, entries, func() {
+			view, err := contractView(appState)
+			if err != nil {
+				log.Printf("error constructing contractView: %v\n", err)
+				dialog.ShowError(err, appState.window)
+				return
+			}
+			appState.window.SetContent(container.NewStack(appState.bg, view))
+		})
+	})
+	filters := container.NewVBox(container.NewBorder(nil, nil, nil, container.NewHBox(mapFilesButton, numberingButton), searchInput), statusFilter)
+
 	body := container.New(
-		layout.NewBorderLayout(nil, nil, nil, nil),
+		layout.NewBorderLayout(filters, nil, nil, nil),
+		filters,
 		emptyContainer,
 		container.NewVScroll(list),
 		container.New(
@@ -909,216 +1332,919 @@ func contractView(appState *AppState) (fyne.CanvasObject, error) {
 	return body, nil
 }
 
-// if and when the xwidget.NumericalEntry works this will actually be useful
-func focusChain(inputs []fyne.CanvasObject, appState *AppState, scrollContainer *fyne.Container) {
-	lastInput := inputs[len(inputs)-1]
-	for i, input := range inputs {
-		switch e := input.(type) {
-		case *widget.Entry:
-			e.OnSubmitted = func(_ string) {
-				// if i < len(inputs)-1 {
-				// 	appState.window.Canvas().Focus(inputs[i+1].(fyne.Focusable))
-				//
-				// 	// TODO: Fix the scrolling when focusing on the next entry
-				// 	offSet := inputs[i+1].Position()
-				// 	scrollContainer.ScrollToOffset(offSet)
-				// } else {
-				// 	fyne.CurrentDevice().(mobile.Device).HideVirtualKeyboard()
-				// 	// e.Disable()
-				// 	// e.Enable()
-				// }
-				if input == lastInput {
-					fyne.CurrentDevice().(mobile.Device).HideVirtualKeyboard()
-					appState.window.Canvas().Unfocus()
-				} else {
-					// appState.window.Canvas().FocusNext()
-					appState.window.Canvas().Focus(inputs[i+1].(fyne.Focusable))
-				}
-			}
-		case *xwidget.NumericalEntry:
-			e.OnSubmitted = func(s string) {
-				if i < len(inputs)-1 {
-					appState.window.Canvas().Focus(inputs[i+1].(fyne.Focusable))
-				} else {
-					fyne.CurrentDevice().(mobile.Device).HideVirtualKeyboard()
-					// e.Disable()
-					// e.Enable()
-				}
-			}
+// Export of the listed contracts' fields for GIS tools and Google Earth and
+// import of fields into the contracts
+func showMapFilesPopup(appState *AppState, entries []Entry, onImported func()) {
+	exportButton := widget.NewButtonWithIcon("Εξαγωγή GeoJSON", theme.DocumentSaveIcon(), func() {
+		data, err := contractsGeoJSON(entries, time.Now())
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, "Συμβόλαια.geojson", data)
+	})
+
+	colourOpts := []string{kmlColourByLabels[kmlColourByStatus], kmlColourByLabels[kmlColourByRenter]}
+	colourSelect := widget.NewSelect(colourOpts, nil)
+	colourSelect.SetSelectedIndex(0)
+	colourBy := func() string {
+		if colourSelect.SelectedIndex() == 1 {
+			return kmlColourByRenter
 		}
+		return kmlColourByStatus
 	}
-}
+	kmlButton := widget.NewButtonWithIcon("KML", theme.DocumentSaveIcon(), func() {
+		data, err := contractsKML(entries, time.Now(), colourBy())
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, "Συμβόλαια.kml", data)
+	})
+	kmzButton := widget.NewButtonWithIcon("KMZ", theme.DocumentSaveIcon(), func() {
+		data, err := contractsKMZ(entries, time.Now(), colourBy())
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, "Συμβόλαια.kmz", data)
+	})
 
-func showOwnerDetails(appState *AppState, owner *OwnerDetails) {
-	log.Printf("Showing details for owner: %v\n", owner.ID)
+	importButton := widget.NewButtonWithIcon("Εισαγωγή (GeoJSON, KML, KMZ)", theme.FileIcon(), func() {
+		dlg := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer func() {
+				if err := reader.Close(); err != nil {
+					log.Println("reader.Close() error: ", err)
+				}
+			}()
 
-	editBtn := widget.NewButton("Edit", nil)
-	closeBtn := widget.NewButton("Close", nil)
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				dialog.ShowError(err, appState.window)
+				return
+			}
+			shapes, err := parseMapFile(reader.URI().Name(), data, gpxTolerance(appState))
+			if err != nil {
+				dialog.ShowError(err, appState.window)
+				return
+			}
+			showImportPreview(appState, shapes, onImported)
+		}, appState.window)
 
-	buttonsContainer := container.NewGridWithColumns(2, editBtn, closeBtn)
+		dlg.SetFilter(storage.NewExtensionFileFilter(mapFileExtensions))
+		dlg.Show()
+	})
 
-	scrollableContainer := container.NewVScroll(container.NewVBox(
-		widget.NewLabel(fmt.Sprintf("ID:               %d", owner.ID)),
-		widget.NewLabel(fmt.Sprintf("Όνομα:            %s", owner.FirstName)),
-		widget.NewLabel(fmt.Sprintf("Επώνυμο:          %s", owner.LastName)),
-		widget.NewLabel(fmt.Sprintf("Όνομα Πατρός:     %s", owner.FathersName)),
-		widget.NewLabel(fmt.Sprintf("Α.Φ.Μ.:           %d", owner.AFM)),
-		widget.NewLabel(fmt.Sprintf("Α.Δ.Τ.:           %s", owner.ADT)),
-		// TODO: Make button to show e9 in default app
-		widget.NewLabel(fmt.Sprintf("Διεύθυνση:        %s", owner.HomeAddress)),
-		widget.NewLabel(fmt.Sprintf("Τηλέφωνο:         %s", owner.PhoneNumber)),
-		widget.NewLabel(fmt.Sprintf("e-mail:           %s", owner.Email)),
-		widget.NewLabel(fmt.Sprintf("Στοιχία Λογιστή:\n%s", owner.AccountantInfo)),
-		widget.NewLabel(fmt.Sprintf("Notes:\n          %s", owner.Notes)),
-	))
+	closeButton := widget.NewButton("Close", nil)
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Συμβόλαια στη λίστα: %d", len(entries))),
+		exportButton,
+		container.NewBorder(nil, nil, widget.NewLabel("Google Earth, χρώμα ανά:"), nil, colourSelect),
+		container.NewGridWithColumns(2, kmlButton, kmzButton),
+		importButton,
+		closeButton,
+	)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	closeButton.OnTapped = func() {
+		popup.Hide()
+	}
+	popup.Show()
+}
 
-	content := container.NewBorder(nil, buttonsContainer, nil, nil, scrollableContainer)
+// Shows which contracts the imported fields go to and replaces their
+// coordinates with the ones that stay checked
+func showImportPreview(appState *AppState, shapes []ImportedShape, onImported func()) {
+	entries, err := getAllEntries(appState.db)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+	imports := matchImports(shapes, filterByStatus(entries, appState.statuses))
+
+	checks := make([]*widget.Check, len(imports))
+	lines := container.NewVBox()
+	for i, imp := range imports {
+		if imp.Err != nil {
+			label := widget.NewLabel(imp.Describe())
+			label.Wrapping = fyne.TextWrapWord
+			lines.Add(label)
+			continue
+		}
+		checks[i] = widget.NewCheck(imp.Describe(), nil)
+		checks[i].SetChecked(true)
+		lines.Add(checks[i])
+	}
+
+	importButton := widget.NewButton("Εισαγωγή", nil)
+	cancelButton := widget.NewButton("Cancel", nil)
+	content := container.NewBorder(
+		widget.NewLabel("Τα συμβόλαια θα πάρουν τις συντεταγμένες του αρχείου"),
+		container.NewGridWithColumns(2, importButton, cancelButton),
+		nil,
+		nil,
+		container.NewVScroll(lines),
+	)
 	popup := widget.NewModalPopUp(content, appState.window.Canvas())
 
-	editBtn.OnTapped = func() {
-		err := editOwner(appState, owner.ID)
-		if err != nil {
-			log.Println("editOwner error: ", err)
+	importButton.OnTapped = func() {
+		updated := 0
+		for i, imp := range imports {
+			if checks[i] == nil || !checks[i].Checked {
+				continue
+			}
+			var parcelIDs []uint
+			for _, p := range imp.Parcels {
+				parcelIDs = append(parcelIDs, p.ID)
+			}
+			if err := setParcelsCoords(appState.db, parcelIDs, imp.Shape.Coords); err != nil {
+				dialog.ShowError(err, appState.window)
+				return
+			}
+			if err := setEntriesCoords(appState.db, imp.EntryIDs(), imp.Shape.Coords); err != nil {
+				dialog.ShowError(err, appState.window)
+				return
+			}
+			updated += len(imp.Entries)
+		}
+		popup.Hide()
+		if onImported != nil {
+			onImported()
 		}
+		dialog.ShowInformation("Database:", fmt.Sprintf("Updated the coordinates of %d contracts", updated), appState.window)
 	}
-	closeBtn.OnTapped = func() {
+	cancelButton.OnTapped = func() {
 		popup.Hide()
 	}
 
 	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.66))
-
 	popup.Show()
 }
 
-func showRenterDetails(appState *AppState, renter *RenterDetails, list *widget.List) {
-	log.Printf("Showing details for renter: %v\n", renter.ID)
+func mapView(appState *AppState) (fyne.CanvasObject, error) {
+	log.Println("Creating the mapView...")
+	allEntries, err := getAllEntriesByYear(appState.db, appState.year)
+	if err != nil {
+		return nil, err
+	}
+	entries := filterByStatus(allEntries, appState.statuses)
 
-	editBtn := widget.NewButton("Edit", nil)
-	closeBtn := widget.NewButton("Close", nil)
+	dataDir := appState.app.Storage().RootURI().Path()
+	basemap, err := openBasemap(dataDir)
+	if err != nil {
+		log.Printf("Error opening the basemap: %v", err)
+		dialog.ShowError(err, appState.window)
+	}
 
-	// buttonsContainer := container.NewHBox(closeBtn, layout.NewSpacer(), editBtn)
-	buttonsContainer := container.NewGridWithColumns(2, editBtn, closeBtn)
+	basemapLabel := widget.NewLabel("")
+	setBasemapLabel := func(m *MBTiles) {
+		switch {
+		case m == nil:
+			basemapLabel.SetText("Χωρίς υπόβαθρο")
+		case m.Name != "":
+			basemapLabel.SetText("Υπόβαθρο: " + m.Name)
+		default:
+			basemapLabel.SetText("Υπόβαθρο: " + basemapFile)
+		}
+	}
+	setBasemapLabel(basemap)
 
-	scrollableContainer := container.NewVScroll(container.NewVBox(
-		widget.NewLabel(fmt.Sprintf("ID:           %d", renter.ID)),
-		widget.NewLabel(fmt.Sprintf("Όνομα:        %s", renter.FirstName)),
-		widget.NewLabel(fmt.Sprintf("Επώνυμο:      %s", renter.LastName)),
-		widget.NewLabel(fmt.Sprintf("Όνομα Πατρός: %s", renter.FathersName)),
-		widget.NewLabel(fmt.Sprintf("Α.Φ.Μ.:       %d", renter.AFM)),
-		widget.NewLabel(fmt.Sprintf("Α.Δ.Τ.:       %s", renter.ADT)),
-		// TODO: Make button to show e9 in default app
-		widget.NewLabel(fmt.Sprintf("Notes:\n  	   %s", renter.Notes)),
-	))
+	fieldsMap := NewMapWidget(basemap, &entries)
+	fieldsMap.OnTapped = func(lat, lon float64) {
+		// the smallest field when they are inside one another
+		hits := entriesAt(entries, lat, lon)
+		if len(hits) == 0 {
+			return
+		}
+		showDetailsPopup(hits[0], appState, nil, &entries)
+	}
 
-	content := container.NewBorder(nil, buttonsContainer, nil, nil, scrollableContainer)
-	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	importButton := widget.NewButtonWithIcon("", theme.UploadIcon(), func() {
+		dlg := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer func() {
+				if err := reader.Close(); err != nil {
+					log.Println("reader.Close() error: ", err)
+				}
+			}()
 
-	editBtn.OnTapped = func() {
-		err := editRenter(appState, renter.ID)
+			// the old tileset is closed so its file can be replaced
+			fieldsMap.SetBasemap(nil)
+			m, err := importBasemap(dataDir, reader)
+			if err != nil {
+				dialog.ShowError(err, appState.window)
+				if m, err = openBasemap(dataDir); err != nil {
+					log.Printf("Error reopening the basemap: %v", err)
+				}
+			}
+			fieldsMap.SetBasemap(m)
+			setBasemapLabel(m)
+		}, appState.window)
+
+		dlg.SetFilter(storage.NewExtensionFileFilter([]string{".mbtiles"}))
+		dlg.Show()
+	})
+
+	legend := container.NewHBox()
+	for _, s := range contractStatuses {
+		badge := newStatusBadge()
+		setStatusBadge(badge, s)
+		legend.Add(badge)
+	}
+
+	back := func() {
+		// closes the tileset
+		fieldsMap.SetBasemap(nil)
+		tmp, err := mainView(appState)
 		if err != nil {
-			log.Println("editRenter error: ", err)
+			log.Printf("error constructing main layout: %v", err)
 		}
+		appState.window.SetContent(tmp)
 	}
-	closeBtn.OnTapped = func() {
-		popup.Hide()
+	var backButton fyne.CanvasObject
+	if fyne.CurrentDevice().IsMobile() {
+		backButton = widget.NewButtonWithIcon("", theme.ContentUndoIcon(), back)
+	} else {
+		backButton = widget.NewButtonWithIcon("Back", theme.ContentUndoIcon(), back)
 	}
 
-	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.66))
+	toolbar := container.NewHBox(
+		widget.NewButtonWithIcon("", theme.ZoomInIcon(), fieldsMap.ZoomIn),
+		widget.NewButtonWithIcon("", theme.ZoomOutIcon(), fieldsMap.ZoomOut),
+		widget.NewButtonWithIcon("", theme.ZoomFitIcon(), fieldsMap.Fit),
+		importButton,
+		basemapLabel,
+	)
 
-	popup.Show()
+	body := container.NewBorder(
+		toolbar,
+		container.NewBorder(nil, nil, nil, container.NewPadded(backButton), container.NewHScroll(legend)),
+		nil,
+		nil,
+		fieldsMap,
+	)
+	log.Println("mapView created successfully!")
+
+	return body, nil
 }
 
-// func showDetailsRenterPopup(appState *AppState, renter *RenterDetails) {
-// 	log.Printf("Showing popup for: %v\n", renter.LastName)
-//
-// 	closeButton := widget.NewButton("Close", nil)
-//
-// 	buttonsContainer := container.NewVBox(
-// 		closeButton,
-// 	)
-//
-// 	entries, err := GetRenterEntries(appState.db, *renter)
-// 	if err != nil {
-// 		dialog.ShowError(err, appState.window)
-// 		return
-// 	}
-//
-// 	list := widget.NewList(
-// 		func() int {
-// 			return len(entries)
-// 		},
-// 		func() fyne.CanvasObject {
-// 			log.Printf("Creating list template...")
-// 			return widget.NewLabel("Template")
-// 		},
-// 		func(lii widget.ListItemID, co fyne.CanvasObject) {
-// 			log.Printf("Updating item with ID: %d", lii)
-// 			if lii < 0 || lii >= len(entries) {
-// 				log.Printf("Invalid item ID: %d", lii)
-// 				return
-// 			}
-// 			entry := entries[lii]
-// 			label, ok := co.(*widget.Label)
-// 			if !ok {
-// 				log.Printf("Canvas object is not *widget.Label, its: %s", fmt.Sprintf("%T", co))
-// 				return
-// 			}
-// 			label.SetText(fmt.Sprintf("%d: %s", entry.ID, entry.Name))
-// 		},
-// 	)
-//
-// 	list.OnSelected = func(id widget.ListItemID) {
-// 		log.Printf("Selected item: %d\n", id)
-// 		if id >= 0 && id < len(entries) {
-// 			log.Printf("Showing popup for item: %d\n", entries[id].ID)
-// 			showDetailsPopup(entries[id], appState, list, &entries, &entries[id].Owners, &entries[id].Renters)
-// 			list.UnselectAll()
-// 		}
-// 	}
-//
-// 	scrollableList := container.NewVScroll(list)
-// 	scrollableList.SetMinSize(fyne.NewSize(appState.window.Canvas().Size().Width*0.7, appState.window.Canvas().Size().Height*0.7))
-//
-// 	content := container.NewVBox(
-// 		scrollableList,
-// 		buttonsContainer,
-// 	)
-//
-// 	popup := widget.NewModalPopUp(content, appState.window.Canvas())
-//
-// 	closeButton.OnTapped = func() {
-// 		popup.Hide()
-// 	}
-//
-// 	popup.Show()
-// 	fmt.Printf("Popup displayed for: %v", renter.LastName)
-// }
+func reportsView(appState *AppState) (fyne.CanvasObject, error) {
+	log.Println("Creating the reportsView...")
 
-// func showDetailsOwnerPopup(appState *AppState, owner *OwnerDetails) {
-// 	log.Printf("Showing popup for: %v\n", owner.LastName)
-//
-// 	closeButton := widget.NewButton("Close", nil)
-//
-// 	buttonsContainer := container.NewVBox(
-// 		closeButton,
-// 	)
-//
-// 	entries, err := GetOwnerEntries(appState.db, *owner)
-// 	if err != nil {
-// 		dialog.ShowError(err, appState.window)
-// 		return
-// 	}
-//
-// 	list := widget.NewList(
-// 		func() int {
-// 			return len(entries)
-// 		},
-// 		func() fyne.CanvasObject {
-// 			log.Printf("Creating list template...")
-// 			return widget.NewLabel("Template")
-// 		},
-// 		func(lii widget.ListItemID, co fyne.CanvasObject) {
-// 			log.Printf("Updating item with ID: %d", lii)
+	owners, err := getAllOwners(appState.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var ownerOpts []string
+	for _, o := range owners {
+		ownerOpts = append(ownerOpts, fmt.Sprintf("%s %s (%d)", o.FirstName, o.LastName, o.AFM))
+	}
+	ownerSelect := widget.NewSelect(ownerOpts, nil)
+	ownerSelect.PlaceHolder = "Εκμισθωτής"
+
+	yearInput := NewFilteredEntry(`[^0-9]`, "Έτος")
+	yearInput.SetText(appState.year)
+
+	// Shared by both export buttons
+	e2Rows := func() (OwnerDetails, int, []E2Row, error) {
+		i := ownerSelect.SelectedIndex()
+		if i < 0 {
+			return OwnerDetails{}, 0, nil, fmt.Errorf("select an owner first")
+		}
+		year, err := strconv.Atoi(yearInput.Text)
+		if err != nil {
+			return OwnerDetails{}, 0, nil, fmt.Errorf("invalid year: %s", yearInput.Text)
+		}
+
+		rows, err := e2Report(appState.db, owners[i], year, appState.statuses)
+		return owners[i], year, rows, err
+	}
+
+	e2CSVButton := widget.NewButtonWithIcon("CSV", theme.DocumentSaveIcon(), func() {
+		owner, year, rows, err := e2Rows()
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		data, err := e2CSV(rows)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, fmt.Sprintf("E2-%d-%s.csv", year, owner.LastName), data)
+	})
+
+	e2PDFButton := widget.NewButtonWithIcon("PDF", theme.DocumentSaveIcon(), func() {
+		owner, year, rows, err := e2Rows()
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		data, err := e2PDF(owner, year, rows)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, fmt.Sprintf("E2-%d-%s.pdf", year, owner.LastName), data)
+	})
+
+	e2Card := widget.NewCard("Ε2", "Μισθώματα ανά εκμισθωτή για το έντυπο Ε2", container.NewVBox(
+		container.NewGridWithColumns(2, ownerSelect, yearInput),
+		container.NewGridWithColumns(2, e2CSVButton, e2PDFButton),
+	))
+
+	costYearInput := NewFilteredEntry(`[^0-9]`, "Έτος")
+	costYearInput.SetText(appState.year)
+	costRows := func() (int, []ContractCostRow, error) {
+		year, err := strconv.Atoi(costYearInput.Text)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid year: %s", costYearInput.Text)
+		}
+		rows, err := contractCostReport(appState.db, year, appState.statuses)
+		return year, rows, err
+	}
+	costCSVButton := widget.NewButtonWithIcon("CSV", theme.DocumentSaveIcon(), func() {
+		year, rows, err := costRows()
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		data, err := contractCostCSV(rows)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, fmt.Sprintf("Έξοδα-%d.csv", year), data)
+	})
+	costPDFButton := widget.NewButtonWithIcon("PDF", theme.DocumentSaveIcon(), func() {
+		year, rows, err := costRows()
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		data, err := contractCostPDF(year, rows)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, fmt.Sprintf("Έξοδα-%d.pdf", year), data)
+	})
+	costCard := widget.NewCard("Έξοδα", "Μίσθωμα, έξοδα και καθαρό κόστος ανά συμβόλαιο", container.NewVBox(
+		costYearInput,
+		container.NewGridWithColumns(2, costCSVButton, costPDFButton),
+	))
+
+	arrearsButton := widget.NewButtonWithIcon("Οφειλές", theme.ListIcon(), func() {
+		view, err := arrearsView(appState)
+		if err != nil {
+			log.Printf("error constructing arrearsView: %v\n", err)
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		appState.window.SetContent(container.NewStack(appState.bg, view))
+	})
+	ratesButton := widget.NewButtonWithIcon("Επιτόκια", theme.SettingsIcon(), func() {
+		showInterestRatesPopup(appState)
+	})
+	arrearsCard := widget.NewCard("Οφειλές", "Ληξιπρόθεσμα μισθώματα και τόκοι υπερημερίας", container.NewGridWithColumns(2, arrearsButton, ratesButton))
+
+	toleranceInput := NewFilteredEntry(`[^0-9.]`, "Ανοχή %")
+	toleranceInput.SetText(strconv.FormatFloat(areaTolerance(appState), 'f', -1, 64))
+	areaRows := func() (float64, []AreaMismatch, error) {
+		tolerance, err := strconv.ParseFloat(toleranceInput.Text, 64)
+		if err != nil || tolerance < 0 {
+			return 0, nil, fmt.Errorf("invalid tolerance: %s", toleranceInput.Text)
+		}
+		appState.app.Preferences().SetFloat("area_tolerance", tolerance)
+		mismatches, err := areaMismatchReport(appState.db, tolerance, appState.statuses)
+		return tolerance, mismatches, err
+	}
+	areaCSVButton := widget.NewButtonWithIcon("CSV", theme.DocumentSaveIcon(), func() {
+		_, mismatches, err := areaRows()
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		data, err := areaMismatchCSV(mismatches)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, "Έκταση.csv", data)
+	})
+	areaPDFButton := widget.NewButtonWithIcon("PDF", theme.DocumentSaveIcon(), func() {
+		tolerance, mismatches, err := areaRows()
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		data, err := areaMismatchPDF(tolerance, mismatches)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, "Έκταση.pdf", data)
+	})
+	areaCard := widget.NewCard("Έκταση", "Συμβόλαια με διαφορά δηλωμένης και σχεδιασμένης έκτασης", container.NewVBox(
+		toleranceInput,
+		container.NewGridWithColumns(2, areaCSVButton, areaPDFButton),
+	))
+
+	pairsCSVButton := widget.NewButtonWithIcon("CSV", theme.DocumentSaveIcon(), func() {
+		pairs, err := fieldPairsReport(appState.db, appState.statuses)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		data, err := fieldPairsCSV(pairs)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, "Γειτονικά.csv", data)
+	})
+	pairsPDFButton := widget.NewButtonWithIcon("PDF", theme.DocumentSaveIcon(), func() {
+		pairs, err := fieldPairsReport(appState.db, appState.statuses)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		data, err := fieldPairsPDF(pairs)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, "Γειτονικά.pdf", data)
+	})
+	pairsCard := widget.NewCard("Γειτονικά", "Συμβόλαια με αγροτεμάχια που επικαλύπτονται ή συνορεύουν", container.NewGridWithColumns(2, pairsCSVButton, pairsPDFButton))
+
+	statusCard := widget.NewCard("Κατάσταση", "Οι αναφορές περιλαμβάνουν μόνο τα συμβόλαια με αυτές τις καταστάσεις", newStatusFilter(appState, nil))
+
+	backButton := widget.NewButtonWithIcon("Back", theme.ContentUndoIcon(), func() {
+		tmp, err := mainView(appState)
+		if err != nil {
+			log.Printf("error constructing main layout: %v", err)
+		}
+		appState.window.SetContent(tmp)
+	})
+	if fyne.CurrentDevice().IsMobile() {
+		backButton.SetText("")
+	}
+
+	body := container.NewBorder(
+		nil,
+		container.NewHBox(layout.NewSpacer(), container.NewPadded(backButton)),
+		nil,
+		nil,
+		container.NewVScroll(container.NewPadded(container.NewVBox(statusCard, e2Card, costCard, arrearsCard, areaCard, pairsCard))),
+	)
+	log.Println("reportsView created successfully!")
+
+	return body, nil
+}
+
+// Overdue rent grouped by who owes it or by who is owed
+func arrearsView(appState *AppState) (fyne.CanvasObject, error) {
+	log.Println("Creating the arrearsView...")
+
+	asOf := time.Now()
+	var people []PersonArrears
+
+	byRenter := true
+	withInterest := false
+	reload := func() error {
+		lines, err := arrearsReport(appState.db, asOf, withInterest, appState.statuses)
+		if err != nil {
+			return err
+		}
+		people = groupArrears(lines, byRenter)
+		return nil
+	}
+	if err := reload(); err != nil {
+		return nil, err
+	}
+
+	list := widget.NewList(
+		func() int {
+			return len(people)
+		},
+		func() fyne.CanvasObject {
+			nameLabel := widget.NewLabel("Name")
+			nameLabel.TextStyle.Bold = true
+			totalLabel := widget.NewLabel("Total")
+			return container.NewBorder(nil, nil, nil, totalLabel, nameLabel)
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			if lii < 0 || lii >= len(people) {
+				return
+			}
+			p := people[lii]
+			box := co.(*fyne.Container)
+			box.Objects[0].(*widget.Label).SetText(p.Name)
+			total := fmt.Sprintf("0-30: %.2f€ | 31-90: %.2f€ | 90+: %.2f€",
+				p.Buckets[bucketUpTo30], p.Buckets[bucketUpTo90], p.Buckets[bucketOver90])
+			if withInterest {
+				total += fmt.Sprintf(" | τόκοι: %.2f€", p.Interest)
+			}
+			box.Objects[1].(*widget.Label).SetText(total)
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		if id >= 0 && id < len(people) {
+			showArrearsStatement(appState, people[id], asOf)
+		}
+		list.UnselectAll()
+	}
+
+	emptyMsg := widget.NewLabel("Δεν υπάρχουν ληξιπρόθεσμες οφειλές.")
+	emptyMsg.Alignment = fyne.TextAlignCenter
+	emptyMsg.Hidden = len(people) > 0
+
+	refresh := func() {
+		if err := reload(); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		emptyMsg.Hidden = len(people) > 0
+		emptyMsg.Refresh()
+		list.Refresh()
+	}
+
+	sideRadio := widget.NewRadioGroup([]string{"Μισθωτές", "Εκμισθωτές"}, func(s string) {
+		byRenter = s != "Εκμισθωτές"
+		refresh()
+	})
+	sideRadio.Horizontal = true
+	sideRadio.SetSelected("Μισθωτές")
+
+	interestCheck := widget.NewCheck("Τόκοι υπερημερίας", func(b bool) {
+		withInterest = b
+		refresh()
+	})
+
+	backButton := widget.NewButtonWithIcon("Back", theme.ContentUndoIcon(), func() {
+		view, err := reportsView(appState)
+		if err != nil {
+			log.Printf("error constructing reportsView: %v", err)
+			return
+		}
+		appState.window.SetContent(container.NewStack(appState.bg, view))
+	})
+	if fyne.CurrentDevice().IsMobile() {
+		backButton.SetText("")
+	}
+
+	body := container.NewBorder(
+		container.NewVBox(sideRadio, interestCheck),
+		container.NewHBox(layout.NewSpacer(), container.NewPadded(backButton)),
+		nil,
+		nil,
+		container.NewStack(list, container.NewCenter(emptyMsg)),
+	)
+	log.Println("arrearsView created successfully!")
+
+	return body, nil
+}
+
+// Statement of the overdue installments of one person
+func showArrearsStatement(appState *AppState, p PersonArrears, asOf time.Time) {
+	lines := container.NewVBox()
+	for _, r := range arrearsRecords(p) {
+		lines.Add(widget.NewLabel(strings.Join(r, "  |  ")))
+	}
+
+	fileName := fmt.Sprintf("Οφειλές-%s-%s", p.Name, asOf.Format(dateLayout))
+	csvButton := widget.NewButtonWithIcon("CSV", theme.DocumentSaveIcon(), func() {
+		data, err := arrearsCSV(p)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, fileName+".csv", data)
+	})
+	pdfButton := widget.NewButtonWithIcon("PDF", theme.DocumentSaveIcon(), func() {
+		data, err := arrearsPDF(p, asOf)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, fileName+".pdf", data)
+	})
+	closeButton := widget.NewButton("Close", nil)
+
+	title := widget.NewLabel(fmt.Sprintf("%s (Α.Φ.Μ. %d)", p.Name, p.AFM))
+	title.TextStyle.Bold = true
+
+	content := container.NewBorder(
+		title,
+		container.NewVBox(container.NewGridWithColumns(2, csvButton, pdfButton), closeButton),
+		nil,
+		nil,
+		container.NewVScroll(lines),
+	)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	closeButton.OnTapped = func() {
+		popup.Hide()
+	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.8, appState.window.Canvas().Size().Height*0.8))
+	popup.Show()
+}
+
+// The table of late payment interest rates that the arrears use
+func showInterestRatesPopup(appState *AppState) {
+	rates, err := getInterestRates(appState.db)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int {
+			return len(rates)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			button := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), nil)
+			return container.NewBorder(nil, nil, nil, button, label)
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			if lii < 0 || lii >= len(rates) {
+				return
+			}
+			r := rates[lii]
+			box := co.(*fyne.Container)
+			box.Objects[0].(*widget.Label).SetText(fmt.Sprintf("Από %s: %.2f%%", r.From, r.Rate))
+			box.Objects[1].(*widget.Button).OnTapped = func() {
+				if err := delInterestRate(appState.db, r.ID); err != nil {
+					dialog.ShowError(err, appState.window)
+					return
+				}
+				rates, err = getInterestRates(appState.db)
+				if err != nil {
+					log.Printf("Error updating the rates list: %v", err)
+				}
+				list.Refresh()
+			}
+		},
+	)
+
+	fromInput := widget.NewEntry()
+	fromInput.SetPlaceHolder("Από")
+	fromInput.Disable()
+	fromButton := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		showCalendar(fromInput, appState.window)
+	})
+	rateInput := NewFilteredEntry(`[^0-9.]`, "Επιτόκιο % ετησίως")
+
+	addButton := widget.NewButtonWithIcon("", theme.ContentAddIcon(), func() {
+		rate, err := ParseFloatToXDecimals(rateInput.Text, 3)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		if err := saveInterestRate(appState.db, InterestRate{From: fromInput.Text, Rate: rate}); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		rateInput.SetText("")
+		rates, err = getInterestRates(appState.db)
+		if err != nil {
+			log.Printf("Error updating the rates list: %v", err)
+		}
+		list.Refresh()
+	})
+
+	form := container.NewBorder(nil, nil, nil, addButton,
+		container.NewGridWithColumns(2, container.NewBorder(nil, nil, nil, fromButton, fromInput), rateInput))
+
+	closeButton := widget.NewButton("Close", nil)
+	content := container.NewBorder(widget.NewLabel("Επιτόκια υπερημερίας"), container.NewVBox(form, closeButton), nil, nil, list)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	closeButton.OnTapped = func() {
+		popup.Hide()
+	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.66))
+	popup.Show()
+}
+
+// if and when the xwidget.NumericalEntry works this will actually be useful
+func focusChain(inputs []fyne.CanvasObject, appState *AppState, scrollContainer *fyne.Container) {
+	lastInput := inputs[len(inputs)-1]
+	for i, input := range inputs {
+		switch e := input.(type) {
+		case *widget.Entry:
+			e.OnSubmitted = func(_ string) {
+				// if i < len(inputs)-1 {
+				// 	appState.window.Canvas().Focus(inputs[i+1].(fyne.Focusable))
+				//
+				// 	// TODO: Fix the scrolling when focusing on the next entry
+				// 	offSet := inputs[i+1].Position()
+				// 	scrollContainer.ScrollToOffset(offSet)
+				// } else {
+				// 	fyne.CurrentDevice().(mobile.Device).HideVirtualKeyboard()
+				// 	// e.Disable()
+				// 	// e.Enable()
+				// }
+				if input == lastInput {
+					fyne.CurrentDevice().(mobile.Device).HideVirtualKeyboard()
+					appState.window.Canvas().Unfocus()
+				} else {
+					// appState.window.Canvas().FocusNext()
+					appState.window.Canvas().Focus(inputs[i+1].(fyne.Focusable))
+				}
+			}
+		case *xwidget.NumericalEntry:
+			e.OnSubmitted = func(s string) {
+				if i < len(inputs)-1 {
+					appState.window.Canvas().Focus(inputs[i+1].(fyne.Focusable))
+				} else {
+					fyne.CurrentDevice().(mobile.Device).HideVirtualKeyboard()
+					// e.Disable()
+					// e.Enable()
+				}
+			}
+		}
+	}
+}
+
+func showOwnerDetails(appState *AppState, owner *OwnerDetails) {
+	log.Printf("Showing details for owner: %v\n", owner.ID)
+
+	editBtn := widget.NewButton("Edit", nil)
+	closeBtn := widget.NewButton("Close", nil)
+
+	buttonsContainer := container.NewGridWithColumns(2, editBtn, closeBtn)
+
+	scrollableContainer := container.NewVScroll(container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("ID:               %d", owner.ID)),
+		widget.NewLabel(fmt.Sprintf("Όνομα:            %s", owner.FirstName)),
+		widget.NewLabel(fmt.Sprintf("Επώνυμο:          %s", owner.LastName)),
+		widget.NewLabel(fmt.Sprintf("Όνομα Πατρός:     %s", owner.FathersName)),
+		widget.NewLabel(fmt.Sprintf("Α.Φ.Μ.:           %d", owner.AFM)),
+		widget.NewLabel(fmt.Sprintf("Α.Δ.Τ.:           %s", owner.ADT)),
+		// TODO: Make button to show e9 in default app
+		widget.NewLabel(fmt.Sprintf("Διεύθυνση:        %s", owner.HomeAddress)),
+		widget.NewLabel(fmt.Sprintf("Τηλέφωνο:         %s", owner.PhoneNumber)),
+		widget.NewLabel(fmt.Sprintf("e-mail:           %s", owner.Email)),
+		widget.NewLabel(fmt.Sprintf("Στοιχία Λογιστή:\n%s", owner.AccountantInfo)),
+		widget.NewLabel(fmt.Sprintf("Notes:\n          %s", owner.Notes)),
+	))
+
+	content := container.NewBorder(nil, buttonsContainer, nil, nil, scrollableContainer)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+
+	editBtn.OnTapped = func() {
+		err := editOwner(appState, owner.ID)
+		if err != nil {
+			log.Println("editOwner error: ", err)
+		}
+	}
+	closeBtn.OnTapped = func() {
+		popup.Hide()
+	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.66))
+
+	popup.Show()
+}
+
+func showRenterDetails(appState *AppState, renter *RenterDetails, list *widget.List) {
+	log.Printf("Showing details for renter: %v\n", renter.ID)
+
+	editBtn := widget.NewButton("Edit", nil)
+	closeBtn := widget.NewButton("Close", nil)
+
+	// buttonsContainer := container.NewHBox(closeBtn, layout.NewSpacer(), editBtn)
+	buttonsContainer := container.NewGridWithColumns(2, editBtn, closeBtn)
+
+	scrollableContainer := container.NewVScroll(container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("ID:           %d", renter.ID)),
+		widget.NewLabel(fmt.Sprintf("Όνομα:        %s", renter.FirstName)),
+		widget.NewLabel(fmt.Sprintf("Επώνυμο:      %s", renter.LastName)),
+		widget.NewLabel(fmt.Sprintf("Όνομα Πατρός: %s", renter.FathersName)),
+		widget.NewLabel(fmt.Sprintf("Α.Φ.Μ.:       %d", renter.AFM)),
+		widget.NewLabel(fmt.Sprintf("Α.Δ.Τ.:       %s", renter.ADT)),
+		// TODO: Make button to show e9 in default app
+		widget.NewLabel(fmt.Sprintf("Notes:\n  	   %s", renter.Notes)),
+	))
+
+	content := container.NewBorder(nil, buttonsContainer, nil, nil, scrollableContainer)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+
+	editBtn.OnTapped = func() {
+		err := editRenter(appState, renter.ID)
+		if err != nil {
+			log.Println("editRenter error: ", err)
+		}
+	}
+	closeBtn.OnTapped = func() {
+		popup.Hide()
+	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.66))
+
+	popup.Show()
+}
+
+// func showDetailsRenterPopup(appState *AppState, renter *RenterDetails) {
+// 	log.Printf("Showing popup for: %v\n", renter.LastName)
+//
+// 	closeButton := widget.NewButton("Close", nil)
+//
+// 	buttonsContainer := container.NewVBox(
+// 		closeButton,
+// 	)
+//
+// 	entries, err := GetRenterEntries(appState.db, *renter)
+// 	if err != nil {
+// 		dialog.ShowError(err, appState.window)
+// 		return
+// 	}
+//
+// 	list := widget.NewList(
+// 		func() int {
+// 			return len(entries)
+// 		},
+// 		func() fyne.CanvasObject {
+// 			log.Printf("Creating list template...")
+// 			return widget.NewLabel("Template")
+// 		},
+// 		func(lii widget.ListItemID, co fyne.CanvasObject) {
+// 			log.Printf("Updating item with ID: %d", lii)
+// 			if lii < 0 || lii >= len(entries) {
+// 				log.Printf("Invalid item ID: %d", lii)
+// 				return
+// 			}
+// 			entry := entries[lii]
+// 			label, ok := co.(*widget.Label)
+// 			if !ok {
+// 				log.Printf("Canvas object is not *widget.Label, its: %s", fmt.Sprintf("%T", co))
+// 				return
+// 			}
+// 			label.SetText(fmt.Sprintf("%d: %s", entry.ID, entry.Name))
+// 		},
+// 	)
+//
+// 	list.OnSelected = func(id widget.ListItemID) {
+// 		log.Printf("Selected item: %d\n", id)
+// 		if id >= 0 && id < len(entries) {
+// 			log.Printf("Showing popup for item: %d\n", entries[id].ID)
+// 			showDetailsPopup(entries[id], appState, list, &entries, &entries[id].Owners, &entries[id].Renters)
+// 			list.UnselectAll()
+// 		}
+// 	}
+//
+// 	scrollableList := container.NewVScroll(list)
+// 	scrollableList.SetMinSize(fyne.NewSize(appState.window.Canvas().Size().Width*0.7, appState.window.Canvas().Size().Height*0.7))
+//
+// 	content := container.NewVBox(
+// 		scrollableList,
+// 		buttonsContainer,
+// 	)
+//
+// 	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+//
+// 	closeButton.OnTapped = func() {
+// 		popup.Hide()
+// 	}
+//
+// 	popup.Show()
+// 	fmt.Printf("Popup displayed for: %v", renter.LastName)
+// }
+
+// func showDetailsOwnerPopup(appState *AppState, owner *OwnerDetails) {
+// 	log.Printf("Showing popup for: %v\n", owner.LastName)
+//
+// 	closeButton := widget.NewButton("Close", nil)
+//
+// 	buttonsContainer := container.NewVBox(
+// 		closeButton,
+// 	)
+//
+// 	entries, err := GetOwnerEntries(appState.db, *owner)
+// 	if err != nil {
+// 		dialog.ShowError(err, appState.window)
+// 		return
+// 	}
+//
+// 	list := widget.NewList(
+// 		func() int {
+// 			return len(entries)
+// 		},
+// 		func() fyne.CanvasObject {
+// 			log.Printf("Creating list template...")
+// 			return widget.NewLabel("Template")
+// 		},
+// 		func(lii widget.ListItemID, co fyne.CanvasObject) {
+// 			log.Printf("Updating item with ID: %d", lii)
 // 			if lii < 0 || lii >= len(entries) {
 // 				log.Printf("Invalid item ID: %d", lii)
 // 				return
@@ -1160,128 +2286,1634 @@ func showRenterDetails(appState *AppState, renter *RenterDetails, list *widget.L
 // 	fmt.Printf("Popup displayed for: %v", owner.LastName)
 // }
 
-// Details popup for the list
-func showDetailsPopup(entry Entry, appState *AppState, list *widget.List, entries *[]Entry, owners *[]OwnerDetails, renters *[]RenterDetails) {
-	log.Printf("Showing popup for: %d", entry.ID)
+// Details popup for the list
+func showDetailsPopup(entry Entry, appState *AppState, list *widget.List, entries *[]Entry) {
+	log.Printf("Showing popup for: %d", entry.ID)
+
+	editButton := widget.NewButton("Edit", nil)
+	closeButton := widget.NewButton("Close", nil)
+	deleteButton := widget.NewButton("Delete", nil)
+
+	buttonsContainer := container.NewVBox(
+		closeButton,
+		container.NewGridWithColumns(2, editButton, deleteButton),
+	)
+
+	coordsContainer := container.NewVBox()
+	// the ΕΓΣΑ87 ones next to the WGS84 ones when they are asked for
+	var showCoords func()
+	showCoords = func() {
+		coordsContainer.RemoveAll()
+		grid := appState.app.Preferences().Bool("coords_egsa87")
+		gridCheck := widget.NewCheck("ΕΓΣΑ87", nil)
+		gridCheck.SetChecked(grid)
+		gridCheck.OnChanged = func(checked bool) {
+			appState.app.Preferences().SetBool("coords_egsa87", checked)
+			showCoords()
+		}
+		coordsContainer.Add(container.NewBorder(nil, nil, widget.NewLabel("Συντετγμένες: "), gridCheck))
+
+		lines := shapeLines(entry.Coords)
+		var gridLines []string
+		if grid {
+			gridLines = egsa87Lines(entry.Coords)
+		}
+		for i, line := range lines {
+			if i < len(gridLines) && gridLines[i] != line {
+				line += "  (" + strings.SplitN(gridLines[i], ". ", 2)[1] + ")"
+			}
+			coordsContainer.Add(widget.NewLabel("\t" + line))
+		}
+	}
+	showCoords()
+
+	// the terms as they are today, with the amendments
+	terms := entry.AsOf(time.Now())
+
+	ownersContainer := container.NewVBox(widget.NewLabel("Εκμισθωτής/ές: "))
+	for _, o := range terms.Owners {
+		ownersContainer.Add(widget.NewLabel("\t" + o.FirstName + " " + o.LastName))
+	}
+
+	rentersContainer := container.NewVBox(widget.NewLabel("Μισθωτής/ες: "))
+	for _, r := range terms.Renters {
+		rentersContainer.Add(widget.NewLabel("\t" + r.FirstName + " " + r.LastName))
+	}
+
+	overridesContainer := container.NewVBox()
+	overrides, err := getOverrides(appState.db, entry.ID)
+	if err != nil {
+		log.Printf("Error getting the overlap overrides of %d: %v", entry.ID, err)
+	}
+	if len(overrides) > 0 {
+		overridesContainer.Add(widget.NewLabel("Αποδεκτές επικαλύψεις: "))
+		for _, o := range overrides {
+			label := widget.NewLabel(fmt.Sprintf("\t%s με #%d: %s", o.Created.Format(dateLayout), o.OtherID, o.Reason))
+			label.Wrapping = fyne.TextWrapWord
+			overridesContainer.Add(label)
+		}
+	}
+
+	shareLabel := widget.NewLabel(fmt.Sprintf("Ποσοστό σοδειάς: %s%%", strconv.FormatFloat(terms.Share, 'f', -1, 64)))
+	if !entry.Rules().Share {
+		shareLabel.Hide()
+	}
+
+	endText := fmt.Sprintf("ΕΩΣ: %s", terms.End)
+	if dateBefore(terms.End, entry.End) {
+		endText += fmt.Sprintf(" (λύση, αρχικά %s)", entry.End)
+	} else if terms.End != entry.End {
+		endText += fmt.Sprintf(" (σιωπηρή παράταση, αρχικά %s)", entry.End)
+	}
+
+	noticeText := fmt.Sprintf("Προειδοποίηση: %d μήνες, από: %s", entry.NoticeMonths, noticePartyLabels[entry.noticeParty()])
+	if deadline, ok := noticeDeadline(entry); ok {
+		noticeText += fmt.Sprintf("\nΣιωπηρή παράταση %d μηνών χωρίς καταγγελία έως %s", entry.AutoRenewMonths, deadline.Format(dateLayout))
+	}
+	var parcelLabels []string
+	for _, p := range entry.Parcels {
+		parcelLabels = append(parcelLabels, fmt.Sprintf("%s %.3f στρ.", parcelLabel(p), p.Area))
+	}
+	parcelsLabel := widget.NewLabel("Αγροτεμάχια: " + strings.Join(parcelLabels, ", "))
+	parcelsLabel.Wrapping = fyne.TextWrapWord
+
+	noticeLabel := widget.NewLabel(noticeText)
+	if entry.NoticeMonths == 0 && entry.AutoRenewMonths == 0 {
+		noticeLabel.Hide()
+	}
+
+	misthButton := widget.NewButton("ΜΙΣΘΩΤΗΡΙΟ", func() {
+		err := openFile(entry, appState)
+		if err != nil {
+			log.Println("openFile error: ", err)
+		}
+	})
+
+	documentButton := widget.NewButtonWithIcon("", theme.DocumentPrintIcon(), func() {
+		showDocumentPopup(appState, entry, func(data []byte) {
+			entry.emisth = data
+			for i := range *entries {
+				if (*entries)[i].ID == entry.ID {
+					(*entries)[i].emisth = data
+				}
+			}
+		})
+	})
+
+	paymentsButton := widget.NewButton("Πληρωμές", func() {
+		showPaymentsPopup(appState, entry)
+	})
+
+	attachmentsButton := widget.NewButton("Συνημμένα", func() {
+		showAttachmentsPopup(appState, entry)
+	})
+
+	expensesButton := widget.NewButton("Έξοδα", func() {
+		showExpensesPopup(appState, entry)
+	})
+
+	amendmentsButton := widget.NewButton(fmt.Sprintf("Τροποποιήσεις (%d)", len(entry.Amendments)), func() {
+		showAmendmentsPopup(appState, entry)
+	})
+
+	depositsLabel := widget.NewLabel("")
+	updateDepositsLabel := func() {
+		deposits, err := getDeposits(appState.db, entry.ID)
+		if err != nil {
+			log.Printf("Error getting the deposits of %d: %v", entry.ID, err)
+			return
+		}
+		if held := heldDepositsAmount(deposits); held > 0 {
+			depositsLabel.SetText(fmt.Sprintf("Εγγυήσεις σε εκκρεμότητα: %.2f€", held))
+			depositsLabel.Show()
+		} else {
+			depositsLabel.Hide()
+		}
+	}
+	updateDepositsLabel()
+
+	depositsButton := widget.NewButton("Εγγυήσεις", func() {
+		showDepositsPopup(appState, entry, updateDepositsLabel)
+	})
+
+	renewButton := widget.NewButtonWithIcon("Ανανέωση", theme.ViewRefreshIcon(), nil)
+	if entry.Status == statusRenewed || !canTransition(entry.Status, statusRenewed) {
+		renewButton.Disable()
+	}
+
+	chainContainer := container.NewVBox()
+	chain, err := getRenewalChain(appState.db, entry.ID)
+	if err != nil {
+		log.Printf("Error getting the renewal chain of %d: %v", entry.ID, err)
+	}
+	if len(chain) > 1 {
+		chainContainer.Add(widget.NewLabel("Ανανεώσεις: "))
+		for _, c := range chain {
+			label := widget.NewLabel(fmt.Sprintf("\t%d. %s (%s - %s) %s", c.ID, c.Name, c.Start, c.End, statusLabels[c.Status]))
+			label.TextStyle.Bold = c.ID == entry.ID
+			chainContainer.Add(label)
+		}
+	}
+
+	// the contract it subleases from and the ones that sublease from it
+	subleaseContainer := container.NewVBox()
+	if entry.ParentID != 0 {
+		parent, err := getEntry(appState.db, entry.ParentID)
+		if err != nil {
+			log.Printf("Error getting the parent %d of %d: %v", entry.ParentID, entry.ID, err)
+		} else {
+			subleaseContainer.Add(widget.NewLabel(fmt.Sprintf("Υπομίσθωση του: %s (%s - %s)", contractLabel(parent), parent.Start, parent.ActualEnd())))
+		}
+	}
+	all, err := getAllEntries(appState.db)
+	if err != nil {
+		log.Printf("Error getting the subleases of %d: %v", entry.ID, err)
+	}
+	if subs := subleasesOf(entry.ID, all); len(subs) > 0 {
+		subleaseContainer.Add(widget.NewLabel("Υπομισθώσεις: "))
+		for _, sub := range subs {
+			subTerms := sub.AsOf(time.Now())
+			subleaseContainer.Add(widget.NewLabel(fmt.Sprintf("\t%s (%s - %s) %.3f στρ. %.2f€ %s",
+				contractLabel(sub), sub.Start, sub.ActualEnd(), subTerms.Size, subTerms.Rent, statusLabels[sub.Status])))
+		}
+
+		payments, err := getAllPayments(appState.db)
+		if err != nil {
+			log.Printf("Error getting the payments for the rent position of %d: %v", entry.ID, err)
+		}
+		position := rentPosition(entry, subs, payments, time.Now())
+		subleaseContainer.Add(widget.NewLabel(fmt.Sprintf("Ετήσιο μίσθωμα: πληρώνουμε %.2f€, εισπράττουμε %.2f€, καθαρό %.2f€",
+			position.RentDue, position.RentIncome, position.NetRent())))
+		subleaseContainer.Add(widget.NewLabel(fmt.Sprintf("Πληρωμές: πληρώθηκαν %.2f€, εισπράχθηκαν %.2f€, καθαρό %.2f€",
+			position.Paid, position.Received, position.Net())))
+	}
+
+	statusBadge := newStatusBadge()
+	setStatusBadge(statusBadge, entry.Status)
+	statusButton := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil)
+	if len(manualTransitions(entry.Status)) == 0 {
+		statusButton.Disable()
+	}
+	statusButton.OnTapped = func() {
+		var opts []string
+		for _, st := range manualTransitions(entry.Status) {
+			opts = append(opts, statusLabels[st])
+		}
+		statusSelect := widget.NewSelect(opts, nil)
+		items := []*widget.FormItem{widget.NewFormItem("Νέα κατάσταση", statusSelect)}
+		dialog.ShowForm("Κατάσταση συμβολαίου", "OK", "Cancel", items, func(b bool) {
+			i := statusSelect.SelectedIndex()
+			if !b || i < 0 {
+				return
+			}
+			if err := setEntryStatus(appState.db, entry.ID, manualTransitions(entry.Status)[i]); err != nil {
+				dialog.ShowError(err, appState.window)
+				return
+			}
+			updated, err := getEntry(appState.db, entry.ID)
+			if err != nil {
+				log.Printf("Error reloading entry %d: %v", entry.ID, err)
+				return
+			}
+			entry.Status = updated.Status
+			setStatusBadge(statusBadge, entry.Status)
+			if len(manualTransitions(entry.Status)) == 0 {
+				statusButton.Disable()
+			}
+			for i := range *entries {
+				if (*entries)[i].ID == entry.ID {
+					(*entries)[i].Status = entry.Status
+				}
+			}
+			if list != nil {
+				list.Refresh()
+			}
+		}, appState.window)
+	}
+
+	sizeText := fmt.Sprintf("Στρέμματα: %.3f", terms.Size)
+	if drawn := drawnSize(entry.Coords); drawn > 0 {
+		sizeText += fmt.Sprintf(" (σχέδιο: %.3f)", drawn)
+	}
+
+	// Add all the details!
+	scrollableContainer := container.NewVScroll(
+		container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("Αριθμός: %s (ID: %d)", entry.Number, entry.ID)),
+			widget.NewLabel(entry.Name),
+			container.NewHBox(widget.NewLabel("Κατάσταση:"), statusBadge, statusButton),
+			ownersContainer,
+			rentersContainer,
+			widget.NewLabel(fmt.Sprintf("Είδος σύμβασης: %s", contractTypeLabel(entry.ContractType))),
+			shareLabel,
+			widget.NewLabel(fmt.Sprintf("Μίσθωμα: %.2f€", terms.Rent)),
+			widget.NewLabel(fmt.Sprintf("ΑΠΟ: %s", entry.Start)),
+			widget.NewLabel(endText),
+			noticeLabel,
+			widget.NewLabel(fmt.Sprintf("Είδος Καλ/γειας: %s", entry.Type)),
+			widget.NewLabel(sizeText),
+			parcelsLabel,
+			depositsLabel,
+			chainContainer,
+			subleaseContainer,
+			overridesContainer,
+			container.NewBorder(nil, nil, nil, documentButton, misthButton),
+			container.NewGridWithColumns(2, paymentsButton, expensesButton),
+			container.NewGridWithColumns(2, depositsButton, attachmentsButton),
+			container.NewGridWithColumns(2, amendmentsButton, renewButton),
+			layout.NewSpacer(),
+			coordsContainer,
+		),
+	)
+	content := container.NewBorder(nil, buttonsContainer, nil, nil, scrollableContainer)
+
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	editButton.OnTapped = func() {
+		editForm, err := editForm(appState, entry.ID)
+		if err != nil {
+			log.Printf("error creating desktopEditForm for %d: %v", entry.ID, err)
+		}
+		appState.window.SetContent(container.NewStack(appState.bg, editForm))
+		popup.Hide()
+	}
+	closeButton.OnTapped = func() {
+		popup.Hide()
+	}
+	renewButton.OnTapped = func() {
+		showRenewForm(appState, entry, func() {
+			popup.Hide()
+			view, err := contractView(appState)
+			if err != nil {
+				log.Printf("error constructing contractView: %v", err)
+				return
+			}
+			appState.window.SetContent(container.NewStack(appState.bg, view))
+		})
+	}
+	deleteButton.OnTapped = func() {
+		dlg := dialog.NewConfirm("Επιβεβαίωση Διαγραφής", "Είσαι σίγουρος;", func(b bool) {
+			if b {
+				err := delEntry(appState.db, entry.ID)
+				if err != nil {
+					dialog.ShowError(err, appState.window)
+				}
+				dialog.ShowInformation("Deleted", fmt.Sprintf("Deleted entry %d!", entry.ID), appState.window)
+				if err != nil {
+					dialog.ShowError(err, appState.window)
+				}
+				// Need to do all that to update the list in the mainView after a deletion
+				*entries, err = getAllEntries(appState.db)
+				if err != nil {
+					log.Printf("Error updating the list: %v", err)
+				}
+				if list != nil {
+					list.Refresh()
+				}
+				popup.Hide()
+			}
+		}, appState.window)
+		dlg.Show()
+	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.8, appState.window.Canvas().Size().Height*0.8))
+
+	popup.Show()
+	fmt.Printf("Popup displayed for: %d", entry.ID)
+}
+
+// Proposes the renewal of the contract and saves it after the dates and the
+// rent are confirmed, onSaved runs after the renewal is saved
+func showRenewForm(appState *AppState, entry Entry, onSaved func()) {
+	prefs := appState.app.Preferences()
+	escalation := prefs.FloatWithFallback("renewal_escalation", 0)
+
+	proposal, err := proposeRenewal(entry, escalation)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+
+	startInput := widget.NewEntry()
+	startInput.SetText(proposal.Start)
+	startInput.Disable()
+	startButton := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		showCalendar(startInput, appState.window)
+	})
+	endInput := widget.NewEntry()
+	endInput.SetText(proposal.End)
+	endInput.Disable()
+	endButton := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		showCalendar(endInput, appState.window)
+	})
+
+	rentInput := NewFilteredEntry(`[^0-9.]`, "Μίσθωμα")
+	rentInput.SetText(fmt.Sprintf("%.2f", proposal.Rent))
+	escalationInput := NewFilteredEntry(`[^0-9.]`, "Αύξηση %")
+	escalationInput.SetText(strconv.FormatFloat(escalation, 'f', -1, 64))
+	// after the filter of the entry, which may have changed the text
+	filter := escalationInput.OnChanged
+	escalationInput.OnChanged = func(s string) {
+		filter(s)
+		if v, err := strconv.ParseFloat(escalationInput.Text, 64); err == nil {
+			escalation = v
+			if p, err := proposeRenewal(entry, v); err == nil {
+				rentInput.SetText(fmt.Sprintf("%.2f", p.Rent))
+			}
+		}
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("ΑΠΟ", container.NewBorder(nil, nil, nil, startButton, startInput)),
+		widget.NewFormItem("ΕΩΣ", container.NewBorder(nil, nil, nil, endButton, endInput)),
+		widget.NewFormItem("Αύξηση %", escalationInput),
+		widget.NewFormItem("Μίσθωμα", rentInput),
+	}
+
+	dlg := dialog.NewForm(fmt.Sprintf("Ανανέωση: %s", entry.Name), "Αποθήκευση", "Cancel", items, func(b bool) {
+		if !b {
+			return
+		}
+
+		rent, err := ParseFloatToXDecimals(rentInput.Text, 2)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		proposal.Start = startInput.Text
+		proposal.End = endInput.Text
+		proposal.Rent = rent
+		proposal.Timestamp = time.Now()
+		proposal.Status = ""
+		if !checkSublease(appState, proposal) {
+			return
+		}
+
+		id, err := renewEntry(appState.db, entry, proposal, numberingScheme(appState))
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		prefs.SetFloat("renewal_escalation", escalation)
+		log.Printf("Entry %d renewed as %d", entry.ID, id)
+
+		dialog.ShowInformation("Database:", "Saved successfully!", appState.window)
+		onSaved()
+	}, appState.window)
+	dlg.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.5, dlg.MinSize().Height))
+	dlg.Show()
+}
+
+// Payments of a contract and a small form to record new ones
+func showPaymentsPopup(appState *AppState, entry Entry) {
+	log.Printf("Showing payments for: %d", entry.ID)
+
+	payments, err := getPayments(appState.db, entry.ID)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+
+	// the payments list may have owners from before a party change
+	allOwners, _ := entry.PartiesDuring(time.Time{}, time.Now().AddDate(100, 0, 0))
+	current := entry.AsOf(time.Now())
+
+	ownerName := func(id uint) string {
+		for _, o := range allOwners {
+			if o.ID == id {
+				return o.FirstName + " " + o.LastName
+			}
+		}
+		return "Όλοι οι εκμισθωτές"
+	}
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int {
+			return len(payments)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			receiptButton := widget.NewButtonWithIcon("", theme.DocumentPrintIcon(), nil)
+			button := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), nil)
+			return container.NewBorder(nil, nil, nil, container.NewHBox(receiptButton, button), label)
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			if lii < 0 || lii >= len(payments) {
+				return
+			}
+			p := payments[lii]
+			box := co.(*fyne.Container)
+			label := box.Objects[0].(*widget.Label)
+			buttons := box.Objects[1].(*fyne.Container)
+			receiptButton := buttons.Objects[0].(*widget.Button)
+			button := buttons.Objects[1].(*widget.Button)
+
+			label.SetText(fmt.Sprintf("%s  %.2f€  %s", p.Date, p.Amount, ownerName(p.OwnerID)))
+			receiptButton.OnTapped = func() {
+				showReceipt(appState, entry, p)
+			}
+			button.OnTapped = func() {
+				dialog.ShowConfirm("Επιβεβαίωση Διαγραφής", "Είσαι σίγουρος;", func(b bool) {
+					if !b {
+						return
+					}
+					if err := delPayment(appState.db, p.ID); err != nil {
+						dialog.ShowError(err, appState.window)
+						return
+					}
+					payments, err = getPayments(appState.db, entry.ID)
+					if err != nil {
+						log.Printf("Error updating the payments list: %v", err)
+					}
+					list.Refresh()
+				}, appState.window)
+			}
+		},
+	)
+
+	dateInput := widget.NewEntry()
+	dateInput.SetPlaceHolder("Ημερομηνία")
+	dateInput.SetText(time.Now().Format(dateLayout))
+	dateInput.Disable()
+	dateButton := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		showCalendar(dateInput, appState.window)
+	})
+
+	amount := NewFilteredEntry(`[^0-9.]`, "Ποσό")
+	notes := newEntryWithLabel("Σημειώσεις")
+
+	ownerOpts := []string{"Όλοι οι εκμισθωτές"}
+	for _, o := range current.Owners {
+		ownerOpts = append(ownerOpts, o.FirstName+" "+o.LastName)
+	}
+	ownerSelect := widget.NewSelect(ownerOpts, nil)
+	ownerSelect.SetSelectedIndex(0)
+
+	var renterOpts []string
+	for _, r := range current.Renters {
+		renterOpts = append(renterOpts, r.FirstName+" "+r.LastName)
+	}
+	renterSelect := widget.NewSelect(renterOpts, nil)
+	renterSelect.PlaceHolder = "Μισθωτής"
+	if len(renterOpts) > 0 {
+		renterSelect.SetSelectedIndex(0)
+	}
+
+	addButton := widget.NewButtonWithIcon("Καταχώρηση", theme.ContentAddIcon(), func() {
+		value, err := ParseFloatToXDecimals(amount.Text, 2)
+		if err != nil || value <= 0 {
+			dialog.ShowError(fmt.Errorf("invalid amount: %s", amount.Text), appState.window)
+			return
+		}
+
+		p := Payment{
+			EntryID: entry.ID,
+			Date:    dateInput.Text,
+			Amount:  value,
+			Notes:   notes.Text,
+		}
+		if i := ownerSelect.SelectedIndex(); i > 0 {
+			p.OwnerID = current.Owners[i-1].ID
+		}
+		if i := renterSelect.SelectedIndex(); i >= 0 {
+			p.RenterID = current.Renters[i].ID
+		}
+
+		if err := savePayment(appState.db, p); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		log.Printf("Saved payment of %.2f for entry %d", p.Amount, entry.ID)
+
+		amount.SetText("")
+		notes.SetText("")
+		payments, err = getPayments(appState.db, entry.ID)
+		if err != nil {
+			log.Printf("Error updating the payments list: %v", err)
+		}
+		list.Refresh()
+	})
+
+	form := container.NewVBox(
+		container.NewBorder(nil, nil, nil, dateButton, dateInput),
+		container.NewGridWithColumns(2, amount, ownerSelect),
+		renterSelect,
+		notes,
+		addButton,
+	)
+
+	closeButton := widget.NewButton("Close", nil)
+	title := widget.NewLabel(fmt.Sprintf("Πληρωμές: %s", entry.Name))
+	title.TextStyle.Bold = true
+
+	content := container.NewBorder(title, container.NewVBox(form, closeButton), nil, nil, list)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	closeButton.OnTapped = func() {
+		popup.Hide()
+	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.8, appState.window.Canvas().Size().Height*0.8))
+	popup.Show()
+}
+
+// Opens the receipt of the payment, issuing it first if there isn't one
+func showReceipt(appState *AppState, entry Entry, p Payment) {
+	r, ok, err := getReceiptForPayment(appState.db, p.ID)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+	if ok {
+		a, err := getAttachment(appState.db, r.AttachmentID)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("cannot find receipt %s: %v", receiptName(r), err), appState.window)
+			return
+		}
+		if err := openBlob(appState, a.Name, a.Data); err != nil {
+			log.Println("openBlob error: ", err)
+		}
+		return
+	}
+
+	prefs := appState.app.Preferences()
+	series := newEntryWithLabel("Σειρά")
+	series.SetText(prefs.StringWithFallback("receipt_series", "Α"))
+
+	dialog.ShowForm("Έκδοση Απόδειξης", "Έκδοση", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Σειρά:", series),
+	}, func(b bool) {
+		if !b {
+			return
+		}
+		s := strings.TrimSpace(series.Text)
+		if s == "" {
+			dialog.ShowError(fmt.Errorf("the receipt needs a series"), appState.window)
+			return
+		}
+
+		r, err := createReceipt(appState.db, p, s, time.Now().Format(dateLayout), func(r Receipt) ([]byte, error) {
+			return receiptPDF(r, p, entry)
+		})
+		if err != nil {
+			log.Printf("Error creating receipt: %v", err)
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		prefs.SetString("receipt_series", s)
+		log.Printf("Issued receipt %s for payment %d", receiptName(r), p.ID)
+
+		a, err := getAttachment(appState.db, r.AttachmentID)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		if err := openBlob(appState, a.Name, a.Data); err != nil {
+			log.Println("openBlob error: ", err)
+		}
+	}, appState.window)
+}
+
+// Expenses of a contract and a form to record new ones
+func showExpensesPopup(appState *AppState, entry Entry) {
+	log.Printf("Showing expenses for: %d", entry.ID)
+	var selectedFileBytes []byte
+
+	expenses, err := getExpenses(appState.db, entry.ID)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int {
+			return len(expenses)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			fileButton := widget.NewButtonWithIcon("", theme.FileIcon(), nil)
+			button := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), nil)
+			return container.NewBorder(nil, nil, nil, container.NewHBox(fileButton, button), label)
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			if lii < 0 || lii >= len(expenses) {
+				return
+			}
+			x := expenses[lii]
+			box := co.(*fyne.Container)
+			label := box.Objects[0].(*widget.Label)
+			buttons := box.Objects[1].(*fyne.Container)
+			fileButton := buttons.Objects[0].(*widget.Button)
+			button := buttons.Objects[1].(*widget.Button)
+
+			label.SetText(fmt.Sprintf("%s  %s  %.2f€  (%s)", x.Date, x.Category, x.Amount, bearerLabels[x.Bearer]))
+			if len(x.Attachment) == 0 {
+				fileButton.Disable()
+			} else {
+				fileButton.Enable()
+			}
+			fileButton.OnTapped = func() {
+				if err := openBlob(appState, x.Category, x.Attachment); err != nil {
+					log.Println("openBlob error: ", err)
+				}
+			}
+			button.OnTapped = func() {
+				dialog.ShowConfirm("Επιβεβαίωση Διαγραφής", "Είσαι σίγουρος;", func(b bool) {
+					if !b {
+						return
+					}
+					if err := delExpense(appState.db, x.ID); err != nil {
+						dialog.ShowError(err, appState.window)
+						return
+					}
+					expenses, err = getExpenses(appState.db, entry.ID)
+					if err != nil {
+						log.Printf("Error updating the expenses list: %v", err)
+					}
+					list.Refresh()
+				}, appState.window)
+			}
+		},
+	)
+
+	dateInput := widget.NewEntry()
+	dateInput.SetPlaceHolder("Ημερομηνία")
+	dateInput.SetText(time.Now().Format(dateLayout))
+	dateInput.Disable()
+	dateButton := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		showCalendar(dateInput, appState.window)
+	})
+
+	categorySelect := widget.NewSelect(expenseCategories, nil)
+	categorySelect.PlaceHolder = "Κατηγορία"
+	amount := NewFilteredEntry(`[^0-9.]`, "Ποσό")
+	notes := newEntryWithLabel("Σημειώσεις")
+
+	bearerRadio := widget.NewRadioGroup([]string{bearerLabels[bearerOwner], bearerLabels[bearerRenter]}, nil)
+	bearerRadio.Horizontal = true
+	bearerRadio.SetSelected(bearerLabels[bearerOwner])
+
+	current := entry.AsOf(time.Now())
+	ownerOpts := []string{"-"}
+	for _, o := range current.Owners {
+		ownerOpts = append(ownerOpts, o.FirstName+" "+o.LastName)
+	}
+	ownerSelect := widget.NewSelect(ownerOpts, nil)
+	ownerSelect.SetSelectedIndex(0)
+
+	fileButton := widget.NewButtonWithIcon("Παραστατικό", theme.FileIcon(), nil)
+	fileButton.OnTapped = func() {
+		dlg := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer func() {
+				if err := reader.Close(); err != nil {
+					log.Println("reader.Close() error: ", err)
+				}
+			}()
+
+			selectedFileBytes, err = io.ReadAll(reader)
+			if err != nil {
+				dialog.ShowError(err, appState.window)
+				return
+			}
+			fileButton.SetText(reader.URI().Name())
+		}, appState.window)
+
+		dlg.SetFilter(storage.NewExtensionFileFilter([]string{".jpg", ".png", ".pdf"}))
+		dlg.Show()
+	}
+
+	addButton := widget.NewButtonWithIcon("Καταχώρηση", theme.ContentAddIcon(), func() {
+		if categorySelect.Selected == "" {
+			dialog.ShowError(fmt.Errorf("select a category"), appState.window)
+			return
+		}
+		value, err := ParseFloatToXDecimals(amount.Text, 2)
+		if err != nil || value <= 0 {
+			dialog.ShowError(fmt.Errorf("invalid amount: %s", amount.Text), appState.window)
+			return
+		}
+
+		x := Expense{
+			EntryID:    entry.ID,
+			Category:   categorySelect.Selected,
+			Date:       dateInput.Text,
+			Amount:     value,
+			Bearer:     bearerOwner,
+			Notes:      notes.Text,
+			Attachment: selectedFileBytes,
+		}
+		if bearerRadio.Selected == bearerLabels[bearerRenter] {
+			x.Bearer = bearerRenter
+		}
+		if i := ownerSelect.SelectedIndex(); i > 0 {
+			x.OwnerID = current.Owners[i-1].ID
+		}
+
+		if err := saveExpense(appState.db, x); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		log.Printf("Saved expense of %.2f for entry %d", x.Amount, entry.ID)
+
+		amount.SetText("")
+		notes.SetText("")
+		selectedFileBytes = nil
+		fileButton.SetText("Παραστατικό")
+		expenses, err = getExpenses(appState.db, entry.ID)
+		if err != nil {
+			log.Printf("Error updating the expenses list: %v", err)
+		}
+		list.Refresh()
+	})
+
+	form := container.NewVBox(
+		container.NewGridWithColumns(2, container.NewBorder(nil, nil, nil, dateButton, dateInput), categorySelect),
+		container.NewGridWithColumns(2, amount, ownerSelect),
+		bearerRadio,
+		container.NewBorder(nil, nil, nil, fileButton, notes),
+		addButton,
+	)
+
+	closeButton := widget.NewButton("Close", nil)
+	title := widget.NewLabel(fmt.Sprintf("Έξοδα: %s", entry.Name))
+	title.TextStyle.Bold = true
+
+	content := container.NewBorder(title, container.NewVBox(form, closeButton), nil, nil, list)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	closeButton.OnTapped = func() {
+		popup.Hide()
+	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.8, appState.window.Canvas().Size().Height*0.8))
+	popup.Show()
+}
+
+// Checks a sublease against its parent, shows what doesn't fit and returns false
+func checkSublease(appState *AppState, e Entry) bool {
+	if e.ParentID == 0 {
+		return true
+	}
+
+	entries, err := getAllEntries(appState.db)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return false
+	}
+	for _, parent := range entries {
+		if parent.ID != e.ParentID {
+			continue
+		}
+		if err := validateSublease(e, parent, entries); err != nil {
+			log.Printf("Invalid sublease of %d: %v", e.ParentID, err)
+			dialog.ShowError(err, appState.window)
+			return false
+		}
+		return true
+	}
+
+	dialog.ShowError(fmt.Errorf("cannot find the parent contract %d", e.ParentID), appState.window)
+	return false
+}
+
+// Looks for other contracts of the same land over the same period before a
+// save. With conflicts the save only goes on with a reason, which is kept
+// with the entry for every conflicting contract. The ones accepted on an
+// earlier save aren't asked for again.
+func checkOverlaps(appState *AppState, e Entry, onSave func(Entry)) {
+	entries, err := getAllEntries(appState.db)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+	overrides := append([]OverlapOverride(nil), e.Overrides...)
+	if e.ID != 0 {
+		saved, err := getOverrides(appState.db, e.ID)
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		overrides = append(overrides, saved...)
+	}
+
+	conflicts := unacceptedConflicts(findOverlaps(e, entries), overrides)
+	if len(conflicts) == 0 {
+		onSave(e)
+		return
+	}
+	log.Printf("Entry %s overlaps %d contracts", e.Name, len(conflicts))
+
+	conflictsContainer := container.NewVBox()
+	for _, c := range conflicts {
+		label := widget.NewLabel("• " + c.String())
+		label.Wrapping = fyne.TextWrapWord
+		conflictsContainer.Add(label)
+	}
+
+	reason := widget.NewMultiLineEntry()
+	reason.SetPlaceHolder("Αιτιολογία (υποχρεωτική)")
+	reason.Wrapping = fyne.TextWrapWord
+
+	var dlg *dialog.CustomDialog
+	saveButton := widget.NewButton("Αποθήκευση παρ' όλα αυτά", func() {
+		dlg.Hide()
+		for _, c := range conflicts {
+			e.Overrides = append(e.Overrides, OverlapOverride{OtherID: c.Entry.ID, Reason: strings.TrimSpace(reason.Text)})
+		}
+		onSave(e)
+	})
+	saveButton.Importance = widget.DangerImportance
+	saveButton.Disable()
+	reason.OnChanged = func(s string) {
+		if strings.TrimSpace(s) == "" {
+			saveButton.Disable()
+		} else {
+			saveButton.Enable()
+		}
+	}
+	cancelButton := widget.NewButton("Cancel", func() {
+		dlg.Hide()
+	})
+
+	content := container.NewBorder(
+		widget.NewLabel("Υπάρχουν άλλα συμβόλαια για την ίδια γη την ίδια περίοδο:"),
+		reason,
+		nil,
+		nil,
+		container.NewVScroll(conflictsContainer),
+	)
+	dlg = dialog.NewCustomWithoutButtons("Επικάλυψη μισθώσεων", content, appState.window)
+	dlg.SetButtons([]fyne.CanvasObject{cancelButton, saveButton})
+	dlg.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.6, appState.window.Canvas().Size().Height*0.6))
+	dlg.Show()
+}
+
+// Asks if a change of the rent, the area or the parties applies from a date
+// on (an amendment) or fixes a mistake of the original contract. Corrections
+// are only offered while the contract has no amendments.
+func showAmendOrCorrect(appState *AppState, canCorrect bool, onAmend func(effective string), onCorrect func()) {
+	dateInput := widget.NewEntry()
+	dateInput.SetText(time.Now().Format(dateLayout))
+	dateInput.Disable()
+	dateButton := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		showCalendar(dateInput, appState.window)
+	})
+
+	var dlg *dialog.CustomDialog
+	amendButton := widget.NewButton("Τροποποίηση", func() {
+		dlg.Hide()
+		onAmend(dateInput.Text)
+	})
+	amendButton.Importance = widget.HighImportance
+	correctButton := widget.NewButton("Διόρθωση", func() {
+		dlg.Hide()
+		onCorrect()
+	})
+	if !canCorrect {
+		correctButton.Disable()
+	}
+	cancelButton := widget.NewButton("Cancel", func() {
+		dlg.Hide()
+	})
+
+	content := container.NewVBox(
+		widget.NewLabel("Άλλαξε το μίσθωμα, η έκταση ή τα πρόσωπα.\nΤροποποίηση από την ημερομηνία ή διόρθωση του αρχικού συμβολαίου;"),
+		container.NewBorder(nil, nil, widget.NewLabel("Ισχύει από"), dateButton, dateInput),
+	)
+	dlg = dialog.NewCustomWithoutButtons("Τροποποίηση", content, appState.window)
+	dlg.SetButtons([]fyne.CanvasObject{cancelButton, correctButton, amendButton})
+	dlg.Show()
+}
+
+// Amendments of a contract and a form for the rent, area and termination
+// ones. Party changes are made from the edit form.
+func showAmendmentsPopup(appState *AppState, entry Entry) {
+	log.Printf("Showing amendments for: %d", entry.ID)
+	var selectedFileBytes []byte
+
+	amendments, err := getAmendments(appState.db, entry.ID)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+
+	describe := func(a Amendment) string {
+		text := fmt.Sprintf("%s  %s", a.Effective, amendmentLabels[a.Kind])
+		switch a.Kind {
+		case amendRent:
+			text += fmt.Sprintf(": %.2f€", a.Rent)
+		case amendArea:
+			text += fmt.Sprintf(": %.3f στρ.", a.Size)
+		case amendParties:
+			var names []string
+			names = append(names, ownerNames(a.Owners)...)
+			names = append(names, renterNames(a.Renters)...)
+			text += ": " + strings.Join(names, ", ")
+		case amendExtension:
+			text += ": έως " + a.End
+		}
+		if a.Notes != "" {
+			text += " (" + a.Notes + ")"
+		}
+		return text
+	}
+
+	var list *widget.List
+	refresh := func() {
+		amendments, err = getAmendments(appState.db, entry.ID)
+		if err != nil {
+			log.Printf("Error updating the amendments list: %v", err)
+		}
+		if err := refreshStatuses(appState.db, time.Now()); err != nil {
+			log.Printf("Error refreshing the contract statuses: %v", err)
+		}
+		list.Refresh()
+	}
+
+	list = widget.NewList(
+		func() int {
+			return len(amendments)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			docButton := widget.NewButtonWithIcon("", theme.FileIcon(), nil)
+			button := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), nil)
+			return container.NewBorder(nil, nil, nil, container.NewHBox(docButton, button), label)
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			if lii < 0 || lii >= len(amendments) {
+				return
+			}
+			a := amendments[lii]
+			box := co.(*fyne.Container)
+			label := box.Objects[0].(*widget.Label)
+			buttons := box.Objects[1].(*fyne.Container)
+			docButton := buttons.Objects[0].(*widget.Button)
+			button := buttons.Objects[1].(*widget.Button)
+
+			label.SetText(describe(a))
+			if len(a.Document) > 0 {
+				docButton.SetIcon(theme.FileIcon())
+			} else {
+				docButton.SetIcon(theme.UploadIcon())
+			}
+			docButton.OnTapped = func() {
+				if len(a.Document) > 0 {
+					if err := openBlob(appState, amendmentLabels[a.Kind]+" "+a.Effective, a.Document); err != nil {
+						log.Println("openBlob error: ", err)
+					}
+					return
+				}
+
+				dlg := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+					if err != nil || reader == nil {
+						return
+					}
+					defer func() {
+						if err := reader.Close(); err != nil {
+							log.Println("reader.Close() error: ", err)
+						}
+					}()
+
+					data, err := io.ReadAll(reader)
+					if err != nil {
+						dialog.ShowError(err, appState.window)
+						return
+					}
+					if err := setAmendmentDocument(appState.db, a.ID, data); err != nil {
+						dialog.ShowError(err, appState.window)
+						return
+					}
+					refresh()
+				}, appState.window)
+				dlg.SetFilter(storage.NewExtensionFileFilter([]string{".jpg", ".png", ".pdf"}))
+				dlg.Show()
+			}
+			button.OnTapped = func() {
+				dialog.ShowConfirm("Επιβεβαίωση Διαγραφής", "Είσαι σίγουρος;", func(b bool) {
+					if !b {
+						return
+					}
+					if err := delAmendment(appState.db, a.ID); err != nil {
+						dialog.ShowError(err, appState.window)
+						return
+					}
+					refresh()
+				}, appState.window)
+			}
+		},
+	)
+
+	kinds := []string{amendRent, amendArea, amendTermination}
+	var kindOpts []string
+	for _, k := range kinds {
+		kindOpts = append(kindOpts, amendmentLabels[k])
+	}
+	value := NewFilteredEntry(`[^0-9.]`, "Νέο μίσθωμα")
+	kindSelect := widget.NewSelect(kindOpts, func(s string) {
+		switch s {
+		case amendmentLabels[amendRent]:
+			value.SetPlaceHolder("Νέο μίσθωμα")
+			value.Show()
+		case amendmentLabels[amendArea]:
+			value.SetPlaceHolder("Νέα στρέμματα")
+			value.Show()
+		default:
+			value.Hide()
+		}
+	})
+	kindSelect.SetSelectedIndex(0)
+
+	dateInput := widget.NewEntry()
+	dateInput.SetPlaceHolder("Ισχύει από")
+	dateInput.SetText(time.Now().Format(dateLayout))
+	dateInput.Disable()
+	dateButton := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		showCalendar(dateInput, appState.window)
+	})
 
-	editButton := widget.NewButton("Edit", nil)
-	closeButton := widget.NewButton("Close", nil)
-	deleteButton := widget.NewButton("Delete", nil)
+	notes := newEntryWithLabel("Σημειώσεις")
 
-	buttonsContainer := container.NewVBox(
-		closeButton,
-		container.NewGridWithColumns(2, editButton, deleteButton),
-	)
+	fileButton := widget.NewButtonWithIcon("Έγγραφο", theme.FileIcon(), nil)
+	fileButton.OnTapped = func() {
+		dlg := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer func() {
+				if err := reader.Close(); err != nil {
+					log.Println("reader.Close() error: ", err)
+				}
+			}()
+
+			selectedFileBytes, err = io.ReadAll(reader)
+			if err != nil {
+				dialog.ShowError(err, appState.window)
+				return
+			}
+			fileButton.SetText(reader.URI().Name())
+		}, appState.window)
 
-	coordsContainer := container.NewVBox(widget.NewLabel("Συντετγμένες: "))
-	for i, c := range entry.Coords {
-		str := fmt.Sprintf("\t%d Lat.: %f Long.: %f", i+1, c.Latitude, c.Longitude)
-		coordsContainer.Add(widget.NewLabel(str))
+		dlg.SetFilter(storage.NewExtensionFileFilter([]string{".jpg", ".png", ".pdf"}))
+		dlg.Show()
 	}
 
-	ownersContainer := container.NewVBox(widget.NewLabel("Εκμισθωτής/ές: "))
-	for _, o := range *owners {
-		ownersContainer.Add(widget.NewLabel("\t" + o.FirstName + " " + o.LastName))
+	addButton := widget.NewButtonWithIcon("Καταχώρηση", theme.ContentAddIcon(), func() {
+		a := Amendment{
+			EntryID:   entry.ID,
+			Kind:      kinds[kindSelect.SelectedIndex()],
+			Effective: dateInput.Text,
+			Document:  selectedFileBytes,
+			Notes:     notes.Text,
+		}
+
+		if a.Kind != amendTermination {
+			v, err := ParseFloatToXDecimals(value.Text, 3)
+			if err != nil || v <= 0 {
+				dialog.ShowError(fmt.Errorf("invalid value: %s", value.Text), appState.window)
+				return
+			}
+			if a.Kind == amendRent {
+				a.Rent = TruncateFloatTo2Decimals(v)
+			} else {
+				a.Size = v
+			}
+		} else if !dateBefore(a.Effective, entry.End) {
+			dialog.ShowError(fmt.Errorf("the termination must be before the end date (%s)", entry.End), appState.window)
+			return
+		}
+
+		if err := saveAmendment(appState.db, a); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		log.Printf("Saved %s amendment for entry %d", a.Kind, entry.ID)
+
+		value.SetText("")
+		notes.SetText("")
+		selectedFileBytes = nil
+		fileButton.SetText("Έγγραφο")
+		refresh()
+	})
+
+	form := container.NewVBox(
+		container.NewGridWithColumns(2, kindSelect, container.NewBorder(nil, nil, nil, dateButton, dateInput)),
+		value,
+		container.NewBorder(nil, nil, nil, fileButton, notes),
+		addButton,
+	)
+
+	closeButton := widget.NewButton("Close", nil)
+	title := widget.NewLabel(fmt.Sprintf("Τροποποιήσεις: %s", entry.Name))
+	title.TextStyle.Bold = true
+	hint := widget.NewLabel("Οι αλλαγές προσώπων γίνονται από την επεξεργασία του συμβολαίου.")
+	hint.TextStyle.Italic = true
+
+	content := container.NewBorder(container.NewVBox(title, hint), container.NewVBox(form, closeButton), nil, nil, list)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	closeButton.OnTapped = func() {
+		popup.Hide()
 	}
 
-	rentersContainer := container.NewVBox(widget.NewLabel("Μισθωτής/ες: "))
-	for _, r := range *renters {
-		rentersContainer.Add(widget.NewLabel("\t" + r.FirstName + " " + r.LastName))
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.8, appState.window.Canvas().Size().Height*0.8))
+	popup.Show()
+}
+
+// Deposits and guarantees of a contract, onChange runs after every change
+// so the details can show the amount that is still held
+func showDepositsPopup(appState *AppState, entry Entry, onChange func()) {
+	log.Printf("Showing deposits for: %d", entry.ID)
+
+	deposits, err := getDeposits(appState.db, entry.ID)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
 	}
 
-	misthButton := widget.NewButton("ΜΙΣΘΩΤΗΡΙΟ", func() {
-		err := openFile(entry, appState)
+	var list *widget.List
+	refresh := func() {
+		deposits, err = getDeposits(appState.db, entry.ID)
 		if err != nil {
-			log.Println("openFile error: ", err)
+			log.Printf("Error updating the deposits list: %v", err)
 		}
+		list.Refresh()
+		onChange()
+	}
+
+	list = widget.NewList(
+		func() int {
+			return len(deposits)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Wrapping = fyne.TextWrapWord
+			returnButton := widget.NewButtonWithIcon("", theme.MailReplyIcon(), nil)
+			button := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), nil)
+			return container.NewBorder(nil, nil, nil, container.NewHBox(returnButton, button), label)
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			if lii < 0 || lii >= len(deposits) {
+				return
+			}
+			d := deposits[lii]
+			box := co.(*fyne.Container)
+			label := box.Objects[0].(*widget.Label)
+			buttons := box.Objects[1].(*fyne.Container)
+			returnButton := buttons.Objects[0].(*widget.Button)
+			button := buttons.Objects[1].(*widget.Button)
+
+			text := fmt.Sprintf("%s  %s  %.2f€  Κάτοχος: %s", d.Received, depositLabels[d.Kind], d.Amount, d.Holder)
+			if d.Held() {
+				returnButton.Enable()
+			} else {
+				text += fmt.Sprintf("  (Επεστράφη: %s)", d.Returned)
+				returnButton.Disable()
+			}
+			if d.Conditions != "" {
+				text += "\nΌροι επιστροφής: " + d.Conditions
+			}
+			label.SetText(text)
+			list.SetItemHeight(lii, label.MinSize().Height)
+
+			returnButton.OnTapped = func() {
+				returnedInput := widget.NewEntry()
+				returnedInput.SetText(time.Now().Format(dateLayout))
+				items := []*widget.FormItem{widget.NewFormItem("Ημερομηνία επιστροφής", returnedInput)}
+				dialog.ShowForm("Επιστροφή εγγύησης", "OK", "Cancel", items, func(b bool) {
+					if !b {
+						return
+					}
+					if err := returnDeposit(appState.db, d.ID, returnedInput.Text); err != nil {
+						dialog.ShowError(err, appState.window)
+						return
+					}
+					refresh()
+				}, appState.window)
+			}
+			button.OnTapped = func() {
+				dialog.ShowConfirm("Επιβεβαίωση Διαγραφής", "Είσαι σίγουρος;", func(b bool) {
+					if !b {
+						return
+					}
+					if err := delDeposit(appState.db, d.ID); err != nil {
+						dialog.ShowError(err, appState.window)
+						return
+					}
+					refresh()
+				}, appState.window)
+			}
+		},
+	)
+
+	kindOpts := []string{depositLabels[depositCash], depositLabels[depositGuarantee]}
+	kindSelect := widget.NewSelect(kindOpts, nil)
+	kindSelect.SetSelectedIndex(0)
+
+	dateInput := widget.NewEntry()
+	dateInput.SetPlaceHolder("Ημερομηνία παραλαβής")
+	dateInput.SetText(time.Now().Format(dateLayout))
+	dateInput.Disable()
+	dateButton := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		showCalendar(dateInput, appState.window)
 	})
 
-	// Add all the details!
-	scrollableContainer := container.NewVScroll(
-		container.NewVBox(
-			widget.NewLabel(fmt.Sprintf("ID: %d", entry.ID)),
-			widget.NewLabel(entry.Name),
-			ownersContainer,
-			rentersContainer,
-			widget.NewLabel(fmt.Sprintf("Μίσθωμα: %.2f€", entry.Rent)),
-			widget.NewLabel(fmt.Sprintf("ΑΠΟ: %s", entry.Start)),
-			widget.NewLabel(fmt.Sprintf("ΕΩΣ: %s", entry.End)),
-			widget.NewLabel(fmt.Sprintf("Είδος Καλ/γειας: %s", entry.Type)),
-			widget.NewLabel(fmt.Sprintf("Στρέμματα: %.3f", entry.Size)),
-			misthButton,
-			layout.NewSpacer(),
-			coordsContainer,
-		),
+	amount := NewFilteredEntry(`[^0-9.]`, "Ποσό")
+	holder := newEntryWithLabel("Κάτοχος")
+	conditions := widget.NewMultiLineEntry()
+	conditions.SetPlaceHolder("Όροι επιστροφής")
+	conditions.Wrapping = fyne.TextWrapWord
+	notes := newEntryWithLabel("Σημειώσεις")
+
+	addButton := widget.NewButtonWithIcon("Καταχώρηση", theme.ContentAddIcon(), func() {
+		value, err := ParseFloatToXDecimals(amount.Text, 2)
+		if err != nil || value <= 0 {
+			dialog.ShowError(fmt.Errorf("invalid amount: %s", amount.Text), appState.window)
+			return
+		}
+
+		d := Deposit{
+			EntryID:    entry.ID,
+			Kind:       depositCash,
+			Amount:     value,
+			Received:   dateInput.Text,
+			Holder:     holder.Text,
+			Conditions: conditions.Text,
+			Notes:      notes.Text,
+		}
+		if kindSelect.SelectedIndex() == 1 {
+			d.Kind = depositGuarantee
+		}
+
+		if err := saveDeposit(appState.db, d); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		log.Printf("Saved deposit of %.2f for entry %d", d.Amount, entry.ID)
+
+		amount.SetText("")
+		holder.SetText("")
+		conditions.SetText("")
+		notes.SetText("")
+		refresh()
+	})
+
+	form := container.NewVBox(
+		container.NewGridWithColumns(2, kindSelect, container.NewBorder(nil, nil, nil, dateButton, dateInput)),
+		container.NewGridWithColumns(2, amount, holder),
+		conditions,
+		notes,
+		addButton,
 	)
-	content := container.NewBorder(nil, buttonsContainer, nil, nil, scrollableContainer)
 
+	closeButton := widget.NewButton("Close", nil)
+	title := widget.NewLabel(fmt.Sprintf("Εγγυήσεις: %s", entry.Name))
+	title.TextStyle.Bold = true
+
+	content := container.NewBorder(title, container.NewVBox(form, closeButton), nil, nil, list)
 	popup := widget.NewModalPopUp(content, appState.window.Canvas())
-	editButton.OnTapped = func() {
-		editForm, err := editForm(appState, entry.ID)
-		if err != nil {
-			log.Printf("error creating desktopEditForm for %d: %v", entry.ID, err)
-		}
-		appState.window.SetContent(container.NewStack(appState.bg, editForm))
+	closeButton.OnTapped = func() {
 		popup.Hide()
 	}
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.8, appState.window.Canvas().Size().Height*0.8))
+	popup.Show()
+}
+
+// Files attached to a contract, receipts end up here too
+func showAttachmentsPopup(appState *AppState, entry Entry) {
+	attachments, err := getAttachments(appState.db, entry.ID)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int {
+			return len(attachments)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			button := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), nil)
+			return container.NewBorder(nil, nil, nil, button, label)
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			if lii < 0 || lii >= len(attachments) {
+				return
+			}
+			a := attachments[lii]
+			box := co.(*fyne.Container)
+			label := box.Objects[0].(*widget.Label)
+			button := box.Objects[1].(*widget.Button)
+
+			label.SetText(fmt.Sprintf("%s  (%s)", a.Name, a.Created.Format(dateLayout)))
+			button.OnTapped = func() {
+				dialog.ShowConfirm("Επιβεβαίωση Διαγραφής", "Είσαι σίγουρος;", func(b bool) {
+					if !b {
+						return
+					}
+					if err := delAttachment(appState.db, a.ID); err != nil {
+						dialog.ShowError(err, appState.window)
+						return
+					}
+					attachments, err = getAttachments(appState.db, entry.ID)
+					if err != nil {
+						log.Printf("Error updating the attachments list: %v", err)
+					}
+					list.Refresh()
+				}, appState.window)
+			}
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		if id >= 0 && id < len(attachments) {
+			if err := openBlob(appState, attachments[id].Name, attachments[id].Data); err != nil {
+				log.Println("openBlob error: ", err)
+			}
+		}
+		list.UnselectAll()
+	}
+
+	closeButton := widget.NewButton("Close", nil)
+	title := widget.NewLabel(fmt.Sprintf("Συνημμένα: %s", entry.Name))
+	title.TextStyle.Bold = true
+
+	content := container.NewBorder(title, closeButton, nil, nil, list)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
 	closeButton.OnTapped = func() {
 		popup.Hide()
 	}
-	deleteButton.OnTapped = func() {
-		dlg := dialog.NewConfirm("Επιβεβαίωση Διαγραφής", "Είσαι σίγουρος;", func(b bool) {
+
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.66))
+	popup.Show()
+}
+
+// Fills a template with the details of the contract and saves the document
+// to a file or as the μισθωτήριο of the contract, onSaved gets the new one
+func showDocumentPopup(appState *AppState, entry Entry, onSaved func([]byte)) {
+	templates, err := getTemplates(appState.db)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+
+	templateNames := func() []string {
+		var names []string
+		for _, t := range templates {
+			names = append(names, t.Name)
+		}
+		return names
+	}
+	templateSelect := widget.NewSelect(templateNames(), nil)
+	if len(templates) > 0 {
+		templateSelect.SetSelectedIndex(0)
+	}
+	formatRadio := widget.NewRadioGroup(documentFormats, nil)
+	formatRadio.Horizontal = true
+	formatRadio.SetSelected(formatPDF)
+
+	render := func() ([]byte, error) {
+		i := templateSelect.SelectedIndex()
+		if i < 0 || i >= len(templates) {
+			return nil, fmt.Errorf("choose a template")
+		}
+		text, err := renderTemplate(templates[i].Body, templateValues(entry, time.Now()))
+		if err != nil {
+			return nil, err
+		}
+		return renderDocument(text, formatRadio.Selected)
+	}
+
+	templatesButton := widget.NewButtonWithIcon("Πρότυπα", theme.SettingsIcon(), func() {
+		showTemplatesPopup(appState, func() {
+			templates, err = getTemplates(appState.db)
+			if err != nil {
+				log.Printf("Error updating the templates: %v", err)
+			}
+			templateSelect.SetOptions(templateNames())
+			templateSelect.ClearSelected()
+		})
+	})
+	fileButton := widget.NewButtonWithIcon("Αρχείο", theme.DocumentSaveIcon(), func() {
+		data, err := render()
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		saveFileDialog(appState, entry.Name+documentExts[formatRadio.Selected], data)
+	})
+	emisthButton := widget.NewButtonWithIcon("Ως μισθωτήριο", theme.DocumentIcon(), func() {
+		data, err := render()
+		if err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		save := func() {
+			if err := setEntryDocument(appState.db, entry.ID, data); err != nil {
+				dialog.ShowError(err, appState.window)
+				return
+			}
+			log.Printf("Generated the document of %d", entry.ID)
+			entry.emisth = data
+			onSaved(data)
+			dialog.ShowInformation("Database:", "Saved successfully!", appState.window)
+		}
+		if len(entry.emisth) == 0 {
+			save()
+			return
+		}
+		dialog.ShowConfirm("Αντικατάσταση", "Το συμβόλαιο έχει ήδη μισθωτήριο, να αντικατασταθεί;", func(b bool) {
 			if b {
-				err := delEntry(appState.db, entry.ID)
-				if err != nil {
-					dialog.ShowError(err, appState.window)
-				}
-				dialog.ShowInformation("Deleted", fmt.Sprintf("Deleted entry %d!", entry.ID), appState.window)
-				if err != nil {
-					dialog.ShowError(err, appState.window)
-				}
-				// Need to do all that to update the list in the mainView after a deletion
-				*entries, err = getAllEntries(appState.db)
-				if err != nil {
-					log.Printf("Error updating the list: %v", err)
-				}
-				list.Refresh()
-				popup.Hide()
+				save()
 			}
 		}, appState.window)
-		dlg.Show()
-	}
+	})
 
-	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.8, appState.window.Canvas().Size().Height*0.8))
+	closeButton := widget.NewButton("Close", nil)
+	title := widget.NewLabel(fmt.Sprintf("Έγγραφο: %s", entry.Name))
+	title.TextStyle.Bold = true
+
+	content := container.NewVBox(
+		title,
+		container.NewBorder(nil, nil, nil, templatesButton, templateSelect),
+		formatRadio,
+		container.NewGridWithColumns(2, fileButton, emisthButton),
+		closeButton,
+	)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	closeButton.OnTapped = func() {
+		popup.Hide()
+	}
 
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, 0))
 	popup.Show()
-	fmt.Printf("Popup displayed for: %d", entry.ID)
 }
 
-func showGeoLocForm(appState *AppState, entriesMap map[string]*widget.Entry) {
-	content := container.NewVBox()
-	closeButton := widget.NewButton("close", nil)
-	for i := range 4 {
-		coordContainer := container.NewGridWithColumns(2, entriesMap[fmt.Sprintf("Πλάτος %d", i+1)], entriesMap[fmt.Sprintf("Μήκος %d", i+1)])
-		content.Add(coordContainer)
+// The templates of the documents, onChange runs after every change
+func showTemplatesPopup(appState *AppState, onChange func()) {
+	templates, err := getTemplates(appState.db)
+	if err != nil {
+		dialog.ShowError(err, appState.window)
+		return
+	}
+
+	var list *widget.List
+	reload := func() {
+		templates, err = getTemplates(appState.db)
+		if err != nil {
+			log.Printf("Error updating the templates list: %v", err)
+		}
+		list.Refresh()
+		onChange()
 	}
+	list = widget.NewList(
+		func() int {
+			return len(templates)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			editButton := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil)
+			delButton := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), nil)
+			return container.NewBorder(nil, nil, nil, container.NewHBox(editButton, delButton), label)
+		},
+		func(lii widget.ListItemID, co fyne.CanvasObject) {
+			if lii < 0 || lii >= len(templates) {
+				return
+			}
+			t := templates[lii]
+			box := co.(*fyne.Container)
+			box.Objects[0].(*widget.Label).SetText(t.Name)
+			buttons := box.Objects[1].(*fyne.Container)
+			buttons.Objects[0].(*widget.Button).OnTapped = func() {
+				showTemplateEditor(appState, t, reload)
+			}
+			buttons.Objects[1].(*widget.Button).OnTapped = func() {
+				dialog.ShowConfirm("Επιβεβαίωση Διαγραφής", "Είσαι σίγουρος;", func(b bool) {
+					if !b {
+						return
+					}
+					if err := delTemplate(appState.db, t.ID); err != nil {
+						dialog.ShowError(err, appState.window)
+						return
+					}
+					reload()
+				}, appState.window)
+			}
+		},
+	)
+
+	addButton := widget.NewButtonWithIcon("Νέο πρότυπο", theme.ContentAddIcon(), func() {
+		showTemplateEditor(appState, Template{}, reload)
+	})
+	closeButton := widget.NewButton("Close", nil)
+	title := widget.NewLabel("Πρότυπα εγγράφων")
+	title.TextStyle.Bold = true
 
+	content := container.NewBorder(title, container.NewVBox(addButton, closeButton), nil, nil, list)
 	popup := widget.NewModalPopUp(content, appState.window.Canvas())
 	closeButton.OnTapped = func() {
 		popup.Hide()
 	}
-	content.Add(closeButton)
 
 	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.66, appState.window.Canvas().Size().Height*0.66))
+	popup.Show()
+}
+
+func showTemplateEditor(appState *AppState, t Template, onSaved func()) {
+	nameInput := widget.NewEntry()
+	nameInput.SetPlaceHolder("Όνομα")
+	nameInput.SetText(t.Name)
+	bodyInput := widget.NewMultiLineEntry()
+	bodyInput.Wrapping = fyne.TextWrapWord
+	bodyInput.SetText(t.Body)
+
+	var keys []string
+	for k := range templatePlaceholders {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var help []string
+	for _, k := range keys {
+		help = append(help, fmt.Sprintf("{{%s}} %s", k, templatePlaceholders[k]))
+	}
+	helpLabel := widget.NewLabel("Οι γραμμές που αρχίζουν με # είναι τίτλοι.\n" + strings.Join(help, "\n"))
+	helpLabel.Wrapping = fyne.TextWrapWord
+
+	saveButton := widget.NewButton("Αποθήκευση", nil)
+	cancelButton := widget.NewButton("Cancel", nil)
+
+	content := container.NewBorder(
+		nameInput,
+		container.NewGridWithColumns(2, cancelButton, saveButton),
+		nil,
+		nil,
+		container.NewHSplit(bodyInput, container.NewVScroll(helpLabel)),
+	)
+	popup := widget.NewModalPopUp(content, appState.window.Canvas())
+	saveButton.OnTapped = func() {
+		t.Name = strings.TrimSpace(nameInput.Text)
+		t.Body = bodyInput.Text
+		if err := saveTemplate(appState.db, t); err != nil {
+			dialog.ShowError(err, appState.window)
+			return
+		}
+		popup.Hide()
+		onSaved()
+	}
+	cancelButton.OnTapped = func() {
+		popup.Hide()
+	}
 
+	popup.Resize(fyne.NewSize(appState.window.Canvas().Size().Width*0.9, appState.window.Canvas().Size().Height*0.9))
 	popup.Show()
 }
 
-// Show Calendar for easy date picking
-// There is another calendar widget floating around maybe I should check it out
-// or customize this to add a button for the year?
 func showCalendar(entry *widget.Entry, window fyne.Window) {
 	log.Printf("Showing popup date picker.")
 	calendar := xwidget.NewCalendar(time.Now(), func(t time.Time) {
-		dateString := t.Format("02-01-2006")
+		dateString := t.Format(dateLayout)
 		entry.SetText(dateString)
 
 		for _, overlay := range window.Canvas().Overlays().List() {
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A parcel at the point looked up, with the contracts that lease it on the
+// day, usually one
+type ParcelHit struct {
+	Parcel  Parcel
+	Current []Entry
+}
+
+// A point typed or pasted as latitude and longitude in any of the ways
+// parseLatLon takes, or as ΕΓΣΑ87 X and Y in metres
+func parsePoint(s string) (Coordinates, error) {
+	c, err := parseLatLon(s)
+	if err == nil {
+		return c, nil
+	}
+
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 2 {
+		if grid, gridErr := parseGridCorner(fields[0], fields[1]); gridErr == nil {
+			return grid, nil
+		}
+	}
+
+	return Coordinates{}, err
+}
+
+// The parcels drawn around the point, the smallest first, and then the
+// parcels of the contracts whose field has the point but whose parcels
+// aren't drawn, so the field the contract was drawn with stands for them.
+// The contracts on the parcels are left to the caller. A contract from
+// before the parcels that has none stands in with its own field and is the
+// contract on it.
+func parcelsAt(parcels []Parcel, fields []Entry, c Coordinates, day time.Time) []ParcelHit {
+	pt := point{c.Longitude, c.Latitude}
+	seen := map[uint]bool{}
+
+	var drawn []Parcel
+	for _, p := range parcels {
+		if shapeContains(shapePolygons(p.Coords), pt) {
+			drawn = append(drawn, p)
+		}
+	}
+	sort.SliceStable(drawn, func(i, j int) bool {
+		return ellipsoidalArea(drawn[i].Coords) < ellipsoidalArea(drawn[j].Coords)
+	})
+
+	var hits []ParcelHit
+	for _, p := range drawn {
+		seen[p.ID] = true
+		hits = append(hits, ParcelHit{Parcel: p})
+	}
+
+	for _, e := range fields {
+		if len(e.Parcels) == 0 {
+			e.Parcels = []Parcel{parcelFromEntry(e)}
+			hits = append(hits, ParcelHit{Parcel: e.Parcels[0], Current: parcelContractsOn(0, []Entry{e}, day)})
+			continue
+		}
+
+		// the field of a contract on drawn parcels is theirs, the ones at
+		// the point are in already
+		onDrawn := false
+		for _, p := range e.Parcels {
+			onDrawn = onDrawn || len(p.Coords) > 0
+		}
+		if onDrawn {
+			continue
+		}
+
+		for _, p := range e.Parcels {
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				hits = append(hits, ParcelHit{Parcel: p})
+			}
+		}
+	}
+
+	return hits
+}
+
+// The parcels at the point with the contracts that lease them on the day.
+// The R*Trees give the parcels and the contracts whose box holds the point,
+// only those fields are checked corner by corner.
+func lookupPoint(db *sql.DB, c Coordinates, day time.Time) ([]ParcelHit, error) {
+	parcels, err := getParcelsAt(db, c.Latitude, c.Longitude)
+	if err != nil {
+		return nil, fmt.Errorf("error searching the spatial index: %v", err)
+	}
+	ids, err := getEntryIDsAt(db, c.Latitude, c.Longitude)
+	if err != nil {
+		return nil, fmt.Errorf("error searching the spatial index: %v", err)
+	}
+	candidates, err := getEntriesByID(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := parcelsAt(parcels, entriesAt(candidates, c.Latitude, c.Longitude), c, day)
+	for i, h := range hits {
+		if h.Parcel.ID == 0 {
+			continue
+		}
+		leases, err := getParcelEntries(db, h.Parcel.ID)
+		if err != nil {
+			return nil, err
+		}
+		hits[i].Current = parcelContractsOn(h.Parcel.ID, leases, day)
+	}
+
+	return hits, nil
+}
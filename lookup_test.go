@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParsePoint(t *testing.T) {
+	t.Parallel()
+
+	c, err := parsePoint("37.9838, 23.7275")
+	if err != nil || c.Latitude != 37.9838 || c.Longitude != 23.7275 {
+		t.Errorf("degrees = %+v, %v", c, err)
+	}
+
+	// the same place in ΕΓΣΑ87
+	x, y := formatGridCorner(Coordinates{Latitude: 37.9838, Longitude: 23.7275})
+	c, err = parsePoint(x + " " + y)
+	if err != nil || math.Abs(c.Latitude-37.9838) > 1e-7 || math.Abs(c.Longitude-23.7275) > 1e-7 {
+		t.Errorf("ΕΓΣΑ87 = %+v, %v", c, err)
+	}
+
+	if _, err := parsePoint("somewhere"); err == nil {
+		t.Error("expected an error for text that is not a point")
+	}
+}
+
+func TestParcelsAt(t *testing.T) {
+	t.Parallel()
+
+	west := Parcel{ID: 1, KAEK: "west", Coords: rectangle(38, 23, 38.001, 23.001)}
+	east := Parcel{ID: 2, KAEK: "east", Coords: rectangle(38, 23.001, 38.001, 23.002)}
+	undrawn := Parcel{ID: 3, KAEK: "undrawn"}
+	both := Entry{ID: 1, Coords: rectangle(38, 23, 38.001, 23.002), Parcels: []Parcel{west, east}}
+	sublease := Entry{ID: 2, Coords: rectangle(38, 23.0012, 38.001, 23.0018), Parcels: []Parcel{east}}
+	old := Entry{ID: 3, KAEK: "old", Start: "01-10-2025", End: "30-09-2030", Status: statusActive,
+		Coords: rectangle(38, 23.0015, 38.001, 23.0025)}
+	noDrawing := Entry{ID: 4, Coords: rectangle(38, 23.0015, 38.001, 23.0025), Parcels: []Parcel{undrawn}}
+
+	// drawn in the Parcels view, no contract leases it
+	free := Parcel{ID: 5, KAEK: "free", Coords: rectangle(38.0004, 23.0015, 38.0006, 23.0017)}
+
+	pt := Coordinates{Latitude: 38.0005, Longitude: 23.0016}
+	fields := entriesAt([]Entry{both, sublease, old, noDrawing}, pt.Latitude, pt.Longitude)
+	hits := parcelsAt([]Parcel{west, east, free}, fields, pt, date("01-01-2026"))
+
+	var kaek []string
+	for _, h := range hits {
+		kaek = append(kaek, h.Parcel.KAEK)
+	}
+	// the drawn ones smallest first, the contracts on east only give it
+	// once, then the contracts that stand in for their parcels
+	if len(kaek) != 4 || kaek[0] != "free" || kaek[1] != "east" || kaek[2] != "old" || kaek[3] != "undrawn" {
+		t.Fatalf("parcels = %v, want free, east once, old and undrawn", kaek)
+	}
+	if len(hits[2].Current) != 1 || hits[2].Current[0].ID != old.ID {
+		t.Errorf("the contract without parcels should be on its own field, got %+v", hits[1].Current)
+	}
+	if hits[0].Current != nil {
+		t.Errorf("the contracts of a parcel are left to the caller, got %+v", hits[0].Current)
+	}
+}
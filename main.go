@@ -2,6 +2,7 @@ package main
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"image/color"
 	"log"
@@ -110,6 +111,9 @@ func InitApp() (*AppState, error) {
 			return nil, fmt.Errorf("error enabling foreign_keys pragma: %v", err)
 		}
 		_, _ = db.Exec("PRAGMA busy_timeout = 5000;")
+		if err := migrateDB(db); err != nil {
+			return nil, errors.Join(err, db.Close())
+		}
 	} else if os.IsNotExist(err) {
 		log.Println("DB file doesn't exist.")
 		db, err = initDB(dbPath)
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"image"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+// The cached tiles are dropped past that many, a screen needs a few dozen
+const maxCachedTiles = 512
+
+// MapWidget shows the fields of the contracts over the basemap, it pans by
+// dragging and zooms with the scroll wheel or the buttons of the map view
+type MapWidget struct {
+	widget.BaseWidget
+
+	raster  *canvas.Raster
+	basemap *MBTiles
+	entries *[]Entry
+	view    mapViewport
+	fitted  bool
+	tiles   map[[3]int]image.Image
+
+	// The latitude and longitude tapped
+	OnTapped func(lat, lon float64)
+}
+
+// NewMapWidget creates a map of the entries, the basemap can be nil
+func NewMapWidget(basemap *MBTiles, entries *[]Entry) *MapWidget {
+	m := &MapWidget{basemap: basemap, entries: entries, tiles: map[[3]int]image.Image{}}
+	m.raster = canvas.NewRaster(m.render)
+	m.raster.SetMinSize(fyne.NewSize(200, 200))
+	m.ExtendBaseWidget(m)
+
+	return m
+}
+
+func (m *MapWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(m.raster)
+}
+
+// SetBasemap swaps the tileset, the old one is closed
+func (m *MapWidget) SetBasemap(basemap *MBTiles) {
+	if m.basemap != nil {
+		if err := m.basemap.Close(); err != nil {
+			log.Println("basemap.Close() error: ", err)
+		}
+	}
+	m.basemap = basemap
+	m.tiles = map[[3]int]image.Image{}
+	m.Fit()
+}
+
+// Fit shows all the fields, or the whole tileset when there are none
+func (m *MapWidget) Fit() {
+	m.fitted = false
+	m.raster.Refresh()
+}
+
+func (m *MapWidget) ZoomIn() {
+	m.zoom(1, float64(m.view.Width)/2, float64(m.view.Height)/2)
+}
+
+func (m *MapWidget) ZoomOut() {
+	m.zoom(-1, float64(m.view.Width)/2, float64(m.view.Height)/2)
+}
+
+func (m *MapWidget) Dragged(ev *fyne.DragEvent) {
+	scale := m.pixelScale()
+	m.view.CenterX -= float64(ev.Dragged.DX) * scale
+	m.view.CenterY -= float64(ev.Dragged.DY) * scale
+	m.raster.Refresh()
+}
+
+func (m *MapWidget) DragEnd() {}
+
+func (m *MapWidget) Scrolled(ev *fyne.ScrollEvent) {
+	if ev.Scrolled.DY == 0 {
+		return
+	}
+	delta := 1
+	if ev.Scrolled.DY < 0 {
+		delta = -1
+	}
+	scale := m.pixelScale()
+	m.zoom(delta, float64(ev.Position.X)*scale, float64(ev.Position.Y)*scale)
+}
+
+func (m *MapWidget) Tapped(ev *fyne.PointEvent) {
+	if m.OnTapped == nil {
+		return
+	}
+	scale := m.pixelScale()
+	lat, lon := m.view.latLonAt(float64(ev.Position.X)*scale, float64(ev.Position.Y)*scale)
+	m.OnTapped(lat, lon)
+}
+
+func (m *MapWidget) zoom(delta int, px, py float64) {
+	minZoom, maxZoom := m.zoomRange()
+	m.view = m.view.zoomAt(delta, px, py, minZoom, maxZoom)
+	m.raster.Refresh()
+}
+
+// The raster is drawn in screen pixels, the events come in the units of the
+// canvas
+func (m *MapWidget) pixelScale() float64 {
+	if m.Size().Width == 0 || m.view.Width == 0 {
+		return 1
+	}
+	return float64(m.view.Width) / float64(m.Size().Width)
+}
+
+func (m *MapWidget) zoomRange() (int, int) {
+	if m.basemap == nil {
+		return mapMinZoom, mapMaxZoom
+	}
+	return m.basemap.MinZoom, m.basemap.MaxZoom + maxOverzoom
+}
+
+func (m *MapWidget) render(w, h int) image.Image {
+	m.view.Width, m.view.Height = w, h
+	if !m.fitted && w > 0 && h > 0 {
+		m.view = m.fitView(w, h)
+		m.fitted = true
+	}
+
+	var tiles tileSource
+	if m.basemap != nil {
+		tiles = m.tile
+	}
+	return renderMap(m.view, tiles, mapShapes(*m.entries))
+}
+
+func (m *MapWidget) fitView(w, h int) mapViewport {
+	minZoom, maxZoom := m.zoomRange()
+	if box, ok := coordsBox(*m.entries); ok {
+		return fitViewport(box, w, h, minZoom, maxZoom)
+	}
+	if m.basemap != nil && m.basemap.Bounds != [4]float64{} {
+		b := m.basemap.Bounds
+		return fitViewport([4]float64{b[1], b[0], b[3], b[2]}, w, h, minZoom, maxZoom)
+	}
+
+	// Greece
+	return fitViewport([4]float64{34.8, 19.3, 41.8, 29.7}, w, h, minZoom, maxZoom)
+}
+
+func (m *MapWidget) tile(z, x, y int) image.Image {
+	key := [3]int{z, x, y}
+	if img, ok := m.tiles[key]; ok {
+		return img
+	}
+
+	img, err := m.basemap.Tile(z, x, y)
+	if err != nil {
+		log.Printf("Error reading the map tile: %v", err)
+	}
+	if len(m.tiles) >= maxCachedTiles {
+		m.tiles = map[[3]int]image.Image{}
+	}
+	m.tiles[key] = img
+
+	return img
+}
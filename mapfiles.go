@@ -0,0 +1,199 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// A field read from a file, before it is matched to a contract
+type ImportedShape struct {
+	Name   string
+	KAEK   string
+	Coords []Coordinates
+	Err    error // why it can't be imported
+}
+
+// What an imported field will do to the contracts, nothing if Entries is
+// empty or Err is set. A field matched by the KAEK of a parcel goes to the
+// parcel and from there to the contracts on it.
+type CoordsImport struct {
+	Shape   ImportedShape
+	Entries []Entry
+	Parcels []Parcel
+	Err     error
+}
+
+// The rings of the field the way GeoJSON and KML want them, longitude first,
+// closed, the outer rings counter-clockwise and the holes clockwise
+func exportRings(coords []Coordinates) [][][][2]float64 {
+	var parts [][][][2]float64
+	for _, rings := range shapeRings(coords) {
+		// a part without its outer ring can't be written
+		if len(openRing(rings[0])) < 3 {
+			continue
+		}
+		var part [][][2]float64
+		for r, corners := range rings {
+			corners = openRing(corners)
+			if len(corners) < 3 {
+				continue
+			}
+			ccw := signedArea(polygonPoints(corners)) > 0
+			positions := make([][2]float64, 0, len(corners)+1)
+			for i := range corners {
+				c := corners[i]
+				if ccw != (r == 0) {
+					c = corners[(len(corners)-i)%len(corners)]
+				}
+				positions = append(positions, [2]float64{c.Longitude, c.Latitude})
+			}
+			positions = append(positions, positions[0])
+			part = append(part, positions)
+		}
+		parts = append(parts, part)
+	}
+
+	return parts
+}
+
+// The fields in a GeoJSON, KML, KMZ or GPX file or a list of corners, by
+// its extension. The tracks of a GPX file are simplified to gpxTolerance
+// metres.
+func parseMapFile(name string, data []byte, gpxTolerance float64) ([]ImportedShape, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".geojson", ".json":
+		return parseGeoJSON(data)
+	case ".kml":
+		return parseKML(data)
+	case ".kmz":
+		return parseKMZ(data)
+	case ".gpx":
+		return parseGPX(data, gpxTolerance)
+	case ".csv", ".txt":
+		coords, err := parseCornerList(string(data))
+		if coords == nil && err != nil {
+			return nil, err
+		}
+		return []ImportedShape{{Name: strings.TrimSuffix(name, filepath.Ext(name)), Coords: coords, Err: err}}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported file: %s", name)
+}
+
+var mapFileExtensions = []string{".geojson", ".json", ".kml", ".kmz", ".gpx", ".csv", ".txt"}
+
+// All the fields of the file as the parts of one field, for a file that
+// was drawn for one contract
+func mergeShapes(shapes []ImportedShape) ([]Coordinates, error) {
+	var merged [][]ring
+	var firstErr error
+	for _, s := range shapes {
+		if s.Err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %v", s.Name, s.Err)
+			}
+			continue
+		}
+		merged = append(merged, shapeRings(s.Coords)...)
+	}
+	if len(merged) == 0 {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, errors.New("there are no fields in the file")
+	}
+
+	coords := numberShape(merged)
+	return coords, validateShape(coords)
+}
+
+// Matches every field to the contracts with its KAEK, on the contract or one
+// of its parcels, or else to the ones with its name
+func matchImports(shapes []ImportedShape, entries []Entry) []CoordsImport {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Join(strings.Fields(s), ""))
+	}
+
+	imports := make([]CoordsImport, 0, len(shapes))
+	for _, s := range shapes {
+		imp := CoordsImport{Shape: s, Err: s.Err}
+		if imp.Err != nil {
+			imports = append(imports, imp)
+			continue
+		}
+
+		if kaek := normalize(s.KAEK); kaek != "" {
+			seen := map[uint]bool{}
+			for _, e := range entries {
+				match := normalize(e.KAEK) == kaek
+				for _, p := range e.Parcels {
+					if normalize(p.KAEK) != kaek {
+						continue
+					}
+					match = true
+					if !seen[p.ID] {
+						seen[p.ID] = true
+						imp.Parcels = append(imp.Parcels, p)
+					}
+				}
+				if match {
+					imp.Entries = append(imp.Entries, e)
+				}
+			}
+		}
+		if name := strings.ToLower(strings.TrimSpace(s.Name)); len(imp.Entries) == 0 && name != "" {
+			for _, e := range entries {
+				if strings.ToLower(strings.TrimSpace(e.Name)) == name {
+					imp.Entries = append(imp.Entries, e)
+				}
+			}
+		}
+		if len(imp.Entries) == 0 {
+			imp.Err = errors.New("no contract with that KAEK or name")
+		}
+		imports = append(imports, imp)
+	}
+
+	return imports
+}
+
+// The contracts that take the field themselves, the rest get it from the
+// parcels
+func (imp CoordsImport) EntryIDs() []uint {
+	matched := map[uint]bool{}
+	for _, p := range imp.Parcels {
+		matched[p.ID] = true
+	}
+
+	var ids []uint
+	for _, e := range imp.Entries {
+		onParcel := false
+		for _, p := range e.Parcels {
+			onParcel = onParcel || matched[p.ID]
+		}
+		if !onParcel {
+			ids = append(ids, e.ID)
+		}
+	}
+
+	return ids
+}
+
+// What the preview says about the field
+func (imp CoordsImport) Describe() string {
+	label := imp.Shape.Name
+	if imp.Shape.KAEK != "" {
+		label = strings.TrimSpace(fmt.Sprintf("%s (ΚΑΕΚ %s)", imp.Shape.Name, imp.Shape.KAEK))
+	}
+	if imp.Err != nil {
+		return fmt.Sprintf("%s: %v", label, imp.Err)
+	}
+
+	var contracts []string
+	for _, e := range imp.Entries {
+		contracts = append(contracts, contractLabel(e))
+	}
+	return fmt.Sprintf("%s → %s, %.3f στρ.", label, strings.Join(contracts, ", "), drawnSize(imp.Shape.Coords))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMatchImports(t *testing.T) {
+	t.Parallel()
+
+	coords := fieldWithHole()
+	entries := []Entry{
+		{ID: 1, Name: "Κάτω χωράφι", KAEK: "12 3456 789012"},
+		{ID: 2, Name: "Πάνω χωράφι", Parcels: []Parcel{{ID: 5, KAEK: "555"}}},
+		{ID: 3, Name: "κάτω χωράφι "},
+	}
+
+	imports := matchImports([]ImportedShape{
+		{Name: "whatever", KAEK: "123456789012", Coords: coords},
+		{KAEK: "555", Coords: coords},
+		{Name: "Κάτω Χωράφι", KAEK: "999", Coords: coords},
+		{Name: "Λόγγος", Coords: coords},
+	}, entries)
+
+	ids := func(imp CoordsImport) []uint {
+		var ids []uint
+		for _, e := range imp.Entries {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+	if got := ids(imports[0]); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("the KAEK should match contract 1, got %v", got)
+	}
+	if got := ids(imports[1]); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("the parcel KAEK should match contract 2, got %v", got)
+	}
+	if len(imports[1].Parcels) != 1 || imports[1].Parcels[0].ID != 5 || len(imports[1].EntryIDs()) != 0 {
+		t.Fatalf("the field should go to parcel 5 and not to contract 2 itself, got %v %v", imports[1].Parcels, imports[1].EntryIDs())
+	}
+	if got := imports[0].EntryIDs(); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("contract 1 has no parcel with the KAEK and takes the field itself, got %v", got)
+	}
+	if got := ids(imports[2]); len(got) != 2 {
+		t.Fatalf("the name should match contracts 1 and 3, got %v", got)
+	}
+	if imports[3].Err == nil || !strings.Contains(imports[3].Describe(), "Λόγγος") {
+		t.Fatalf("expected no match, got %+v", imports[3])
+	}
+}
+
+func TestMergeShapes(t *testing.T) {
+	t.Parallel()
+
+	a := ImportedShape{Name: "a", Coords: corners(0, 0, 38, 23, 38, 23.01, 38.01, 23.01)}
+	b := ImportedShape{Name: "b", Coords: corners(0, 0, 39, 23, 39, 23.01, 39.01, 23.01)}
+	point := ImportedShape{Name: "point", Err: errors.New("not a field")}
+
+	coords, err := mergeShapes([]ImportedShape{a, point, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coords) != 6 || coords[3].Part != 1 || coords[3].Latitude != 39 {
+		t.Fatalf("expected b as the second part: %+v", coords)
+	}
+
+	if _, err := mergeShapes([]ImportedShape{a, a}); err == nil {
+		t.Fatal("expected the parts on top of each other to be refused")
+	}
+	if _, err := mergeShapes([]ImportedShape{point}); err == nil || !strings.Contains(err.Error(), "point") {
+		t.Fatalf("expected the error of the point, got %v", err)
+	}
+}
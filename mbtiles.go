@@ -0,0 +1,451 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/vector"
+	_ "golang.org/x/image/webp"
+)
+
+// Map tiles are 256 pixels wide, the world is 256·2^zoom pixels at a zoom
+const tileSize = 256
+
+// Web Mercator can't reach the poles
+const maxMercatorLat = 85.05112878
+
+// A zoom past the last one of the tileset scales up the tiles of the last one
+const maxOverzoom = 3
+
+// The map of the fields without a basemap goes that far
+const (
+	mapMinZoom = 2
+	mapMaxZoom = 19
+)
+
+// A raster tileset in an MBTiles file (an SQLite database), the basemap of
+// the map view
+type MBTiles struct {
+	db      *sql.DB
+	Name    string
+	Format  string
+	MinZoom int
+	MaxZoom int
+	// min lon, min lat, max lon, max lat, all 0 when the tileset doesn't say
+	Bounds [4]float64
+}
+
+func openMBTiles(path string) (*MBTiles, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MBTiles{db: db}
+	if err := m.load(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *MBTiles) load() error {
+	var minZoom, maxZoom sql.NullInt64
+	err := m.db.QueryRow(`SELECT MIN(zoom_level), MAX(zoom_level) FROM tiles`).Scan(&minZoom, &maxZoom)
+	if err != nil {
+		return fmt.Errorf("not an MBTiles file: %v", err)
+	}
+	if !minZoom.Valid {
+		return errors.New("the MBTiles file has no tiles")
+	}
+	m.MinZoom, m.MaxZoom = int(minZoom.Int64), int(maxZoom.Int64)
+
+	// the metadata is optional, the zooms found in the tiles stay
+	rows, err := m.db.Query(`SELECT name, value FROM metadata`)
+	if err != nil {
+		return nil
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Println("rows.Close() error: ", err)
+		}
+	}()
+
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return err
+		}
+		switch name {
+		case "name":
+			m.Name = value
+		case "format":
+			m.Format = value
+		case "bounds":
+			var b [4]float64
+			fields := strings.Split(value, ",")
+			if len(fields) != 4 {
+				continue
+			}
+			ok := true
+			for i, f := range fields {
+				v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+				ok = ok && err == nil
+				b[i] = v
+			}
+			if ok {
+				m.Bounds = b
+			}
+		}
+	}
+	if m.Format == "pbf" {
+		return errors.New("the MBTiles file has vector tiles, the map needs raster (png, jpg or webp) tiles")
+	}
+
+	return rows.Err()
+}
+
+// The tile in the XYZ numbering of the web maps, nil if the tileset doesn't
+// have it. MBTiles counts the rows from the south (TMS).
+func (m *MBTiles) Tile(z, x, y int) (image.Image, error) {
+	var data []byte
+	err := m.db.QueryRow(`
+		SELECT tile_data FROM tiles
+		WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?`,
+		z, x, (1<<z)-1-y).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("tile %d/%d/%d: %v", z, x, y, err)
+	}
+
+	return img, nil
+}
+
+func (m *MBTiles) Close() error {
+	return m.db.Close()
+}
+
+// The basemap is kept next to the database under that name
+const basemapFile = "basemap.mbtiles"
+
+// The basemap in the directory, nil without an error when there is none
+func openBasemap(dir string) (*MBTiles, error) {
+	path := filepath.Join(dir, basemapFile)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return openMBTiles(path)
+}
+
+// Copies the tileset into the directory as the basemap. It is checked
+// before it replaces the old one, a bad file leaves the old one in place.
+func importBasemap(dir string, r io.Reader) (*MBTiles, error) {
+	tmp, err := os.CreateTemp(dir, "basemap-*.mbtiles")
+	if err != nil {
+		return nil, err
+	}
+	// gone already after the rename
+	defer func() {
+		if err := os.Remove(tmp.Name()); err != nil && !os.IsNotExist(err) {
+			log.Println("os.Remove() error: ", err)
+		}
+	}()
+
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := openMBTiles(tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, basemapFile)); err != nil {
+		return nil, err
+	}
+	return openBasemap(dir)
+}
+
+// Latitude and longitude to the pixels of the whole world at the zoom
+func worldPixel(lat, lon float64, zoom int) (float64, float64) {
+	lat = max(min(lat, maxMercatorLat), -maxMercatorLat)
+	size := float64(int(tileSize) << zoom)
+	phi := radians(lat)
+
+	x := (lon + 180) / 360 * size
+	y := (1 - math.Log(math.Tan(phi)+1/math.Cos(phi))/math.Pi) / 2 * size
+
+	return x, y
+}
+
+// The pixels of the whole world at the zoom to latitude and longitude
+func latLonAt(x, y float64, zoom int) (float64, float64) {
+	size := float64(int(tileSize) << zoom)
+	lon := x/size*360 - 180
+	lat := degrees(math.Atan(math.Sinh(math.Pi * (1 - 2*y/size))))
+
+	return lat, lon
+}
+
+// What the map shows: the world pixel in the middle of the image at the zoom
+type mapViewport struct {
+	Zoom             int
+	CenterX, CenterY float64
+	Width, Height    int
+}
+
+// The top left corner of the image in world pixels
+func (v mapViewport) origin() (float64, float64) {
+	return v.CenterX - float64(v.Width)/2, v.CenterY - float64(v.Height)/2
+}
+
+// Latitude and longitude at a pixel of the image
+func (v mapViewport) latLonAt(px, py float64) (float64, float64) {
+	ox, oy := v.origin()
+	return latLonAt(ox+px, oy+py, v.Zoom)
+}
+
+// The same place under the pixel of the image after zooming by delta
+func (v mapViewport) zoomAt(delta int, px, py float64, minZoom, maxZoom int) mapViewport {
+	zoom := max(min(v.Zoom+delta, maxZoom), minZoom)
+	if zoom == v.Zoom {
+		return v
+	}
+
+	ox, oy := v.origin()
+	scale := math.Pow(2, float64(zoom-v.Zoom))
+	wx, wy := (ox+px)*scale, (oy+py)*scale
+	v.Zoom = zoom
+	v.CenterX = wx - px + float64(v.Width)/2
+	v.CenterY = wy - py + float64(v.Height)/2
+
+	return v
+}
+
+// The biggest zoom that shows the whole box (min lat, min lon, max lat, max
+// lon) in the image, centred on it
+func fitViewport(box [4]float64, width, height, minZoom, maxZoom int) mapViewport {
+	v := mapViewport{Zoom: minZoom, Width: width, Height: height}
+	for z := maxZoom; z >= minZoom; z-- {
+		x1, y1 := worldPixel(box[2], box[1], z)
+		x2, y2 := worldPixel(box[0], box[3], z)
+		if x2-x1 <= float64(width)*0.9 && y2-y1 <= float64(height)*0.9 || z == minZoom {
+			v.Zoom = z
+			v.CenterX, v.CenterY = (x1+x2)/2, (y1+y2)/2
+			break
+		}
+	}
+
+	return v
+}
+
+// The box around the coordinates of the contracts, ok is false when none of
+// them has any
+func coordsBox(entries []Entry) ([4]float64, bool) {
+	box := [4]float64{90, 180, -90, -180}
+	ok := false
+	for _, e := range entries {
+		for _, c := range e.Coords {
+			box[0], box[1] = min(box[0], c.Latitude), min(box[1], c.Longitude)
+			box[2], box[3] = max(box[2], c.Latitude), max(box[3], c.Longitude)
+			ok = true
+		}
+	}
+
+	return box, ok
+}
+
+// A field on the map, its rings as exportRings gives them so the holes wind
+// the other way and stay empty
+type mapShape struct {
+	ID     uint
+	Parts  [][][][2]float64
+	Colour color.NRGBA
+}
+
+// Fetches a tile, nil when there isn't one
+type tileSource func(z, x, y int) image.Image
+
+var mapBackground = color.NRGBA{R: 0xe8, G: 0xe4, B: 0xd8, A: 0xff}
+
+// Draws the tiles and the fields seen through the viewport. A missing tile
+// is cut out of the nearest zoom out that has it.
+func renderMap(v mapViewport, tiles tileSource, shapes []mapShape) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, v.Width, v.Height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(mapBackground), image.Point{}, draw.Src)
+
+	ox, oy := v.origin()
+	if tiles != nil {
+		n := 1 << v.Zoom
+		for ty := int(math.Floor(oy / tileSize)); float64(ty*tileSize) < oy+float64(v.Height); ty++ {
+			if ty < 0 || ty >= n {
+				continue
+			}
+			for tx := int(math.Floor(ox / tileSize)); float64(tx*tileSize) < ox+float64(v.Width); tx++ {
+				at := image.Pt(tx*tileSize-int(math.Floor(ox)), ty*tileSize-int(math.Floor(oy)))
+				drawTile(dst, at, tiles, v.Zoom, ((tx%n)+n)%n, ty)
+			}
+		}
+	}
+
+	z := vector.NewRasterizer(0, 0)
+	for _, s := range shapes {
+		drawShape(dst, z, v, ox, oy, s)
+	}
+
+	return dst
+}
+
+func drawTile(dst *image.RGBA, at image.Point, tiles tileSource, zoom, x, y int) {
+	r := image.Rectangle{Min: at, Max: at.Add(image.Pt(tileSize, tileSize))}
+	for up := 0; up <= maxOverzoom && up <= zoom; up++ {
+		img := tiles(zoom-up, x>>up, y>>up)
+		if img == nil {
+			continue
+		}
+
+		// the part of the bigger tile that covers this one, the tiles may
+		// be 512 pixels for high resolution screens
+		b := img.Bounds()
+		size := b.Dx() >> up
+		sr := image.Rect(0, 0, size, size).Add(b.Min).Add(image.Pt((x&(1<<up-1))*size, (y&(1<<up-1))*size))
+		if up == 0 && b.Dx() == tileSize {
+			draw.Draw(dst, r, img, b.Min, draw.Src)
+		} else {
+			xdraw.NearestNeighbor.Scale(dst, r, img, sr, draw.Src, nil)
+		}
+		return
+	}
+}
+
+func drawShape(dst *image.RGBA, z *vector.Rasterizer, v mapViewport, ox, oy float64, s mapShape) {
+	var pts [][][2]float32
+	bounds := image.Rectangle{}
+	for _, part := range s.Parts {
+		for _, r := range part {
+			var ring [][2]float32
+			for _, p := range r {
+				x, y := worldPixel(p[1], p[0], v.Zoom)
+				px, py := float32(x-ox), float32(y-oy)
+				ring = append(ring, [2]float32{px, py})
+				pt := image.Pt(int(px), int(py))
+				if bounds.Empty() {
+					bounds = image.Rectangle{Min: pt, Max: pt.Add(image.Pt(1, 1))}
+				}
+				bounds = bounds.Union(image.Rectangle{Min: pt, Max: pt.Add(image.Pt(1, 1))})
+			}
+			pts = append(pts, ring)
+		}
+	}
+
+	// room for the outline
+	r := bounds.Inset(-2).Intersect(dst.Bounds())
+	if r.Empty() {
+		return
+	}
+	off := [2]float32{float32(r.Min.X), float32(r.Min.Y)}
+
+	fill := s.Colour
+	fill.A = 0x66
+	z.Reset(r.Dx(), r.Dy())
+	for _, ring := range pts {
+		z.MoveTo(ring[0][0]-off[0], ring[0][1]-off[1])
+		for _, p := range ring[1:] {
+			z.LineTo(p[0]-off[0], p[1]-off[1])
+		}
+		z.ClosePath()
+	}
+	z.Draw(dst, r, image.NewUniform(fill), image.Point{})
+
+	// the outline as a thin quad along every edge
+	const half = 1
+	z.Reset(r.Dx(), r.Dy())
+	for _, ring := range pts {
+		for i := 0; i+1 < len(ring); i++ {
+			a, b := ring[i], ring[i+1]
+			dx, dy := b[0]-a[0], b[1]-a[1]
+			l := float32(math.Hypot(float64(dx), float64(dy)))
+			if l == 0 {
+				continue
+			}
+			nx, ny := -dy/l*half, dx/l*half
+			z.MoveTo(a[0]+nx-off[0], a[1]+ny-off[1])
+			z.LineTo(b[0]+nx-off[0], b[1]+ny-off[1])
+			z.LineTo(b[0]-nx-off[0], b[1]-ny-off[1])
+			z.LineTo(a[0]-nx-off[0], a[1]-ny-off[1])
+			z.ClosePath()
+		}
+	}
+	z.Draw(dst, r, image.NewUniform(s.Colour), image.Point{})
+}
+
+// The fields of the contracts, coloured by their status
+func mapShapes(entries []Entry) []mapShape {
+	var shapes []mapShape
+	for _, e := range entries {
+		parts := exportRings(e.Coords)
+		if len(parts) == 0 {
+			continue
+		}
+		shapes = append(shapes, mapShape{ID: e.ID, Parts: parts, Colour: statusColors[e.Status]})
+	}
+
+	return shapes
+}
+
+// The contracts whose field has the point, the smallest field first so the
+// one inside another is found
+func entriesAt(entries []Entry, lat, lon float64) []Entry {
+	type hit struct {
+		e    Entry
+		area float64
+	}
+
+	var hits []hit
+	for _, e := range entries {
+		if shapeContains(shapePolygons(e.Coords), point{lon, lat}) {
+			hits = append(hits, hit{e, ellipsoidalArea(e.Coords)})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].area < hits[j].area
+	})
+
+	found := make([]Entry, 0, len(hits))
+	for _, h := range hits {
+		found = append(found, h.e)
+	}
+
+	return found
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorldPixel(t *testing.T) {
+	t.Parallel()
+
+	x, y := worldPixel(0, 0, 0)
+	if x != 128 || math.Abs(y-128) > 1e-9 {
+		t.Errorf("0, 0 at zoom 0 = %v, %v, want the middle of the tile", x, y)
+	}
+	x, y = worldPixel(maxMercatorLat, -180, 2)
+	if x != 0 || math.Abs(y) > 1e-6 {
+		t.Errorf("the north west corner at zoom 2 = %v, %v, want 0, 0", x, y)
+	}
+	if _, y := worldPixel(89, 0, 0); math.Abs(y) > 1e-6 {
+		t.Errorf("latitudes past the edge must stay on it, got y %v", y)
+	}
+
+	x, y = worldPixel(37.9838, 23.7275, 15)
+	lat, lon := latLonAt(x, y, 15)
+	if math.Abs(lat-37.9838) > 1e-9 || math.Abs(lon-23.7275) > 1e-9 {
+		t.Errorf("round trip = %v, %v", lat, lon)
+	}
+}
+
+func TestMapViewport_ZoomAtKeepsThePlace(t *testing.T) {
+	t.Parallel()
+
+	x, y := worldPixel(38, 23, 10)
+	v := mapViewport{Zoom: 10, CenterX: x, CenterY: y, Width: 400, Height: 300}
+	lat, lon := v.latLonAt(100, 50)
+
+	in := v.zoomAt(2, 100, 50, 0, 19)
+	if in.Zoom != 12 {
+		t.Fatalf("zoom = %d, want 12", in.Zoom)
+	}
+	gotLat, gotLon := in.latLonAt(100, 50)
+	if math.Abs(gotLat-lat) > 1e-9 || math.Abs(gotLon-lon) > 1e-9 {
+		t.Errorf("the place under the cursor moved from %v, %v to %v, %v", lat, lon, gotLat, gotLon)
+	}
+
+	if got := v.zoomAt(5, 0, 0, 0, 12).Zoom; got != 12 {
+		t.Errorf("zoom past the last = %d, want 12", got)
+	}
+}
+
+func TestFitViewport(t *testing.T) {
+	t.Parallel()
+
+	box := [4]float64{38, 23, 38.01, 23.02}
+	v := fitViewport(box, 400, 300, 0, 19)
+
+	lat, lon := v.latLonAt(0, 0)
+	if lat < box[2] || lon > box[1] {
+		t.Errorf("the top left %v, %v is inside the box", lat, lon)
+	}
+	lat, lon = v.latLonAt(400, 300)
+	if lat > box[0] || lon < box[3] {
+		t.Errorf("the bottom right %v, %v is inside the box", lat, lon)
+	}
+
+	x1, y1 := worldPixel(box[2], box[1], v.Zoom+1)
+	x2, y2 := worldPixel(box[0], box[3], v.Zoom+1)
+	if x2-x1 <= 360 && y2-y1 <= 270 {
+		t.Errorf("zoom %d is not the biggest that fits", v.Zoom)
+	}
+}
+
+func pngTile(t *testing.T, c color.Color) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+	for y := range tileSize {
+		for x := range tileSize {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeMBTiles(t *testing.T, path string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("unexpected error closing the DB: %v", err)
+		}
+	}()
+
+	for _, q := range []string{
+		`CREATE TABLE metadata (name TEXT, value TEXT)`,
+		`CREATE TABLE tiles (zoom_level INTEGER, tile_column INTEGER, tile_row INTEGER, tile_data BLOB)`,
+		`INSERT INTO metadata VALUES ('name', 'Κάμπος'), ('format', 'png'), ('bounds', '20.5, 35, 28.5,41.5')`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the south east tile of zoom 1
+	if _, err := db.Exec(`INSERT INTO tiles VALUES (1, 1, 0, ?)`, pngTile(t, color.RGBA{R: 0xff, A: 0xff})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO tiles VALUES (3, 0, 0, ?)`, pngTile(t, color.RGBA{B: 0xff, A: 0xff})); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMBTiles_TileRowsFromTheSouth(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "test.mbtiles")
+	writeMBTiles(t, path)
+
+	m, err := openMBTiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := m.Close(); err != nil {
+			t.Errorf("unexpected error closing the tileset: %v", err)
+		}
+	}()
+
+	if m.Name != "Κάμπος" || m.Format != "png" || m.MinZoom != 1 || m.MaxZoom != 3 {
+		t.Errorf("metadata = %q %q %d-%d", m.Name, m.Format, m.MinZoom, m.MaxZoom)
+	}
+	if m.Bounds != [4]float64{20.5, 35, 28.5, 41.5} {
+		t.Errorf("bounds = %v", m.Bounds)
+	}
+
+	img, err := m.Tile(1, 1, 1)
+	if err != nil || img == nil {
+		t.Fatalf("tile 1/1/1 = %v, %v", img, err)
+	}
+	if r, _, _, _ := img.At(10, 10).RGBA(); r != 0xffff {
+		t.Errorf("tile 1/1/1 is not the red one")
+	}
+	if img, err := m.Tile(1, 1, 0); img != nil || err != nil {
+		t.Errorf("tile 1/1/0 = %v, %v, want none", img, err)
+	}
+}
+
+func TestImportBasemap(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if m, err := openBasemap(dir); m != nil || err != nil {
+		t.Fatalf("no basemap = %v, %v", m, err)
+	}
+
+	src := filepath.Join(t.TempDir(), "test.mbtiles")
+	writeMBTiles(t, src)
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := importBasemap(dir, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := importBasemap(dir, strings.NewReader("not a tileset")); err == nil {
+		t.Error("expected an error for a file that is not a tileset")
+	}
+	m, err = openBasemap(dir)
+	if err != nil || m == nil {
+		t.Fatalf("the old basemap is gone: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("files left behind: %v", files)
+	}
+}
+
+func TestRenderMap(t *testing.T) {
+	t.Parallel()
+
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	// only zoom 0 has tiles, zoom 1 is cut out of it
+	tiles := func(z, x, y int) image.Image {
+		if z != 0 {
+			return nil
+		}
+		img := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+		for py := range tileSize {
+			for px := range tileSize {
+				img.Set(px, py, blue)
+			}
+		}
+		return img
+	}
+
+	outer := corners(0, 0, -60, -120, -60, 120, 60, 120, 60, -120)
+	hole := corners(0, 1, -20, -40, -20, 40, 20, 40, 20, -40)
+	shapes := mapShapes([]Entry{{ID: 1, Status: statusExpired, Coords: append(outer, hole...)}})
+
+	v := mapViewport{Zoom: 1, CenterX: 256, CenterY: 256, Width: 512, Height: 512}
+	img := renderMap(v, tiles, shapes)
+
+	if got := img.RGBAAt(256, 256); got != blue {
+		t.Errorf("the hole = %v, want the tile", got)
+	}
+	x, y := worldPixel(0, -80, 1)
+	if got := img.RGBAAt(int(x), int(y)); got == blue || got.R == 0 {
+		t.Errorf("the field = %v, want the colour of its status over the tile", got)
+	}
+	x, y = worldPixel(75, -150, 1)
+	if got := img.RGBAAt(int(x), int(y)); got != blue {
+		t.Errorf("outside the field = %v, want the tile", got)
+	}
+
+	// no tiles at all
+	if got := renderMap(v, nil, nil).RGBAAt(5, 5); color.NRGBAModel.Convert(got) != mapBackground {
+		t.Errorf("background = %v", got)
+	}
+}
+
+func TestEntriesAt(t *testing.T) {
+	t.Parallel()
+
+	big := Entry{ID: 1, Coords: corners(0, 0, 37.99, 22.99, 38.02, 22.99, 38.02, 23.02, 37.99, 23.02)}
+	small := Entry{ID: 2, Coords: corners(0, 0, 38.005, 23.005, 38.006, 23.005, 38.006, 23.006, 38.005, 23.006)}
+	holed := Entry{ID: 3, Coords: fieldWithHole()}
+	none := Entry{ID: 4}
+	entries := []Entry{big, small, holed, none}
+
+	var ids []uint
+	for _, e := range entriesAt(entries, 38.0055, 23.0055) {
+		ids = append(ids, e.ID)
+	}
+	if len(ids) != 3 || ids[0] != 2 || ids[2] != 1 {
+		t.Errorf("entries at the small field = %v, want 2 first and 1 last", ids)
+	}
+
+	// in the hole of 3
+	ids = nil
+	for _, e := range entriesAt(entries, 38.003, 23.003) {
+		ids = append(ids, e.ID)
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("entries in the hole = %v, want 1", ids)
+	}
+
+	if got := entriesAt(entries, 39, 23); len(got) != 0 {
+		t.Errorf("entries away from the fields = %v", got)
+	}
+}
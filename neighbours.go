@@ -0,0 +1,134 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// Edges of neighbouring fields this many metres apart or closer count as
+// one boundary, fields drawn apart never line up exactly
+const boundaryTolerance = 0.5
+
+// Fields that share less boundary than that only meet at a corner
+const minSharedBoundary = 1.0
+
+// Two contracts whose fields overlap or border each other while both run
+type FieldPair struct {
+	A, B    Entry
+	Overlap float64 // στρέμματα
+	Shared  float64 // metres of common boundary
+}
+
+// The fields share some area, a data error or the same land leased twice.
+// A sliver along the common boundary no wider than the tolerance is how
+// neighbours are drawn, not an overlap.
+func (p FieldPair) Overlapping() bool {
+	return p.Overlap*squareMetresPerStremma > p.Shared*boundaryTolerance
+}
+
+func (p FieldPair) Relation() string {
+	if p.Overlapping() {
+		return "Επικάλυψη"
+	}
+	return "Γειτονικά"
+}
+
+// The pairs of contracts that run at the same time and whose fields overlap
+// or share a boundary, the overlaps first with the biggest on top, then the
+// neighbours with the longest boundary. A sublease is on the land of its
+// parent so the two are not a pair.
+func fieldPairs(entries []Entry) []FieldPair {
+	type field struct {
+		entry Entry
+		polys []polygon
+		box   [4]float64
+	}
+
+	var fields []field
+	for _, e := range entries {
+		polys := shapePolygons(e.Coords)
+		if len(polys) == 0 {
+			continue
+		}
+		box, _ := coordsBox([]Entry{e})
+		fields = append(fields, field{e, polys, box})
+	}
+
+	var pairs []FieldPair
+	for i, a := range fields {
+		for _, b := range fields[i+1:] {
+			if a.entry.ParentID == b.entry.ID || b.entry.ParentID == a.entry.ID || !periodsOverlap(a.entry, b.entry) {
+				continue
+			}
+
+			// in metres around a corner of the first, a few metres of slack
+			// in degrees for the boxes
+			origin := a.polys[0].outer[0]
+			perLat, perLon := metresPerDegree(origin.y)
+			slackLat, slackLon := 2*boundaryTolerance/perLat, 2*boundaryTolerance/perLon
+			if a.box[0] > b.box[2]+slackLat || b.box[0] > a.box[2]+slackLat ||
+				a.box[1] > b.box[3]+slackLon || b.box[1] > a.box[3]+slackLon {
+				continue
+			}
+			pa, pb := localMetres(a.polys, origin), localMetres(b.polys, origin)
+
+			p := FieldPair{A: a.entry, B: b.entry}
+			if shared := shapesIntersectionArea(pa, pb); shared > min(shapeArea(pa), shapeArea(pb))*1e-6 {
+				p.Overlap = shared / squareMetresPerStremma
+			}
+			p.Shared = sharedBoundary(pa, pb, boundaryTolerance)
+			if p.Overlap > 0 || p.Shared >= minSharedBoundary {
+				pairs = append(pairs, p)
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].Overlapping() != pairs[j].Overlapping() {
+			return pairs[i].Overlapping()
+		}
+		if pairs[i].Overlap != pairs[j].Overlap {
+			return pairs[i].Overlap > pairs[j].Overlap
+		}
+		return pairs[i].Shared > pairs[j].Shared
+	})
+
+	return pairs
+}
+
+func fieldPairsReport(db *sql.DB, statuses []string) ([]FieldPair, error) {
+	entries, err := getAllEntries(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return fieldPairs(filterByStatus(entries, statuses)), nil
+}
+
+var fieldPairsHeader = []string{"Συμβόλαιο", "Συμβόλαιο", "Σχέση", "Επικάλυψη στρ.", "Κοινό όριο μ."}
+
+func fieldPairsRecords(pairs []FieldPair) [][]string {
+	records := make([][]string, 0, len(pairs))
+	for _, p := range pairs {
+		records = append(records, []string{
+			contractLabel(p.A),
+			contractLabel(p.B),
+			p.Relation(),
+			fmt.Sprintf("%.3f", p.Overlap),
+			fmt.Sprintf("%.1f", p.Shared),
+		})
+	}
+
+	return records
+}
+
+func fieldPairsCSV(pairs []FieldPair) ([]byte, error) {
+	return csvBytes(fieldPairsHeader, fieldPairsRecords(pairs))
+}
+
+func fieldPairsPDF(pairs []FieldPair) ([]byte, error) {
+	pdf := newReportPDF("L", "Επικαλύψεις και γειτονικά αγροτεμάχια")
+	pdfTable(pdf, fieldPairsHeader, []float64{80, 80, 35, 35, 35}, fieldPairsRecords(pairs))
+
+	return pdfBytes(pdf)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// A rectangle between the latitudes and the longitudes
+func rectangle(lat1, lon1, lat2, lon2 float64) []Coordinates {
+	return corners(0, 0, lat1, lon1, lat1, lon2, lat2, lon2, lat2, lon1)
+}
+
+func TestFieldPairs(t *testing.T) {
+	t.Parallel()
+
+	field := func(id uint, coords []Coordinates) Entry {
+		return Entry{ID: id, Number: fmt.Sprint(id), Start: "01-10-2025", End: "30-09-2030", Status: statusActive, Coords: coords}
+	}
+	a := field(1, rectangle(38, 23, 38.001, 23.001))
+	b := field(2, rectangle(38, 23.001, 38.001, 23.002))
+	c := field(3, rectangle(38.0002, 23.0004, 38.0008, 23.0015))
+	sublease := field(4, rectangle(38.0001, 23.0001, 38.0009, 23.0003))
+	sublease.ParentID = a.ID
+	earlier := field(5, rectangle(38, 23.001, 38.001, 23.002))
+	earlier.Start, earlier.End = "01-10-2015", "30-09-2020"
+	// north of a, drawn a third of a metre into it
+	sliver := field(6, rectangle(38.000997, 23, 38.002, 23.001))
+	far := field(7, rectangle(39, 23, 39.001, 23.001))
+
+	pairs := fieldPairs([]Entry{a, b, c, sublease, earlier, sliver, far, {ID: 8}})
+
+	var got []string
+	for _, p := range pairs {
+		got = append(got, fmt.Sprintf("%d-%d %s", p.A.ID, p.B.ID, p.Relation()))
+	}
+	want := []string{"1-3 Επικάλυψη", "2-3 Επικάλυψη", "1-6 Γειτονικά", "1-2 Γειτονικά"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("pairs = %v, want %v", got, want)
+	}
+
+	// 0.0006° of longitude by 0.0006° of latitude at 38°
+	perLat, perLon := metresPerDegree(38.0005)
+	if want := 0.0006 * perLon * 0.0006 * perLat / 1000; math.Abs(pairs[0].Overlap-want) > 0.01 {
+		t.Errorf("overlap of 1 and 3 = %.3f στρ., want %.3f", pairs[0].Overlap, want)
+	}
+	if pairs[2].Overlap == 0 {
+		t.Error("the sliver of 6 should still be in the report")
+	}
+	if want := 0.001 * perLat; math.Abs(pairs[3].Shared-want) > 0.5 {
+		t.Errorf("boundary of 1 and 2 = %.1f m, want %.1f", pairs[3].Shared, want)
+	}
+}
+
+func TestFieldPairsRecords(t *testing.T) {
+	t.Parallel()
+
+	pairs := []FieldPair{{A: Entry{Number: "2025/1"}, B: Entry{Number: "2025/2"}, Overlap: 1.23456, Shared: 12.34}}
+	records := fieldPairsRecords(pairs)
+	if len(records) != 1 || records[0][2] != "Επικάλυψη" || records[0][3] != "1.235" || records[0][4] != "12.3" {
+		t.Errorf("records = %v", records)
+	}
+	if _, err := fieldPairsCSV(pairs); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Who can give notice to end the contract
+const (
+	noticeBoth   = "both"
+	noticeOwner  = "owner"
+	noticeRenter = "renter"
+)
+
+var noticeParties = []string{noticeBoth, noticeOwner, noticeRenter}
+
+var noticePartyLabels = map[string]string{
+	noticeBoth:   "Και οι δύο",
+	noticeOwner:  "Εκμισθωτής",
+	noticeRenter: "Μισθωτής",
+}
+
+// We are reminded that many days before the last day to give notice
+const noticeReminderDays = 30
+
+// The last day to give notice so the contract ends on its end date instead of
+// renewing itself, ok is false when the contract doesn't renew itself
+func noticeDeadline(e Entry) (time.Time, bool) {
+	if e.AutoRenewMonths <= 0 {
+		return time.Time{}, false
+	}
+	end, err := parseDate(e.ActualEnd())
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// counted back from the day after the end, so the 31st doesn't spill over
+	// into the next month
+	return end.AddDate(0, 0, 1).AddDate(0, -e.NoticeMonths, -1), true
+}
+
+// The notice clause has to fit in the contract
+func validateNotice(e Entry) error {
+	if e.NoticeMonths < 0 || e.AutoRenewMonths < 0 {
+		return errors.New("the notice period and the renewal term cannot be negative")
+	}
+	if _, ok := noticePartyLabels[e.noticeParty()]; !ok {
+		return fmt.Errorf("unknown notice party: %s", e.NoticeParty)
+	}
+	if deadline, ok := noticeDeadline(e); ok {
+		start, err := parseDate(e.Start)
+		if err == nil && deadline.Before(start) {
+			return fmt.Errorf("the notice period of %d months is longer than the contract", e.NoticeMonths)
+		}
+	}
+
+	return nil
+}
+
+// Contracts that still renew themselves, the ones that were terminated,
+// renewed by hand or are drafts are left alone
+func autoRenews(e Entry) bool {
+	switch e.Status {
+	case statusDraft, statusTerminated, statusRenewed:
+		return false
+	}
+	for _, a := range e.Amendments {
+		if a.Kind == amendTermination {
+			return false
+		}
+	}
+
+	return e.AutoRenewMonths > 0
+}
+
+// The extensions of the contract for every notice deadline that passed
+// before today without a termination, each one for the auto renewal term
+func pendingExtensions(e Entry, today time.Time) []Amendment {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	var extensions []Amendment
+	for autoRenews(e) {
+		deadline, ok := noticeDeadline(e)
+		if !ok || !today.After(deadline) {
+			break
+		}
+		end, err := parseDate(e.ActualEnd())
+		if err != nil {
+			break
+		}
+
+		start := end.AddDate(0, 0, 1)
+		a := Amendment{
+			EntryID:   e.ID,
+			Kind:      amendExtension,
+			Effective: start.Format(dateLayout),
+			End:       start.AddDate(0, e.AutoRenewMonths, -1).Format(dateLayout),
+			Notes:     fmt.Sprintf("no notice by %s", deadline.Format(dateLayout)),
+		}
+		extensions = append(extensions, a)
+		e.Amendments = append(e.Amendments, a)
+	}
+
+	return extensions
+}
+
+// Reminders for the contracts whose last day to give notice is close
+func noticeReminders(entries []Entry, today time.Time) []string {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	var reminders []string
+	for _, e := range entries {
+		if !autoRenews(e) {
+			continue
+		}
+		deadline, ok := noticeDeadline(e)
+		if !ok {
+			continue
+		}
+
+		days := daysBetween(today, deadline)
+		if days < 0 || days > noticeReminderDays {
+			continue
+		}
+		reminders = append(reminders, fmt.Sprintf("%s: notice by %s (%d days, %s) or it renews for %d months",
+			contractLabel(e), deadline.Format(dateLayout), days, noticePartyLabels[e.noticeParty()], e.AutoRenewMonths))
+	}
+
+	return reminders
+}
+
+func (e Entry) noticeParty() string {
+	if e.NoticeParty == "" {
+		return noticeBoth
+	}
+	return e.NoticeParty
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func autoRenewing() Entry {
+	return Entry{
+		ID:              1,
+		Name:            "Κάμπος",
+		Status:          statusActive,
+		Start:           "01-01-2024",
+		End:             "31-12-2025",
+		NoticeMonths:    3,
+		AutoRenewMonths: 12,
+		NoticeParty:     noticeRenter,
+	}
+}
+
+func TestNoticeDeadline(t *testing.T) {
+	t.Parallel()
+
+	e := autoRenewing()
+	deadline, ok := noticeDeadline(e)
+	if !ok || deadline.Format(dateLayout) != "30-09-2025" {
+		t.Fatalf("noticeDeadline() = %v, %v, want 30-09-2025", deadline, ok)
+	}
+
+	e.AutoRenewMonths = 0
+	if _, ok := noticeDeadline(e); ok {
+		t.Fatal("a contract that doesn't renew itself has no deadline")
+	}
+}
+
+func TestPendingExtensions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		edit  func(*Entry)
+		today string
+		ends  []string
+	}{
+		{"before the deadline", nil, "30-09-2025", nil},
+		{"after the deadline", nil, "01-10-2025", []string{"31-12-2026"}},
+		{"missed two deadlines", nil, "15-10-2026", []string{"31-12-2026", "31-12-2027"}},
+		{"already extended", func(e *Entry) {
+			e.Amendments = []Amendment{{Kind: amendExtension, Effective: "01-01-2026", End: "31-12-2026"}}
+		}, "01-10-2025", nil},
+		{"terminated", func(e *Entry) {
+			e.Amendments = []Amendment{{Kind: amendTermination, Effective: "31-12-2025"}}
+		}, "01-10-2025", nil},
+		{"draft", func(e *Entry) { e.Status = statusDraft }, "01-10-2025", nil},
+		{"renewed by hand", func(e *Entry) { e.Status = statusRenewed }, "01-10-2025", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := autoRenewing()
+			if tt.edit != nil {
+				tt.edit(&e)
+			}
+
+			got := pendingExtensions(e, date(tt.today))
+			if len(got) != len(tt.ends) {
+				t.Fatalf("got %d extensions, want %d: %+v", len(got), len(tt.ends), got)
+			}
+			for i, a := range got {
+				if a.Kind != amendExtension || a.End != tt.ends[i] || a.EntryID != e.ID {
+					t.Fatalf("extension %d = %+v, want one until %s", i, a, tt.ends[i])
+				}
+			}
+			if len(got) > 0 && got[0].Effective != "01-01-2026" {
+				t.Fatalf("the first extension starts %s, want 01-01-2026", got[0].Effective)
+			}
+		})
+	}
+}
+
+func TestNoticeReminders(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{autoRenewing()}
+
+	if got := noticeReminders(entries, date("30-08-2025")); len(got) != 0 {
+		t.Fatalf("too early for a reminder, got %v", got)
+	}
+	got := noticeReminders(entries, date("10-09-2025"))
+	if len(got) != 1 || !strings.Contains(got[0], "30-09-2025") || !strings.Contains(got[0], noticePartyLabels[noticeRenter]) {
+		t.Fatalf("unexpected reminders: %v", got)
+	}
+	if got := noticeReminders(entries, date("01-10-2025")); len(got) != 0 {
+		t.Fatalf("the deadline passed, got %v", got)
+	}
+}
+
+func TestValidateNotice(t *testing.T) {
+	t.Parallel()
+
+	e := autoRenewing()
+	if err := validateNotice(e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	e.NoticeMonths = 30
+	if err := validateNotice(e); err == nil {
+		t.Fatal("a notice longer than the contract should fail")
+	}
+
+	e = autoRenewing()
+	e.NoticeParty = "nobody"
+	if err := validateNotice(e); err == nil {
+		t.Fatal("an unknown party should fail")
+	}
+}
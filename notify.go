@@ -22,6 +22,14 @@ func notify(appState *AppState) {
 }
 
 func checkEndDateNotification(appState *AppState) {
+	// before the statuses, an extended contract isn't expiring anymore
+	if _, err := applyAutoRenewals(appState.db, time.Now()); err != nil {
+		log.Printf("Error renewing the contracts automatically: %v", err)
+	}
+	if err := refreshStatuses(appState.db, time.Now()); err != nil {
+		log.Printf("Error refreshing the contract statuses: %v", err)
+	}
+
 	entries, err := getAllEntries(appState.db)
 	if err != nil {
 		log.Println("Error getting all entries from db!")
@@ -29,24 +37,59 @@ func checkEndDateNotification(appState *AppState) {
 
 	var notifications []string
 	for _, e := range entries {
-		endTime, err := time.Parse("02-01-2006", e.End)
+		// nobody to renew with when the owners farm it themselves
+		if !e.Rules().Renters {
+			continue
+		}
+		endTime, err := parseDate(e.ActualEnd())
 		if err != nil {
 			log.Printf("Error parsing date for entry %d: %v", e.ID, err)
 			continue
 		}
 		daysLeft := int(math.Ceil(time.Until(endTime).Hours() / 24))
 
-		if daysLeft <= 30 && daysLeft > 0 {
-			notifications = append(notifications, fmt.Sprintf("%s ends in %d days (%s)", e.Name, daysLeft, e.End))
+		if daysLeft <= expiringDays && daysLeft > 0 {
+			notifications = append(notifications, fmt.Sprintf("%s ends in %d days (%s)", contractLabel(e), daysLeft, e.ActualEnd()))
 		}
+	}
 
-		if len(notifications) > 0 {
-			content := strings.Join(notifications, "\n")
-			appState.app.SendNotification(&fyne.Notification{
-				Title:   "End dates approaching!",
-				Content: content,
-			})
-			log.Println("Notification for end dates sent!")
-		}
+	if len(notifications) > 0 {
+		content := strings.Join(notifications, "\n")
+		appState.app.SendNotification(&fyne.Notification{
+			Title:   "End dates approaching!",
+			Content: content,
+		})
+		log.Println("Notification for end dates sent!")
+	}
+
+	checkNoticeNotification(appState, entries)
+	checkDepositsNotification(appState, entries)
+}
+
+func checkNoticeNotification(appState *AppState, entries []Entry) {
+	reminders := noticeReminders(entries, time.Now())
+	if len(reminders) > 0 {
+		appState.app.SendNotification(&fyne.Notification{
+			Title:   "Notice deadlines approaching!",
+			Content: strings.Join(reminders, "\n"),
+		})
+		log.Println("Notification for notice deadlines sent!")
+	}
+}
+
+func checkDepositsNotification(appState *AppState, entries []Entry) {
+	deposits, err := getHeldDeposits(appState.db)
+	if err != nil {
+		log.Printf("Error getting the held deposits: %v", err)
+		return
+	}
+
+	reminders := depositReminders(entries, deposits, time.Now())
+	if len(reminders) > 0 {
+		appState.app.SendNotification(&fyne.Notification{
+			Title:   "Deposits to return!",
+			Content: strings.Join(reminders, "\n"),
+		})
+		log.Println("Notification for deposits sent!")
 	}
 }
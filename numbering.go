@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// How the contracts are numbered when they are created, ex. ΜΙΣ-2025/0007
+type NumberingScheme struct {
+	Prefix string
+	Year   bool // the year of creation goes in the number
+	Reset  bool // the sequence starts over every year, needs Year
+	Digits int  // the sequence is padded with zeros to that many digits
+}
+
+var defaultNumberingScheme = NumberingScheme{Prefix: "ΜΙΣ", Year: true, Reset: true, Digits: 4}
+
+func (s NumberingScheme) Validate() error {
+	if s.Reset && !s.Year {
+		return errors.New("the sequence can only start over every year when the year is in the number")
+	}
+	if s.Digits < 1 || s.Digits > 9 {
+		return errors.New("the digits of the sequence have to be from 1 to 9")
+	}
+	if strings.ContainsAny(s.Prefix, "/\\") {
+		return errors.New("the prefix cannot contain slashes")
+	}
+
+	return nil
+}
+
+// The sequence the number is taken from, every year has its own when it resets
+func (s NumberingScheme) Series(year int) (string, int) {
+	if s.Reset {
+		return s.Prefix, year
+	}
+	return s.Prefix, 0
+}
+
+func (s NumberingScheme) Format(year, number int) string {
+	n := fmt.Sprintf("%0*d", s.Digits, number)
+	if s.Year {
+		n = fmt.Sprintf("%d/%s", year, n)
+	}
+	if s.Prefix != "" {
+		n = s.Prefix + "-" + n
+	}
+
+	return n
+}
+
+// The number and the name of the contract, how it's printed everywhere
+func contractLabel(e Entry) string {
+	if e.Number == "" {
+		return e.Name
+	}
+	return e.Number + " " + e.Name
+}
+
+// Contracts with the query in their number or name, case insensitive
+func searchEntries(entries []Entry, query string) []Entry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return entries
+	}
+
+	var found []Entry
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.Number), query) || strings.Contains(strings.ToLower(e.Name), query) {
+			found = append(found, e)
+		}
+	}
+
+	return found
+}
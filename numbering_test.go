@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNumberingScheme_Format(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scheme NumberingScheme
+		want   string
+	}{
+		{defaultNumberingScheme, "ΜΙΣ-2025/0007"},
+		{NumberingScheme{Prefix: "Α", Digits: 3}, "Α-007"},
+		{NumberingScheme{Year: true, Digits: 1}, "2025/7"},
+		{NumberingScheme{Prefix: "Β", Digits: 2}, "Β-07"},
+	}
+	for _, tt := range tests {
+		if got := tt.scheme.Format(2025, 7); got != tt.want {
+			t.Errorf("%+v: Format = %s, want %s", tt.scheme, got, tt.want)
+		}
+	}
+
+	if got := (NumberingScheme{Digits: 2}).Format(2025, 123); got != "123" {
+		t.Fatalf("a longer sequence shouldn't be cut, got %s", got)
+	}
+}
+
+func TestNumberingScheme_SeriesAndValidate(t *testing.T) {
+	t.Parallel()
+
+	if s, y := defaultNumberingScheme.Series(2025); s != "ΜΙΣ" || y != 2025 {
+		t.Fatalf("expected a series per year, got %s %d", s, y)
+	}
+	if s, y := (NumberingScheme{Prefix: "Α", Year: true, Digits: 4}).Series(2025); s != "Α" || y != 0 {
+		t.Fatalf("expected one series for every year, got %s %d", s, y)
+	}
+
+	if err := defaultNumberingScheme.Validate(); err != nil {
+		t.Fatalf("the default scheme should be valid: %v", err)
+	}
+	invalid := []NumberingScheme{
+		{Prefix: "Α", Reset: true, Digits: 4},
+		{Prefix: "Α", Digits: 0},
+		{Prefix: "Α/Β", Digits: 4},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", s)
+		}
+	}
+}
+
+func TestSearchEntries(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{ID: 1, Number: "ΜΙΣ-2025/0001", Name: "Κάμπος"},
+		{ID: 2, Number: "ΜΙΣ-2025/0002", Name: "Ρέμα"},
+		{ID: 3, Name: "Χωρίς αριθμό"},
+	}
+
+	tests := []struct {
+		query string
+		want  []uint
+	}{
+		{"", []uint{1, 2, 3}},
+		{"0002", []uint{2}},
+		{"μισ-2025", []uint{1, 2}},
+		{" κάμπος ", []uint{1}},
+		{"τίποτα", nil},
+	}
+	for _, tt := range tests {
+		got := searchEntries(entries, tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("%q: expected %v, got %+v", tt.query, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i].ID != tt.want[i] {
+				t.Errorf("%q: expected %v, got %+v", tt.query, tt.want, got)
+			}
+		}
+	}
+
+	if got := contractLabel(entries[0]); got != "ΜΙΣ-2025/0001 Κάμπος" {
+		t.Fatalf("unexpected label: %s", got)
+	}
+	if got := contractLabel(entries[2]); got != "Χωρίς αριθμό" {
+		t.Fatalf("unexpected label: %s", got)
+	}
+}
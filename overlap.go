@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Another contract for the same land over the same period
+type Conflict struct {
+	Entry   Entry
+	Reasons []string
+}
+
+func (c Conflict) String() string {
+	return contractLabel(c.Entry) + " (" + c.Entry.Start + " - " + c.Entry.ActualEnd() + "): " + strings.Join(c.Reasons, ", ")
+}
+
+// The periods of the contracts share at least one day
+func periodsOverlap(a, b Entry) bool {
+	aStart, err := parseDate(a.Start)
+	if err != nil {
+		return false
+	}
+	aEnd, err := parseDate(a.ActualEnd())
+	if err != nil {
+		return false
+	}
+	bStart, err := parseDate(b.Start)
+	if err != nil {
+		return false
+	}
+	bEnd, err := parseDate(b.ActualEnd())
+	if err != nil {
+		return false
+	}
+
+	return !aStart.After(bEnd) && !bStart.After(aEnd)
+}
+
+// The land of a contract, its own KAEK, ATAK and field and those of the
+// parcels it leases
+type contractLand struct {
+	kaek    map[string]bool
+	atak    map[string]bool
+	parcels map[string]bool
+	shape   []polygon
+}
+
+func landOf(e Entry) contractLand {
+	l := contractLand{kaek: map[string]bool{}, atak: map[string]bool{}, parcels: map[string]bool{}, shape: shapePolygons(e.Coords)}
+	add := func(kaek string, atak uint) {
+		if kaek != "" && kaek != "0" {
+			l.kaek[kaek] = true
+		}
+		if atak != 0 {
+			l.atak[strconv.FormatUint(uint64(atak), 10)] = true
+		}
+	}
+
+	add(e.KAEK, e.ATAK)
+	for _, p := range e.Parcels {
+		add(p.KAEK, p.ATAK)
+		if p.ID != 0 {
+			l.parcels[strconv.FormatUint(uint64(p.ID), 10)] = true
+		}
+		l.shape = append(l.shape, shapePolygons(p.Coords)...)
+	}
+
+	return l
+}
+
+func sharesKey(a, b map[string]bool) bool {
+	for k := range a {
+		if b[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// Contracts that lease the same land as e while it runs, by KAEK, ATAK,
+// parcel or meeting polygons, their own or those of their parcels.
+// Terminated and renewed contracts end with their dates so they are checked
+// like the rest.
+func findOverlaps(e Entry, entries []Entry) []Conflict {
+	var conflicts []Conflict
+	land := landOf(e)
+
+	for _, other := range entries {
+		if other.ID == e.ID || other.Status == statusDraft || !periodsOverlap(e, other) {
+			continue
+		}
+
+		// a sublease is on the land of its parent
+		if (e.ParentID != 0 && other.ID == e.ParentID) || (e.ID != 0 && other.ParentID == e.ID) {
+			continue
+		}
+		// subleases of the same parent share its parcel, only their polygons
+		// tell if they are on the same part of it
+		siblings := e.ParentID != 0 && e.ParentID == other.ParentID
+		otherLand := landOf(other)
+
+		var reasons []string
+		if !siblings && sharesKey(land.kaek, otherLand.kaek) {
+			reasons = append(reasons, "ίδιο ΚΑΕΚ")
+		}
+		if !siblings && sharesKey(land.atak, otherLand.atak) {
+			reasons = append(reasons, "ίδιο ΑΤΑΚ")
+		}
+		if !siblings && sharesKey(land.parcels, otherLand.parcels) {
+			reasons = append(reasons, "ίδιο αγροτεμάχιο")
+		}
+		if shapesOverlap(land.shape, otherLand.shape) {
+			reasons = append(reasons, "επικάλυψη συντεταγμένων")
+		}
+
+		if len(reasons) > 0 {
+			conflicts = append(conflicts, Conflict{Entry: other, Reasons: reasons})
+		}
+	}
+
+	return conflicts
+}
+
+// The conflicts that weren't accepted with a reason before
+func unacceptedConflicts(conflicts []Conflict, overrides []OverlapOverride) []Conflict {
+	accepted := make(map[uint]bool, len(overrides))
+	for _, o := range overrides {
+		accepted[o.OtherID] = true
+	}
+
+	var left []Conflict
+	for _, c := range conflicts {
+		if !accepted[c.Entry.ID] {
+			left = append(left, c)
+		}
+	}
+
+	return left
+}
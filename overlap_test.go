@@ -0,0 +1,127 @@
+package main
+
+import (
+	"testing"
+)
+
+func parcel(lon, lat float64) []Coordinates {
+	return []Coordinates{
+		{Latitude: lat, Longitude: lon},
+		{Latitude: lat, Longitude: lon + 0.001},
+		{Latitude: lat + 0.001, Longitude: lon + 0.001},
+		{Latitude: lat + 0.001, Longitude: lon},
+	}
+}
+
+func TestFindOverlaps(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{ID: 1, Name: "Νέο", KAEK: "111", ATAK: 5, Start: "01-01-2025", End: "31-12-2025", Coords: parcel(23, 38)}
+	entries := []Entry{
+		e,
+		{ID: 2, Name: "Ίδιο ΚΑΕΚ", KAEK: "111", Start: "01-06-2025", End: "31-05-2026", Status: statusActive},
+		{ID: 3, Name: "Ίδιο ΑΤΑΚ", ATAK: 5, Start: "01-01-2024", End: "01-01-2025", Status: statusExpired},
+		{ID: 4, Name: "Πολύγωνο", Start: "01-01-2025", End: "31-12-2025", Coords: parcel(23.0005, 38.0005)},
+		{ID: 5, Name: "Γειτονικό", Start: "01-01-2025", End: "31-12-2025", Coords: parcel(23.001, 38)},
+		{ID: 6, Name: "Άλλη περίοδος", KAEK: "111", Start: "01-01-2026", End: "31-12-2026"},
+		{ID: 7, Name: "Πρόχειρο", KAEK: "111", Start: "01-01-2025", End: "31-12-2025", Status: statusDraft},
+		{ID: 8, Name: "Λύθηκε νωρίς", KAEK: "111", Start: "01-01-2024", End: "31-12-2026",
+			Amendments: []Amendment{{Kind: amendTermination, Effective: "30-06-2024"}}},
+		{ID: 9, Name: "Χωρίς ΚΑΕΚ", KAEK: "0", Start: "01-01-2025", End: "31-12-2025"},
+	}
+
+	got := findOverlaps(e, entries)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 conflicts, got %+v", got)
+	}
+	want := map[uint]string{2: "ίδιο ΚΑΕΚ", 3: "ίδιο ΑΤΑΚ", 4: "επικάλυψη συντεταγμένων"}
+	for _, c := range got {
+		if len(c.Reasons) != 1 || c.Reasons[0] != want[c.Entry.ID] {
+			t.Fatalf("unexpected conflict with %d: %v", c.Entry.ID, c.Reasons)
+		}
+	}
+
+	// a new contract without an id isn't taken for itself
+	e.ID = 0
+	e.KAEK = "0"
+	e.ATAK = 0
+	e.Coords = nil
+	if got := findOverlaps(e, entries); len(got) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", got)
+	}
+}
+
+func TestConflictString(t *testing.T) {
+	t.Parallel()
+
+	c := Conflict{
+		Entry:   Entry{Name: "Κάμπος", Start: "01-01-2025", End: "31-12-2025", Amendments: []Amendment{{Kind: amendTermination, Effective: "30-06-2025"}}},
+		Reasons: []string{"ίδιο ΚΑΕΚ", "ίδιο ΑΤΑΚ"},
+	}
+	if got := c.String(); got != "Κάμπος (01-01-2025 - 30-06-2025): ίδιο ΚΑΕΚ, ίδιο ΑΤΑΚ" {
+		t.Fatalf("unexpected string: %s", got)
+	}
+}
+
+func TestFindOverlaps_Subleases(t *testing.T) {
+	t.Parallel()
+
+	parent := Entry{ID: 1, KAEK: "111", Start: "01-01-2025", End: "31-12-2025", Coords: parcel(23, 38)}
+	sub := Entry{ID: 2, ParentID: 1, KAEK: "111", Start: "01-01-2025", End: "31-12-2025", Coords: parcel(23, 38)}
+	sibling := Entry{ID: 3, ParentID: 1, KAEK: "111", Start: "01-01-2025", End: "31-12-2025", Coords: parcel(23.001, 38)}
+	stranger := Entry{ID: 4, KAEK: "111", Start: "01-01-2025", End: "31-12-2025"}
+	entries := []Entry{parent, sub, sibling, stranger}
+
+	// the parent and its own subleases don't conflict, a sibling only on the map
+	got := findOverlaps(sub, entries)
+	if len(got) != 1 || got[0].Entry.ID != 4 {
+		t.Fatalf("expected only the stranger, got %+v", got)
+	}
+	got = findOverlaps(parent, entries)
+	if len(got) != 1 || got[0].Entry.ID != 4 {
+		t.Fatalf("expected only the stranger, got %+v", got)
+	}
+
+	sibling.Coords = parcel(23.0005, 38)
+	got = findOverlaps(sub, []Entry{parent, sibling})
+	if len(got) != 1 || got[0].Reasons[0] != "επικάλυψη συντεταγμένων" {
+		t.Fatalf("expected the sibling on the map, got %+v", got)
+	}
+}
+
+func TestFindOverlaps_SharedParcel(t *testing.T) {
+	t.Parallel()
+
+	shared := Parcel{ID: 7, KAEK: "222", ATAK: 9, Coords: parcel(23, 38)}
+	e := Entry{ID: 1, KAEK: "111", ATAK: 5, Start: "01-01-2025", End: "31-12-2025", Parcels: []Parcel{{ID: 6, KAEK: "111"}, shared}}
+	entries := []Entry{
+		e,
+		{ID: 2, KAEK: "333", ATAK: 8, Start: "01-01-2025", End: "31-12-2025", Parcels: []Parcel{shared}},
+		{ID: 3, KAEK: "444", Start: "01-01-2025", End: "31-12-2025", Parcels: []Parcel{{ID: 8, Coords: parcel(23.0005, 38.0005)}}},
+		{ID: 4, KAEK: "555", Start: "01-01-2025", End: "31-12-2025", Parcels: []Parcel{{ID: 9, KAEK: "666", Coords: parcel(24, 38)}}},
+	}
+
+	got := findOverlaps(e, entries)
+	if len(got) != 2 || got[0].Entry.ID != 2 || got[1].Entry.ID != 3 {
+		t.Fatalf("expected conflicts with 2 and 3, got %+v", got)
+	}
+	if r := got[0].Reasons; len(r) != 4 || r[2] != "ίδιο αγροτεμάχιο" {
+		t.Errorf("the contract on the same parcel = %v", r)
+	}
+	if r := got[1].Reasons; len(r) != 1 || r[0] != "επικάλυψη συντεταγμένων" {
+		t.Errorf("the parcel drawn over the shared one = %v", r)
+	}
+}
+
+func TestUnacceptedConflicts(t *testing.T) {
+	t.Parallel()
+
+	conflicts := []Conflict{{Entry: Entry{ID: 2}}, {Entry: Entry{ID: 3}}}
+	got := unacceptedConflicts(conflicts, []OverlapOverride{{OtherID: 2, Reason: "the same land in two parts"}})
+	if len(got) != 1 || got[0].Entry.ID != 3 {
+		t.Fatalf("expected only the conflict with 3 left, got %+v", got)
+	}
+	if got := unacceptedConflicts(conflicts, nil); len(got) != 2 {
+		t.Fatalf("expected both conflicts without overrides, got %+v", got)
+	}
+}
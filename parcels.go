@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The field of a contract from before the parcels, or of a new contract that
+// didn't pick any
+func parcelFromEntry(e Entry) Parcel {
+	p := Parcel{KAEK: e.KAEK, ATAK: e.ATAK, Area: e.Size}
+	for _, c := range e.Coords {
+		p.Coords = append(p.Coords, Coordinates{Latitude: c.Latitude, Longitude: c.Longitude, Part: c.Part, Ring: c.Ring, Seq: c.Seq})
+	}
+
+	return p
+}
+
+// The parcels the contract is saved with, one of its own when it picked none
+func entryParcels(e Entry) []Parcel {
+	if len(e.Parcels) == 0 {
+		return []Parcel{parcelFromEntry(e)}
+	}
+
+	return e.Parcels
+}
+
+func validateParcel(p Parcel) error {
+	if p.KAEK == "" && p.ATAK == 0 {
+		return errors.New("the parcel needs a KAEK or an ATAK")
+	}
+	if p.Area < 0 {
+		return errors.New("the area of the parcel cannot be negative")
+	}
+
+	return nil
+}
+
+// How a parcel is printed, its KAEK or the ATAK when it has none
+func parcelLabel(p Parcel) string {
+	label := p.KAEK
+	if label == "" {
+		label = fmt.Sprintf("ΑΤΑΚ %d", p.ATAK)
+	}
+	if p.Municipality != "" {
+		label += " (" + p.Municipality + ")"
+	}
+
+	return label
+}
+
+// The contracts that leased the parcel, oldest first
+func parcelHistory(parcelID uint, entries []Entry) []Entry {
+	var history []Entry
+	for _, e := range entries {
+		for _, p := range e.Parcels {
+			if p.ID == parcelID {
+				history = append(history, e)
+				break
+			}
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return dateBefore(history[i].Start, history[j].Start)
+	})
+
+	return history
+}
+
+// The contracts that lease the parcel on the day without the drafts, usually
+// one but a field can be leased twice by mistake
+func parcelContractsOn(parcelID uint, entries []Entry, day time.Time) []Entry {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	var current []Entry
+	for _, e := range parcelHistory(parcelID, entries) {
+		if e.Status == statusDraft {
+			continue
+		}
+		start, errStart := parseDate(e.Start)
+		end, errEnd := parseDate(e.ActualEnd())
+		if errStart != nil || errEnd != nil || day.Before(start) || day.After(end) {
+			continue
+		}
+		current = append(current, e)
+	}
+
+	return current
+}
+
+// Parcels with the query in their KAEK, ATAK or municipality, case insensitive
+func searchParcels(parcels []Parcel, query string) []Parcel {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return parcels
+	}
+
+	var found []Parcel
+	for _, p := range parcels {
+		atak := strconv.FormatUint(uint64(p.ATAK), 10)
+		if strings.Contains(strings.ToLower(p.KAEK), query) || strings.Contains(atak, query) ||
+			strings.Contains(strings.ToLower(p.Municipality), query) {
+			found = append(found, p)
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParcelHistory_OldestFirst(t *testing.T) {
+	t.Parallel()
+
+	field := Parcel{ID: 7, KAEK: "050011234567"}
+	entries := []Entry{
+		{ID: 2, Start: "01-01-2025", End: "31-12-2029", Status: statusActive, Parcels: []Parcel{field}},
+		{ID: 1, Start: "01-01-2020", End: "31-12-2024", Status: statusRenewed, Parcels: []Parcel{{ID: 3}, field}},
+		{ID: 3, Start: "01-01-2023", End: "31-12-2026", Status: statusActive, Parcels: []Parcel{{ID: 3}}},
+		{ID: 4, Start: "01-01-2026", End: "31-12-2026", Status: statusDraft, Parcels: []Parcel{field}},
+	}
+
+	history := parcelHistory(field.ID, entries)
+	if len(history) != 3 || history[0].ID != 1 || history[1].ID != 2 || history[2].ID != 4 {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	current := parcelContractsOn(field.ID, entries, date("15-06-2026"))
+	if len(current) != 1 || current[0].ID != 2 {
+		t.Fatalf("unexpected contracts on 15-06-2026: %+v", current)
+	}
+	if got := parcelContractsOn(field.ID, entries, date("15-06-2031")); len(got) != 0 {
+		t.Fatalf("nothing leases it in 2031, got %+v", got)
+	}
+}
+
+func TestParcelFromEntry(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{ID: 1, KAEK: "k", ATAK: 12, Size: 4.5, Coords: []Coordinates{{ID: 9, EntryID: 1, Latitude: 38, Longitude: 23}}}
+	p := parcelFromEntry(e)
+	if p.ID != 0 || p.KAEK != "k" || p.ATAK != 12 || p.Area != 4.5 || len(p.Coords) != 1 {
+		t.Fatalf("unexpected parcel: %+v", p)
+	}
+	if p.Coords[0].ID != 0 || p.Coords[0].EntryID != 0 || p.Coords[0].Latitude != 38 {
+		t.Fatalf("the coordinates should be new rows: %+v", p.Coords[0])
+	}
+}
+
+func TestValidateParcel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		parcel  Parcel
+		wantErr bool
+	}{
+		{"kaek", Parcel{KAEK: "k"}, false},
+		{"atak", Parcel{ATAK: 1}, false},
+		{"neither", Parcel{Area: 3}, true},
+		{"negative area", Parcel{KAEK: "k", Area: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := validateParcel(tt.parcel); (err != nil) != tt.wantErr {
+				t.Fatalf("validateParcel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSearchParcels(t *testing.T) {
+	t.Parallel()
+
+	parcels := []Parcel{{ID: 1, KAEK: "0500", Municipality: "Λαρισαίων"}, {ID: 2, ATAK: 9876}}
+	if got := searchParcels(parcels, "λαρισ"); len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("by municipality: %+v", got)
+	}
+	if got := searchParcels(parcels, "987"); len(got) != 1 || got[0].ID != 2 {
+		t.Fatalf("by ATAK: %+v", got)
+	}
+	if got := searchParcels(parcels, " "); len(got) != 2 {
+		t.Fatalf("empty query: %+v", got)
+	}
+}
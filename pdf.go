@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"fyne.io/fyne/v2/theme"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// The core pdf fonts don't have greek glyphs so we embed the one fyne ships with
+const pdfFont = "noto"
+
+func newPDF(orientation string) *gofpdf.Fpdf {
+	pdf := gofpdf.New(orientation, "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(pdfFont, "", theme.DefaultTextFont().Content())
+	pdf.AddUTF8FontFromBytes(pdfFont, "B", theme.DefaultTextBoldFont().Content())
+	pdf.SetFont(pdfFont, "", 10)
+
+	return pdf
+}
+
+// New document with a first page that starts with a bold title and any
+// number of lines under it
+func newReportPDF(orientation, title string, lines ...string) *gofpdf.Fpdf {
+	pdf := newPDF(orientation)
+	pdf.AddPage()
+
+	pdf.SetFont(pdfFont, "B", 14)
+	pdf.CellFormat(0, 8, title, "", 1, "L", false, 0, "")
+	pdf.SetFont(pdfFont, "", 10)
+	for _, l := range lines {
+		pdf.CellFormat(0, 6, l, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	return pdf
+}
+
+// Draws a simple bordered table, when widths is nil every column gets the same width
+func pdfTable(pdf *gofpdf.Fpdf, header []string, widths []float64, rows [][]string) {
+	if widths == nil {
+		pageWidth, _ := pdf.GetPageSize()
+		left, _, right, _ := pdf.GetMargins()
+		w := (pageWidth - left - right) / float64(len(header))
+		for range header {
+			widths = append(widths, w)
+		}
+	}
+
+	pdf.SetFont(pdfFont, "B", 9)
+	for i, h := range header {
+		pdf.CellFormat(widths[i], 7, h, "1", 0, "C", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont(pdfFont, "", 9)
+	for _, row := range rows {
+		for i, c := range row {
+			pdf.CellFormat(widths[i], 6, c, "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func pdfBytes(pdf *gofpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("error creating pdf: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
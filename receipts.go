@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Α-12/2025
+func receiptName(r Receipt) string {
+	return fmt.Sprintf("%s-%d/%d", r.Series, r.Number, r.Year)
+}
+
+// The name it's attached to the contract with, no slashes so it works as a file name
+func receiptFileName(r Receipt) string {
+	return fmt.Sprintf("Απόδειξη %s-%d-%d", r.Series, r.Number, r.Year)
+}
+
+// Who received the money, everyone when it was paid to all the owners
+func receiptPayees(p Payment, e Entry) []OwnerDetails {
+	for _, o := range e.Owners {
+		if p.OwnerID != 0 && o.ID == p.OwnerID {
+			return []OwnerDetails{o}
+		}
+	}
+
+	return e.Owners
+}
+
+func receiptPayers(p Payment, e Entry) []RenterDetails {
+	for _, r := range e.Renters {
+		if p.RenterID != 0 && r.ID == p.RenterID {
+			return []RenterDetails{r}
+		}
+	}
+
+	return e.Renters
+}
+
+func receiptPDF(r Receipt, p Payment, e Entry) ([]byte, error) {
+	// the people of the contract when it was paid
+	if d, err := parseDate(p.Date); err == nil {
+		e = e.AsOf(d)
+	}
+
+	pdf := newReportPDF("P", "ΑΠΟΔΕΙΞΗ ΕΙΣΠΡΑΞΗΣ ΜΙΣΘΩΜΑΤΟΣ",
+		fmt.Sprintf("Σειρά: %s   Αριθμός: %d/%d", r.Series, r.Number, r.Year),
+		fmt.Sprintf("Ημερομηνία έκδοσης: %s", r.Issued),
+	)
+
+	section := func(title string, lines ...string) {
+		pdf.SetFont(pdfFont, "B", 11)
+		pdf.CellFormat(0, 7, title, "B", 1, "L", false, 0, "")
+		pdf.SetFont(pdfFont, "", 10)
+		for _, l := range lines {
+			pdf.MultiCell(0, 5, l, "", "L", false)
+		}
+		pdf.Ln(3)
+	}
+
+	var payees []string
+	for _, o := range receiptPayees(p, e) {
+		payees = append(payees, fmt.Sprintf("%s %s του %s, Α.Φ.Μ.: %d, Α.Δ.Τ.: %s, %s",
+			o.FirstName, o.LastName, o.FathersName, o.AFM, o.ADT, o.HomeAddress))
+	}
+	section("Εκμισθωτής / Εισπράκτορας", payees...)
+
+	var payers []string
+	for _, rd := range receiptPayers(p, e) {
+		payers = append(payers, fmt.Sprintf("%s %s του %s, Α.Φ.Μ.: %d, Α.Δ.Τ.: %s",
+			rd.FirstName, rd.LastName, rd.FathersName, rd.AFM, rd.ADT))
+	}
+	section("Μισθωτής / Καταβάλλων", payers...)
+
+	section("Μισθωτήριο",
+		fmt.Sprintf("Συμβόλαιο: %s (%s - %s)", contractLabel(e), e.Start, e.End),
+		fmt.Sprintf("ΑΤΑΚ: %d   ΚΑΕΚ: %s   Στρέμματα: %.3f", e.ATAK, e.KAEK, e.Size),
+	)
+
+	section("Ποσό",
+		fmt.Sprintf("%.2f€", p.Amount),
+		greekAmountWords(p.Amount),
+		fmt.Sprintf("Ημερομηνία πληρωμής: %s", p.Date),
+	)
+	if p.Notes != "" {
+		section("Σημειώσεις", p.Notes)
+	}
+
+	pdf.Ln(20)
+	pdf.CellFormat(90, 6, "Ο Εισπράξας", "T", 0, "C", false, 0, "")
+	pdf.CellFormat(10, 6, "", "", 0, "C", false, 0, "")
+	pdf.CellFormat(90, 6, "Ο Καταβαλών", "T", 1, "C", false, 0, "")
+
+	return pdfBytes(pdf)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+)
+
+// The renewal of a contract, same parties, parcel and coordinates as on its
+// last day after the amendments. It starts the day after the old one really
+// ends, extended or terminated early, and runs for as many months, the rent
+// goes up by escalation percent.
+func proposeRenewal(e Entry, escalation float64) (Entry, error) {
+	start, err := parseDate(e.Start)
+	if err != nil {
+		return Entry{}, err
+	}
+	end, err := parseDate(e.ActualEnd())
+	if err != nil {
+		return Entry{}, err
+	}
+	terms := e.AsOf(end)
+
+	newStart := end.AddDate(0, 0, 1)
+	months := (newStart.Year()-start.Year())*12 + int(newStart.Month()) - int(start.Month())
+
+	// whole months when it ran for whole months, the same days otherwise
+	newEnd := newStart.AddDate(0, months, -1)
+	if !start.AddDate(0, months, 0).Equal(newStart) {
+		newEnd = newStart.Add(end.Sub(start))
+	}
+
+	renewed := Entry{
+		Name:            e.Name,
+		ATAK:            terms.ATAK,
+		KAEK:            terms.KAEK,
+		Size:            terms.Size,
+		Type:            terms.Type,
+		ContractType:    e.ContractType,
+		Share:           e.Share,
+		NoticeMonths:    e.NoticeMonths,
+		AutoRenewMonths: e.AutoRenewMonths,
+		NoticeParty:     e.NoticeParty,
+		Start:           newStart.Format(dateLayout),
+		End:             newEnd.Format(dateLayout),
+		Rent:            math.Round(terms.Rent*(100+escalation)) / 100,
+		PredecessorID:   e.ID,
+		ParentID:        e.ParentID,
+	}
+
+	// the same fields, so their history goes on
+	renewed.Parcels = append(renewed.Parcels, e.Parcels...)
+
+	// new rows for the coordinates, the people are matched by name anyway
+	renewed.Owners = append(renewed.Owners, terms.Owners...)
+	renewed.Renters = append(renewed.Renters, terms.Renters...)
+	for _, c := range e.Coords {
+		renewed.Coords = append(renewed.Coords, Coordinates{Latitude: c.Latitude, Longitude: c.Longitude, Part: c.Part, Ring: c.Ring, Seq: c.Seq})
+	}
+
+	return renewed, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestProposeRenewal_WholeYears(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{
+		ID:       7,
+		ParentID: 3,
+		Name:     "Κάμπος",
+		KAEK:     "123",
+		Size:     12.5,
+		Rent:     1000,
+		Start:    "01-01-2024",
+		End:      "31-12-2026",
+		Status:   statusExpiring,
+		Owners:   []OwnerDetails{{ID: 1, FirstName: "A"}},
+		Renters:  []RenterDetails{{ID: 2, FirstName: "B"}},
+		Coords:   []Coordinates{{ID: 9, EntryID: 7, Latitude: 38.1, Longitude: 23.2}},
+		emisth:   []byte("old"),
+	}
+
+	got, err := proposeRenewal(e, 3)
+	if err != nil {
+		t.Fatalf("proposeRenewal returned error: %v", err)
+	}
+	if got.Start != "01-01-2027" || got.End != "31-12-2029" {
+		t.Fatalf("unexpected dates: %s - %s", got.Start, got.End)
+	}
+	if got.Rent != 1030 || got.PredecessorID != 7 || got.ParentID != 3 || got.KAEK != "123" || got.Size != 12.5 {
+		t.Fatalf("unexpected renewal: %+v", got)
+	}
+	if got.ID != 0 || got.Status != "" || got.emisth != nil {
+		t.Fatalf("the renewal should be a new contract: %+v", got)
+	}
+	if len(got.Coords) != 1 || got.Coords[0].ID != 0 || got.Coords[0].Latitude != 38.1 {
+		t.Fatalf("unexpected coordinates: %+v", got.Coords)
+	}
+	if len(got.Owners) != 1 || len(got.Renters) != 1 {
+		t.Fatalf("expected the same parties, got %+v %+v", got.Owners, got.Renters)
+	}
+}
+
+func TestProposeRenewal_MidMonthAndOddLength(t *testing.T) {
+	t.Parallel()
+
+	got, err := proposeRenewal(Entry{Start: "15-10-2024", End: "14-10-2025", Rent: 333.33}, 0)
+	if err != nil {
+		t.Fatalf("proposeRenewal returned error: %v", err)
+	}
+	if got.Start != "15-10-2025" || got.End != "14-10-2026" || got.Rent != 333.33 {
+		t.Fatalf("unexpected renewal: %s - %s %v", got.Start, got.End, got.Rent)
+	}
+
+	// 100 days
+	got, err = proposeRenewal(Entry{Start: "01-03-2025", End: "08-06-2025"}, 0)
+	if err != nil {
+		t.Fatalf("proposeRenewal returned error: %v", err)
+	}
+	if got.Start != "09-06-2025" || got.End != "16-09-2025" {
+		t.Fatalf("unexpected dates: %s - %s", got.Start, got.End)
+	}
+}
+
+func TestProposeRenewal_AfterTheAmendments(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{
+		ID:      7,
+		Rent:    1000,
+		Start:   "01-01-2024",
+		End:     "31-12-2025",
+		Owners:  []OwnerDetails{{ID: 1, FirstName: "A"}, {ID: 2, FirstName: "Γ"}},
+		Renters: []RenterDetails{{ID: 3, FirstName: "B"}},
+		Amendments: []Amendment{
+			{Kind: amendRent, Effective: "01-01-2025", Rent: 1200},
+			{Kind: amendParties, Effective: "01-06-2025", Owners: []OwnerDetails{{ID: 1, FirstName: "A"}}},
+			{Kind: amendExtension, Effective: "01-10-2025", End: "31-12-2026"},
+		},
+	}
+
+	got, err := proposeRenewal(e, 5)
+	if err != nil {
+		t.Fatalf("proposeRenewal returned error: %v", err)
+	}
+	if got.Start != "01-01-2027" || got.End != "31-12-2029" {
+		t.Errorf("unexpected dates: %s - %s, want after the extension", got.Start, got.End)
+	}
+	if got.Rent != 1260 {
+		t.Errorf("rent = %v, want the amended rent plus 5%%", got.Rent)
+	}
+	if len(got.Owners) != 1 || got.Owners[0].ID != 1 || len(got.Renters) != 1 {
+		t.Errorf("expected the parties after the amendment, got %+v %+v", got.Owners, got.Renters)
+	}
+	if len(got.Amendments) != 0 {
+		t.Errorf("the amendments stay with the old contract: %+v", got.Amendments)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// One line of the Ε2 form, a leased property of the owner for the year
+type E2Row struct {
+	EntryID    uint
+	Name       string
+	Type       string // lease, free use etc.
+	ATAK       uint
+	KAEK       string
+	RenterAFMs string
+	From       string
+	To         string
+	Months     int
+	Rent       float64
+}
+
+var e2Header = []string{"Συμβόλαιο", "Είδος", "ΑΤΑΚ", "ΚΑΕΚ", "ΑΦΜ Μισθωτή", "Από", "Έως", "Μήνες", "Μίσθωμα (€)"}
+
+// The part of a lease that falls inside the year, ok is false if there is none
+func leasePeriodInYear(start, end string, year int) (from, to time.Time, ok bool, err error) {
+	from, err = parseDate(start)
+	if err != nil {
+		return from, to, false, err
+	}
+	to, err = parseDate(end)
+	if err != nil {
+		return from, to, false, err
+	}
+
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	if from.Before(yearStart) {
+		from = yearStart
+	}
+	if to.After(yearEnd) {
+		to = yearEnd
+	}
+
+	return from, to, !from.After(to), nil
+}
+
+// Calendar months touched by the period, both ends included
+func monthsInPeriod(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month()) + 1
+}
+
+// The rent an owner received for a contract, payments made to all the
+// owners together are split equally between the owners on the payment date
+func ownerShareOfPayments(owner OwnerDetails, e Entry, payments []Payment, year int) float64 {
+	var total float64
+
+	for _, p := range payments {
+		if p.EntryID != e.ID {
+			continue
+		}
+		d, err := parseDate(p.Date)
+		if err != nil || d.Year() != year {
+			continue
+		}
+
+		switch {
+		case p.OwnerID == owner.ID:
+			total += p.Amount
+		case p.OwnerID == 0 && len(e.AsOf(d).Owners) > 0:
+			total += p.Amount / float64(len(e.AsOf(d).Owners))
+		}
+	}
+
+	return TruncateFloatTo2Decimals(total)
+}
+
+func buildE2Rows(owner OwnerDetails, entries []Entry, payments []Payment, year int) ([]E2Row, error) {
+	var rows []E2Row
+
+	for _, e := range entries {
+		// the land the owners farm themselves isn't declared
+		if !e.Rules().E2 {
+			continue
+		}
+		from, to, ok, err := leasePeriodInYear(e.Start, e.ActualEnd(), year)
+		if err != nil {
+			return nil, fmt.Errorf("contract %s: %v", e.Name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		owners, renters := e.PartiesDuring(from, to)
+		owned := false
+		for _, o := range owners {
+			if o.ID == owner.ID {
+				owned = true
+				break
+			}
+		}
+		if !owned {
+			continue
+		}
+
+		var afms []string
+		for _, r := range renters {
+			afms = append(afms, strconv.FormatUint(uint64(r.AFM), 10))
+		}
+
+		// a line for every parcel, the rent split by their area
+		parcels := e.Parcels
+		if len(parcels) == 0 {
+			parcels = []Parcel{{ATAK: e.ATAK, KAEK: e.KAEK}}
+		}
+		rents := splitByArea(ownerShareOfPayments(owner, e, payments, year), parcels)
+		for i, p := range parcels {
+			rows = append(rows, E2Row{
+				EntryID:    e.ID,
+				Name:       contractLabel(e),
+				Type:       contractTypeLabel(e.ContractType),
+				ATAK:       p.ATAK,
+				KAEK:       p.KAEK,
+				RenterAFMs: strings.Join(afms, ", "),
+				From:       from.Format(dateLayout),
+				To:         to.Format(dateLayout),
+				Months:     monthsInPeriod(from, to),
+				Rent:       rents[i],
+			})
+		}
+	}
+
+	return rows, nil
+}
+
+// The amount shared by the parcels in proportion to their area, equally when
+// the areas aren't known. The last one gets what the rounding left.
+func splitByArea(amount float64, parcels []Parcel) []float64 {
+	var total float64
+	for _, p := range parcels {
+		total += max(p.Area, 0)
+	}
+
+	shares := make([]float64, len(parcels))
+	left := amount
+	for i, p := range parcels {
+		if i == len(parcels)-1 {
+			shares[i] = math.Round(left*100) / 100
+			break
+		}
+		share := amount / float64(len(parcels))
+		if total > 0 {
+			share = amount * max(p.Area, 0) / total
+		}
+		shares[i] = TruncateFloatTo2Decimals(share)
+		left -= shares[i]
+	}
+
+	return shares
+}
+
+func e2Report(db *sql.DB, owner OwnerDetails, year int, statuses []string) ([]E2Row, error) {
+	y := strconv.Itoa(year)
+
+	entries, err := getAllEntriesByYear(db, y)
+	if err != nil {
+		return nil, err
+	}
+	entries = filterByStatus(entries, statuses)
+	payments, err := getPaymentsByYear(db, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildE2Rows(owner, entries, payments, year)
+}
+
+func e2Records(rows []E2Row) [][]string {
+	records := make([][]string, 0, len(rows)+1)
+
+	var total float64
+	for _, r := range rows {
+		records = append(records, []string{
+			r.Name,
+			r.Type,
+			strconv.FormatUint(uint64(r.ATAK), 10),
+			r.KAEK,
+			r.RenterAFMs,
+			r.From,
+			r.To,
+			strconv.Itoa(r.Months),
+			fmt.Sprintf("%.2f", r.Rent),
+		})
+		total += r.Rent
+	}
+	records = append(records, []string{"Σύνολο", "", "", "", "", "", "", "", fmt.Sprintf("%.2f", total)})
+
+	return records
+}
+
+func e2CSV(rows []E2Row) ([]byte, error) {
+	return csvBytes(e2Header, e2Records(rows))
+}
+
+func e2PDF(owner OwnerDetails, year int, rows []E2Row) ([]byte, error) {
+	pdf := newReportPDF("L", fmt.Sprintf("Στοιχεία μισθωμάτων για το έντυπο Ε2 - %d", year),
+		fmt.Sprintf("Εκμισθωτής: %s %s", owner.FirstName, owner.LastName),
+		fmt.Sprintf("Α.Φ.Μ.: %d", owner.AFM),
+	)
+	pdfTable(pdf, e2Header, []float64{45, 32, 20, 35, 43, 24, 24, 16, 28}, e2Records(rows))
+
+	return pdfBytes(pdf)
+}
+
+// Excel needs the BOM to understand that the file is utf-8
+func csvBytes(header []string, records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
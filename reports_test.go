@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestLeasePeriodInYear_ClampsToYear(t *testing.T) {
+	t.Parallel()
+
+	from, to, ok, err := leasePeriodInYear("01-10-2023", "30-09-2026", 2025)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("expected the lease to overlap 2025")
+	}
+	if from.Format(dateLayout) != "01-01-2025" || to.Format(dateLayout) != "31-12-2025" {
+		t.Fatalf("unexpected period: %s - %s", from.Format(dateLayout), to.Format(dateLayout))
+	}
+	if got := monthsInPeriod(from, to); got != 12 {
+		t.Fatalf("monthsInPeriod() = %d, want 12", got)
+	}
+
+	from, to, ok, err = leasePeriodInYear("01-10-2023", "30-09-2026", 2023)
+	if err != nil || !ok {
+		t.Fatalf("expected the lease to overlap 2023, err: %v", err)
+	}
+	if got := monthsInPeriod(from, to); got != 3 {
+		t.Fatalf("monthsInPeriod() = %d, want 3", got)
+	}
+
+	if _, _, ok, _ := leasePeriodInYear("01-10-2023", "30-09-2026", 2027); ok {
+		t.Fatalf("expected no overlap with 2027")
+	}
+
+	if _, _, _, err := leasePeriodInYear("2023-10-01", "30-09-2026", 2025); err == nil {
+		t.Fatalf("expected error for a badly formatted date")
+	}
+}
+
+func TestBuildE2Rows_SplitsSharedPayments(t *testing.T) {
+	t.Parallel()
+
+	alice := OwnerDetails{ID: 1, FirstName: "Alice"}
+	bob := OwnerDetails{ID: 2, FirstName: "Bob"}
+
+	entries := []Entry{
+		{
+			ID:      10,
+			Name:    "Κάμπος",
+			ATAK:    123,
+			KAEK:    "050123",
+			Owners:  []OwnerDetails{alice, bob},
+			Renters: []RenterDetails{{ID: 5, AFM: 111222333}},
+			Start:   "01-03-2024",
+			End:     "28-02-2027",
+		},
+		{
+			ID:     11,
+			Name:   "Όχι της Alice",
+			Owners: []OwnerDetails{bob},
+			Start:  "01-01-2025",
+			End:    "31-12-2025",
+		},
+	}
+
+	payments := []Payment{
+		{EntryID: 10, Date: "15-03-2025", Amount: 1000},               // shared
+		{EntryID: 10, OwnerID: 1, Date: "15-09-2025", Amount: 300},    // alice only
+		{EntryID: 10, OwnerID: 2, Date: "15-09-2025", Amount: 300},    // bob only
+		{EntryID: 10, OwnerID: 1, Date: "15-09-2024", Amount: 999},    // another year
+		{EntryID: 11, OwnerID: 0, Date: "15-09-2025", Amount: 5000.5}, // not her contract
+	}
+
+	rows, err := buildE2Rows(alice, entries, payments, 2025)
+	if err != nil {
+		t.Fatalf("buildE2Rows returned error: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d: %+v", len(rows), rows)
+	}
+
+	r := rows[0]
+	if r.Rent != 800 {
+		t.Fatalf("Rent = %v, want 800", r.Rent)
+	}
+	if r.RenterAFMs != "111222333" || r.Months != 12 || r.From != "01-01-2025" {
+		t.Fatalf("unexpected row: %+v", r)
+	}
+}
+
+func TestBuildE2Rows_OneRowPerParcel(t *testing.T) {
+	t.Parallel()
+
+	alice := OwnerDetails{ID: 1, FirstName: "Alice"}
+	entries := []Entry{{
+		ID:     10,
+		ATAK:   123,
+		KAEK:   "050123",
+		Owners: []OwnerDetails{alice},
+		Start:  "01-01-2025",
+		End:    "31-12-2025",
+		Parcels: []Parcel{
+			{ID: 1, ATAK: 123, KAEK: "050123", Area: 10},
+			{ID: 2, ATAK: 456, KAEK: "050456", Area: 20},
+		},
+	}}
+	payments := []Payment{{EntryID: 10, Date: "15-03-2025", Amount: 1000}}
+
+	rows, err := buildE2Rows(alice, entries, payments, 2025)
+	if err != nil {
+		t.Fatalf("buildE2Rows returned error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].ATAK != 123 || rows[1].ATAK != 456 || rows[1].KAEK != "050456" {
+		t.Fatalf("expected a row for every parcel, got %+v", rows)
+	}
+	if rows[0].Rent != 333.33 || rows[1].Rent != 666.67 {
+		t.Errorf("rents = %v, %v, want the 1000 split by area", rows[0].Rent, rows[1].Rent)
+	}
+}
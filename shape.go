@@ -0,0 +1,237 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A ring of corners, the first one isn't repeated at the end
+type ring []Coordinates
+
+// The coordinates grouped into parts and the parts into rings, the outer ring
+// first and then the holes, everything in the order of Part, Ring and Seq
+func shapeRings(coords []Coordinates) [][]ring {
+	sorted := append([]Coordinates(nil), coords...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Part != b.Part {
+			return a.Part < b.Part
+		}
+		if a.Ring != b.Ring {
+			return a.Ring < b.Ring
+		}
+		return a.Seq < b.Seq
+	})
+
+	var parts [][]ring
+	for i, c := range sorted {
+		switch {
+		case i == 0 || c.Part != sorted[i-1].Part:
+			parts = append(parts, []ring{{c}})
+		case c.Ring != sorted[i-1].Ring:
+			parts[len(parts)-1] = append(parts[len(parts)-1], ring{c})
+		default:
+			rings := parts[len(parts)-1]
+			rings[len(rings)-1] = append(rings[len(rings)-1], c)
+		}
+	}
+
+	return parts
+}
+
+// Flattens the parts back to coordinates numbered by their position, the
+// empty rings and parts are dropped
+func numberShape(parts [][]ring) []Coordinates {
+	var coords []Coordinates
+	part := 0
+	for _, rings := range parts {
+		if len(rings) == 0 || len(rings[0]) == 0 {
+			continue
+		}
+		r := 0
+		for _, corners := range rings {
+			if len(corners) == 0 {
+				continue
+			}
+			for seq, c := range corners {
+				c.Part, c.Ring, c.Seq = part, r, seq
+				coords = append(coords, c)
+			}
+			r++
+		}
+		part++
+	}
+
+	return coords
+}
+
+// The ring without the first corner repeated at the end, GPS and GIS tools
+// close their rings that way
+func openRing(r ring) ring {
+	if len(r) > 1 && samePlace(r[0], r[len(r)-1]) {
+		return r[:len(r)-1]
+	}
+	return r
+}
+
+func samePlace(a, b Coordinates) bool {
+	return a.Latitude == b.Latitude && a.Longitude == b.Longitude
+}
+
+// How a ring is named in the messages, part and hole counted from 1
+func ringName(part, r int) string {
+	if r == 0 {
+		return fmt.Sprintf("part %d", part+1)
+	}
+	return fmt.Sprintf("part %d hole %d", part+1, r)
+}
+
+// Every ring has to close into a simple polygon and the holes have to be in
+// their part. No coordinates at all is fine, they are optional.
+func validateShape(coords []Coordinates) error {
+	for _, c := range coords {
+		if c.Latitude == 0 && c.Longitude == 0 {
+			return errors.New("a corner is at 0,0, fill it in or remove it")
+		}
+		if c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+			return fmt.Errorf("%f, %f is not a valid latitude, longitude", c.Latitude, c.Longitude)
+		}
+	}
+
+	var outers [][]point
+	for p, rings := range shapeRings(coords) {
+		var polys [][]point
+		for r, corners := range rings {
+			name := ringName(p, r)
+			corners = openRing(corners)
+			if len(corners) < 3 {
+				return fmt.Errorf("%s does not close, it needs at least 3 corners", name)
+			}
+			for i := range corners {
+				if samePlace(corners[i], corners[(i+1)%len(corners)]) {
+					return fmt.Errorf("%s has corner %d twice in a row", name, i+1)
+				}
+			}
+
+			poly := polygonPoints(corners)
+			if i, j, ok := selfIntersection(poly); ok {
+				return fmt.Errorf("%s crosses itself, edge %d meets edge %d", name, i+1, j+1)
+			}
+			polys = append(polys, poly)
+		}
+
+		outer := polys[0]
+		for h, hole := range polys[1:] {
+			if !ringInside(hole, outer) {
+				return fmt.Errorf("%s is not inside its part", ringName(p, h+1))
+			}
+			for k, other := range polys[1 : h+1] {
+				if polygonsIntersect(hole, other) {
+					return fmt.Errorf("%s meets %s", ringName(p, h+1), ringName(p, k+1))
+				}
+			}
+		}
+		for k, other := range outers {
+			if polygonsOverlap(outer, other) {
+				return fmt.Errorf("%s overlaps %s", ringName(p, 0), ringName(k, 0))
+			}
+		}
+		outers = append(outers, outer)
+	}
+
+	return nil
+}
+
+// The first two edges of the polygon that meet without being neighbours, or
+// neighbours that fold back along each other
+func selfIntersection(poly []point) (int, int, bool) {
+	n := len(poly)
+	for i := range n {
+		a, b := poly[i], poly[(i+1)%n]
+		for j := i + 1; j < n; j++ {
+			c, d := poly[j], poly[(j+1)%n]
+			switch {
+			case j == i+1:
+				if foldsBack(b, a, d) {
+					return i, j, true
+				}
+			case i == 0 && j == n-1:
+				if foldsBack(a, b, c) {
+					return i, j, true
+				}
+			case segmentsIntersect(a, b, c, d):
+				return i, j, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// The edges from the shared corner to p and to q run along the same line in
+// the same direction
+func foldsBack(shared, p, q point) bool {
+	dot := (p.x-shared.x)*(q.x-shared.x) + (p.y-shared.y)*(q.y-shared.y)
+	return orientation(shared, p, q) == 0 && dot > 0
+}
+
+// The ring is inside the polygon without crossing its edges
+func ringInside(inner, outer []point) bool {
+	for i := range inner {
+		for j := range outer {
+			if segmentsIntersect(inner[i], inner[(i+1)%len(inner)], outer[j], outer[(j+1)%len(outer)]) {
+				return false
+			}
+		}
+	}
+
+	return pointInPolygon(inner[0], outer)
+}
+
+// The coordinates as lines for the documents and the details, one corner per
+// line with a heading for every part and hole when there is more than one ring
+func shapeLines(coords []Coordinates) []string {
+	parts := shapeRings(coords)
+	single := len(parts) == 1 && len(parts[0]) == 1
+
+	var lines []string
+	for p, rings := range parts {
+		for r, corners := range rings {
+			if !single {
+				if r == 0 {
+					lines = append(lines, fmt.Sprintf("Τμήμα %d:", p+1))
+				} else {
+					lines = append(lines, fmt.Sprintf("Οπή %d:", r))
+				}
+			}
+			for i, c := range corners {
+				lines = append(lines, fmt.Sprintf("%d. %f, %f", i+1, c.Latitude, c.Longitude))
+			}
+		}
+	}
+
+	return lines
+}
+
+// A corner from the text of its latitude and longitude in any of the forms
+// of parseAngle, or from both pasted together in the first one
+func parseCorner(lat, lon string) (Coordinates, error) {
+	if strings.TrimSpace(lon) == "" && strings.TrimSpace(lat) != "" {
+		if c, err := parseLatLon(lat); err == nil {
+			return c, nil
+		}
+	}
+
+	latitude, err := parseLatitude(lat)
+	if err != nil {
+		return Coordinates{}, err
+	}
+	longitude, err := parseLongitude(lon)
+	if err != nil {
+		return Coordinates{}, err
+	}
+
+	return Coordinates{Latitude: latitude, Longitude: longitude}, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// the corners of a ring, latitude and longitude pairs
+func corners(part, r int, latLon ...float64) []Coordinates {
+	var coords []Coordinates
+	for i := 0; i+1 < len(latLon); i += 2 {
+		coords = append(coords, Coordinates{Latitude: latLon[i], Longitude: latLon[i+1], Part: part, Ring: r, Seq: i / 2})
+	}
+	return coords
+}
+
+func TestShapeRings_GroupsAndNumbers(t *testing.T) {
+	t.Parallel()
+
+	coords := []Coordinates{
+		{Latitude: 5, Part: 1, Ring: 0, Seq: 1},
+		{Latitude: 1, Part: 0, Ring: 0, Seq: 0},
+		{Latitude: 3, Part: 0, Ring: 2, Seq: 0},
+		{Latitude: 4, Part: 1, Ring: 0, Seq: 0},
+		{Latitude: 2, Part: 0, Ring: 0, Seq: 1},
+	}
+
+	parts := shapeRings(coords)
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 1 {
+		t.Fatalf("unexpected grouping: %+v", parts)
+	}
+	if parts[0][0][1].Latitude != 2 || parts[1][0][0].Latitude != 4 {
+		t.Fatalf("corners out of order: %+v", parts)
+	}
+
+	// the gap in the rings of the first part is closed
+	numbered := numberShape(parts)
+	if h := numbered[2]; h.Latitude != 3 || h.Part != 0 || h.Ring != 1 || h.Seq != 0 {
+		t.Fatalf("unexpected hole corner: %+v", h)
+	}
+	if last := numbered[4]; last.Latitude != 5 || last.Part != 1 || last.Seq != 1 {
+		t.Fatalf("unexpected last corner: %+v", last)
+	}
+
+	// the corners from before the parts all have zeros and keep their order
+	old := []Coordinates{{Latitude: 3}, {Latitude: 1}, {Latitude: 2}}
+	if got := numberShape(shapeRings(old)); got[0].Latitude != 3 || got[2].Seq != 2 {
+		t.Fatalf("legacy corners reordered: %+v", got)
+	}
+}
+
+func TestValidateShape(t *testing.T) {
+	t.Parallel()
+
+	square := corners(0, 0, 38, 23, 38, 23.01, 38.01, 23.01, 38.01, 23)
+	hole := corners(0, 1, 38.002, 23.002, 38.002, 23.004, 38.004, 23.004, 38.004, 23.002)
+	other := corners(1, 0, 38.1, 23.1, 38.1, 23.11, 38.11, 23.11)
+
+	join := func(rings ...[]Coordinates) []Coordinates {
+		var all []Coordinates
+		for _, r := range rings {
+			all = append(all, r...)
+		}
+		return all
+	}
+
+	tests := []struct {
+		name    string
+		coords  []Coordinates
+		wantErr string
+	}{
+		{"no coordinates", nil, ""},
+		{"square", square, ""},
+		{"closed with the first corner", append(corners(0, 0, 38, 23, 38, 23.01, 38.01, 23.01), Coordinates{Latitude: 38, Longitude: 23, Seq: 3}), ""},
+		{"with a hole and a second part", join(square, hole, other), ""},
+		{"missing corner", append(corners(0, 0, 38, 23, 38, 23.01, 38.01, 23.01), Coordinates{Seq: 3}), "0,0"},
+		{"two corners", corners(0, 0, 38, 23, 38, 23.01), "does not close"},
+		{"closed triangle of two", corners(0, 0, 38, 23, 38, 23.01, 38, 23), "does not close"},
+		{"bow tie", corners(0, 0, 38, 23, 38.01, 23.01, 38, 23.01, 38.01, 23), "crosses itself"},
+		{"folds back", corners(0, 0, 38, 23, 38, 23.02, 38, 23.01, 38.01, 23.01), "crosses itself"},
+		{"on a line", corners(0, 0, 38, 23, 38, 23.01, 38, 23.02), "crosses itself"},
+		{"repeated corner", corners(0, 0, 38, 23, 38, 23.01, 38, 23.01, 38.01, 23.01), "twice"},
+		{"hole outside", join(square, corners(0, 1, 38.1, 23.1, 38.1, 23.11, 38.11, 23.11)), "not inside"},
+		{"overlapping parts", join(square, corners(1, 0, 38.005, 23.005, 38.005, 23.02, 38.02, 23.02)), "overlaps"},
+		{"out of range", corners(0, 0, 95, 23, 38, 23.01, 38.01, 23.01), "not a valid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateShape(tt.coords)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("validateShape() = %v, want an error with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShapeLines(t *testing.T) {
+	t.Parallel()
+
+	single := shapeLines(corners(0, 0, 38, 23, 38, 23.01, 38.01, 23.01))
+	if len(single) != 3 || single[0] != "1. 38.000000, 23.000000" {
+		t.Fatalf("unexpected lines: %v", single)
+	}
+
+	withHole := shapeLines(append(corners(0, 0, 38, 23, 38, 23.01, 38.01, 23.01), corners(0, 1, 38.001, 23.002, 38.001, 23.003, 38.002, 23.003)...))
+	if len(withHole) != 8 || withHole[0] != "Τμήμα 1:" || withHole[4] != "Οπή 1:" {
+		t.Fatalf("unexpected lines: %v", withHole)
+	}
+}
+
+func TestParseCorner(t *testing.T) {
+	t.Parallel()
+
+	c, err := parseCorner(" 38.5 ", "-1.25")
+	if err != nil || c.Latitude != 38.5 || c.Longitude != -1.25 {
+		t.Fatalf("parseCorner() = %+v, %v", c, err)
+	}
+	if _, err := parseCorner("x", "23"); err == nil || !strings.Contains(err.Error(), "latitude") {
+		t.Fatalf("expected a latitude error, got %v", err)
+	}
+}
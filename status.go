@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+)
+
+// Lifecycle of a contract
+const (
+	statusDraft      = "draft"
+	statusActive     = "active"
+	statusExpiring   = "expiring"
+	statusExpired    = "expired"
+	statusTerminated = "terminated"
+	statusRenewed    = "renewed"
+)
+
+// In the order they are shown in the filters
+var contractStatuses = []string{statusDraft, statusActive, statusExpiring, statusExpired, statusTerminated, statusRenewed}
+
+var statusLabels = map[string]string{
+	statusDraft:      "Πρόχειρο",
+	statusActive:     "Ενεργό",
+	statusExpiring:   "Λήγει",
+	statusExpired:    "Έληξε",
+	statusTerminated: "Λύθηκε",
+	statusRenewed:    "Ανανεώθηκε",
+}
+
+// The colour of the status in the list, the badges and the maps
+var statusColors = map[string]color.NRGBA{
+	statusDraft:      {R: 0x75, G: 0x75, B: 0x75, A: 0xff},
+	statusActive:     {R: 0x2e, G: 0x7d, B: 0x32, A: 0xff},
+	statusExpiring:   {R: 0xef, G: 0x6c, B: 0x00, A: 0xff},
+	statusExpired:    {R: 0xc6, G: 0x28, B: 0x28, A: 0xff},
+	statusTerminated: {R: 0x42, G: 0x42, B: 0x42, A: 0xff},
+	statusRenewed:    {R: 0x15, G: 0x65, B: 0xc0, A: 0xff},
+}
+
+// A contract is expiring when it ends in that many days or less, same as the
+// end date notifications
+const expiringDays = 30
+
+// Where a contract can go from every status. Active, expiring and expired
+// follow the dates so they can move between them when the dates are edited,
+// terminated and renewed are final.
+var statusTransitions = map[string][]string{
+	statusDraft:      {statusActive},
+	statusActive:     {statusExpiring, statusExpired, statusTerminated, statusRenewed},
+	statusExpiring:   {statusActive, statusExpired, statusTerminated, statusRenewed},
+	statusExpired:    {statusActive, statusExpiring, statusTerminated, statusRenewed},
+	statusTerminated: {},
+	statusRenewed:    {},
+}
+
+func canTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range statusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Statuses a user can pick by hand, the dated ones are derived
+func manualTransitions(from string) []string {
+	var opts []string
+	for _, s := range statusTransitions[from] {
+		if s == statusExpiring || s == statusExpired {
+			continue
+		}
+		if s == statusActive && from != statusDraft {
+			continue
+		}
+		opts = append(opts, s)
+	}
+
+	return opts
+}
+
+// Status of the contract on the day. Drafts, terminated and renewed contracts
+// keep the status they have, the rest follow the end date.
+func deriveStatus(current, end string, today time.Time) (string, error) {
+	switch current {
+	case statusDraft, statusTerminated, statusRenewed:
+		return current, nil
+	}
+
+	endDate, err := parseDate(end)
+	if err != nil {
+		return current, err
+	}
+
+	// dates are parsed as UTC midnights, the contract runs through its last day
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	daysLeft := daysBetween(today, endDate)
+	switch {
+	case daysLeft < 0:
+		return statusExpired, nil
+	case daysLeft <= expiringDays:
+		return statusExpiring, nil
+	default:
+		return statusActive, nil
+	}
+}
+
+// Status of the contract with its amendments, a contract that ended early
+// because of a termination amendment is terminated instead of expired
+func entryStatus(e Entry, today time.Time) (string, error) {
+	if dateBefore(e.ActualEnd(), e.End) && e.Status != statusDraft && canTransition(e.Status, statusTerminated) {
+		end, err := parseDate(e.ActualEnd())
+		if err != nil {
+			return e.Status, err
+		}
+		if end.Before(today) {
+			return statusTerminated, nil
+		}
+	}
+
+	return deriveStatus(e.Status, e.ActualEnd(), today)
+}
+
+// Moves the contract to a new status if it's allowed, the dated statuses are
+// derived again so a draft that is signed after its end lands on expired
+func nextStatus(e Entry, to string, today time.Time) (string, error) {
+	if !canTransition(e.Status, to) {
+		return "", fmt.Errorf("cannot change the status from %s to %s", statusLabels[e.Status], statusLabels[to])
+	}
+
+	e.Status = to
+	return entryStatus(e, today)
+}
+
+// nil statuses means everything, an empty slice means nothing
+func filterByStatus(entries []Entry, statuses []string) []Entry {
+	if statuses == nil {
+		return entries
+	}
+
+	allowed := make(map[string]bool, len(statuses))
+	for _, s := range statuses {
+		allowed[s] = true
+	}
+
+	var filtered []Entry
+	for _, e := range entries {
+		if allowed[e.Status] {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
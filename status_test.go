@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeriveStatus_FollowsTheEndDate(t *testing.T) {
+	t.Parallel()
+
+	// late in the day on purpose, the last day still counts
+	today := time.Date(2025, 6, 1, 18, 30, 0, 0, time.Local)
+	cases := []struct {
+		current, end, want string
+	}{
+		{statusActive, "31-12-2025", statusActive},
+		{statusActive, "01-07-2025", statusExpiring},
+		{statusActive, "01-06-2025", statusExpiring},
+		{statusExpiring, "31-05-2025", statusExpired},
+		{statusExpired, "31-12-2026", statusActive},
+		{statusDraft, "31-05-2025", statusDraft},
+		{statusTerminated, "31-12-2026", statusTerminated},
+		{statusRenewed, "31-05-2025", statusRenewed},
+	}
+
+	for _, c := range cases {
+		got, err := deriveStatus(c.current, c.end, today)
+		if err != nil {
+			t.Fatalf("deriveStatus(%s, %s) returned error: %v", c.current, c.end, err)
+		}
+		if got != c.want {
+			t.Errorf("deriveStatus(%s, %s) = %s, want %s", c.current, c.end, got, c.want)
+		}
+	}
+
+	if _, err := deriveStatus(statusActive, "2025-12-31", today); err == nil {
+		t.Fatalf("expected an error for a bad date")
+	}
+}
+
+func TestNextStatus_RespectsTheTransitions(t *testing.T) {
+	t.Parallel()
+
+	today := date("01-06-2025")
+
+	got, err := nextStatus(Entry{Status: statusDraft, End: "31-12-2024"}, statusActive, today)
+	if err != nil || got != statusExpired {
+		t.Fatalf("signing an old draft should land on expired, got %s (err: %v)", got, err)
+	}
+
+	if _, err := nextStatus(Entry{Status: statusTerminated, End: "31-12-2026"}, statusActive, today); err == nil {
+		t.Fatalf("expected terminated to be final")
+	}
+	if _, err := nextStatus(Entry{Status: statusDraft, End: "31-12-2026"}, statusRenewed, today); err == nil {
+		t.Fatalf("expected a draft not to be renewed")
+	}
+}
+
+func TestManualTransitions(t *testing.T) {
+	t.Parallel()
+
+	if got := manualTransitions(statusDraft); len(got) != 1 || got[0] != statusActive {
+		t.Fatalf("unexpected draft transitions: %v", got)
+	}
+	if got := manualTransitions(statusExpiring); len(got) != 2 || got[0] != statusTerminated || got[1] != statusRenewed {
+		t.Fatalf("unexpected expiring transitions: %v", got)
+	}
+	if got := manualTransitions(statusRenewed); len(got) != 0 {
+		t.Fatalf("unexpected renewed transitions: %v", got)
+	}
+}
+
+func TestFilterByStatus(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{{ID: 1, Status: statusActive}, {ID: 2, Status: statusDraft}, {ID: 3, Status: statusExpired}}
+
+	if got := filterByStatus(entries, nil); len(got) != 3 {
+		t.Fatalf("nil should keep everything, got %d", len(got))
+	}
+	if got := filterByStatus(entries, []string{}); len(got) != 0 {
+		t.Fatalf("empty should keep nothing, got %d", len(got))
+	}
+	got := filterByStatus(entries, []string{statusActive, statusExpired})
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 3 {
+		t.Fatalf("unexpected filtered entries: %+v", got)
+	}
+}
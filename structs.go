@@ -19,11 +19,13 @@ type AppState struct {
 	year      string
 	user      string
 	userLabel *widget.Label
+	statuses  []string // status filter of the contract list and the reports, nil for all
 }
 
 // Main struct/table
 type Entry struct {
 	ID        uint
+	Number    string // given on creation, never changes
 	Name      string
 	Timestamp time.Time
 	Renters   []RenterDetails
@@ -36,7 +38,29 @@ type Entry struct {
 	Start     string
 	End       string
 	Rent      float64
-	emisth    []byte
+	Status    string
+	// Lease, free use, sharecropping etc., see contractTypeRules
+	ContractType string
+	// Percent of the crop that goes to the owners in a sharecropping
+	Share float64
+	// Months before the end to give notice, see noticeDeadline
+	NoticeMonths int
+	// Months it renews itself for without notice, 0 if it doesn't
+	AutoRenewMonths int
+	// Who can give notice, see noticeParties
+	NoticeParty string
+	// The contract this one renewed, 0 for a new contract
+	PredecessorID uint
+	// The contract this one subleases land from, 0 if it isn't a sublease
+	ParentID uint
+	// Changes after signing, use AsOf for the terms on a day
+	Amendments []Amendment
+	// Why it was saved although it overlaps other contracts, saved with it
+	Overrides []OverlapOverride
+	// The fields it leases, a new contract without any gets one from its
+	// KAEK, ATAK, size and coordinates
+	Parcels []Parcel
+	emisth  []byte
 }
 
 // Coordinates for the land
@@ -45,6 +69,11 @@ type Coordinates struct {
 	EntryID   uint
 	Latitude  float64
 	Longitude float64
+	// A field can be in several separate parts, each with an outer ring
+	// (Ring 0) and holes (Ring 1 on). Seq is the order of the corners.
+	Part int
+	Ring int
+	Seq  int
 }
 
 // Εκμισθωτές
@@ -85,3 +114,112 @@ type EntryRenter struct {
 	EntryID  uint
 	RenterID uint
 }
+
+// Πληρωμές μισθωμάτων
+type Payment struct {
+	ID       uint
+	EntryID  uint
+	OwnerID  uint // 0 when it was paid to all the owners together
+	RenterID uint
+	Date     string
+	Amount   float64
+	Notes    string
+}
+
+// Αποδείξεις είσπραξης, numbered per series and year
+type Receipt struct {
+	ID           uint
+	PaymentID    uint
+	Series       string
+	Year         int
+	Number       int
+	Issued       string
+	AttachmentID uint
+}
+
+// Files kept with a contract besides the μισθωτήριο
+type Attachment struct {
+	ID      uint
+	EntryID uint
+	Name    string
+	Data    []byte
+	Created time.Time
+}
+
+// Επιτόκιο υπερημερίας, yearly percentage that applies from the date on
+type InterestRate struct {
+	ID   uint
+	From string
+	Rate float64
+}
+
+// Έξοδα αγροτεμαχίου, property tax, irrigation fees, surveyors etc.
+type Expense struct {
+	ID         uint
+	EntryID    uint
+	OwnerID    uint // optional, the owner the expense concerns
+	Category   string
+	Date       string
+	Amount     float64
+	Bearer     string
+	Notes      string
+	Attachment []byte
+}
+
+// Εγγύηση, a cash deposit or a bank guarantee kept until the contract ends
+type Deposit struct {
+	ID         uint
+	EntryID    uint
+	Kind       string
+	Amount     float64
+	Received   string
+	Holder     string
+	Conditions string
+	Returned   string // empty while it's still held
+	Notes      string
+}
+
+// Αγροτεμάχιο, a field as the cadastre knows it. A contract can lease several
+// of them and successive contracts lease the same one.
+type Parcel struct {
+	ID           uint
+	KAEK         string
+	ATAK         uint
+	Area         float64 // στρέμματα
+	Municipality string
+	Coords       []Coordinates
+	Notes        string
+}
+
+// Τροποποίηση, a change of the contract that applies from the effective
+// date on. Only the fields of its kind are used.
+type Amendment struct {
+	ID        uint
+	EntryID   uint
+	Kind      string
+	Effective string
+	Rent      float64
+	Size      float64
+	End       string          // the new end date of an extension
+	Owners    []OwnerDetails  // the owners from then on, empty if they didn't change
+	Renters   []RenterDetails // same for the renters
+	Document  []byte
+	Notes     string
+}
+
+// An overlap with another contract that was accepted on save
+type OverlapOverride struct {
+	ID      uint
+	EntryID uint
+	OtherID uint
+	Reason  string
+	Created time.Time
+}
+
+// Πρότυπο εγγράφου, text with {{placeholders}} for the contract details
+type Template struct {
+	ID      uint
+	Name    string
+	Body    string
+	Created time.Time
+}
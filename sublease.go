@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// The contracts that sublease parts of the parent
+func subleasesOf(parentID uint, entries []Entry) []Entry {
+	var subs []Entry
+	for _, e := range entries {
+		if parentID != 0 && e.ParentID == parentID {
+			subs = append(subs, e)
+		}
+	}
+
+	return subs
+}
+
+// A sublease has to run while its parent runs and, together with the other
+// subleases of the same days, it can't sublease more land than the parent has
+func validateSublease(sub, parent Entry, entries []Entry) error {
+	if sub.ID != 0 && parent.ID == sub.ID {
+		return fmt.Errorf("a contract cannot sublease itself")
+	}
+
+	// the parent can't be a sublease of the sublease
+	byID := make(map[uint]Entry, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	for p, i := parent, 0; p.ParentID != 0 && i < len(entries); p, i = byID[p.ParentID], i+1 {
+		if p.ParentID == sub.ID {
+			return fmt.Errorf("%s is already a sublease of %s", contractLabel(parent), contractLabel(sub))
+		}
+	}
+
+	if dateBefore(sub.Start, parent.Start) || dateBefore(parent.ActualEnd(), sub.ActualEnd()) {
+		return fmt.Errorf("the sublease (%s - %s) has to be within %s (%s - %s)",
+			sub.Start, sub.ActualEnd(), contractLabel(parent), parent.Start, parent.ActualEnd())
+	}
+
+	start, err := parseDate(sub.Start)
+	if err != nil {
+		return err
+	}
+	available := parent.AsOf(start).Size
+	used := sub.AsOf(start).Size
+	for _, other := range subleasesOf(parent.ID, entries) {
+		if other.ID == sub.ID || other.Status == statusDraft || !periodsOverlap(sub, other) {
+			continue
+		}
+		used += other.AsOf(start).Size
+	}
+	if used > available+1e-9 {
+		return fmt.Errorf("the subleases of %s add up to %.3f στρέμματα, more than the %.3f it leases",
+			contractLabel(parent), used, available)
+	}
+
+	return nil
+}
+
+// What a contract that is subleased costs and brings in
+type RentPosition struct {
+	RentDue    float64 // yearly rent of the parent today
+	RentIncome float64 // yearly rent of the subleases that run today
+	Paid       float64 // payments made for the parent
+	Received   float64 // payments received for the subleases
+}
+
+func (p RentPosition) NetRent() float64 {
+	return TruncateFloatTo2Decimals(p.RentIncome - p.RentDue)
+}
+
+func (p RentPosition) Net() float64 {
+	return TruncateFloatTo2Decimals(p.Received - p.Paid)
+}
+
+func rentPosition(parent Entry, subs []Entry, payments []Payment, today time.Time) RentPosition {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	position := RentPosition{RentDue: parent.AsOf(today).Rent}
+
+	subIDs := make(map[uint]bool, len(subs))
+	for _, s := range subs {
+		subIDs[s.ID] = true
+
+		start, errStart := parseDate(s.Start)
+		end, errEnd := parseDate(s.ActualEnd())
+		if errStart != nil || errEnd != nil || s.Status == statusDraft || today.Before(start) || today.After(end) {
+			continue
+		}
+		position.RentIncome += s.AsOf(today).Rent
+	}
+
+	for _, p := range payments {
+		switch {
+		case p.EntryID == parent.ID:
+			position.Paid += p.Amount
+		case subIDs[p.EntryID]:
+			position.Received += p.Amount
+		}
+	}
+
+	return position
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateSublease(t *testing.T) {
+	t.Parallel()
+
+	parent := Entry{ID: 1, Name: "Κάμπος", Size: 20, Start: "01-01-2025", End: "31-12-2029",
+		Amendments: []Amendment{{Kind: amendArea, Effective: "01-01-2027", Size: 10}}}
+	other := Entry{ID: 2, ParentID: 1, Size: 8, Start: "01-01-2025", End: "31-12-2025"}
+	draft := Entry{ID: 3, ParentID: 1, Size: 20, Start: "01-01-2025", End: "31-12-2029", Status: statusDraft}
+	entries := []Entry{parent, other, draft}
+
+	tests := []struct {
+		name string
+		sub  Entry
+		err  string
+	}{
+		{"fits", Entry{ParentID: 1, Size: 12, Start: "01-06-2025", End: "31-12-2026"}, ""},
+		{"fits after the other ends", Entry{ParentID: 1, Size: 10, Start: "01-01-2027", End: "31-12-2029"}, ""},
+		{"starts before the parent", Entry{ParentID: 1, Size: 1, Start: "31-12-2024", End: "31-12-2025"}, "has to be within"},
+		{"ends after the parent", Entry{ParentID: 1, Size: 1, Start: "01-01-2026", End: "01-01-2030"}, "has to be within"},
+		{"too much with the other", Entry{ParentID: 1, Size: 12.5, Start: "01-06-2025", End: "31-12-2025"}, "add up to 20.500"},
+		{"more than the amended area", Entry{ParentID: 1, Size: 11, Start: "01-01-2027", End: "31-12-2027"}, "more than the 10.000"},
+		{"itself", Entry{ID: 1, ParentID: 1, Size: 1, Start: "01-01-2025", End: "31-12-2025"}, "itself"},
+	}
+	for _, tt := range tests {
+		err := validateSublease(tt.sub, parent, entries)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: expected an error with %q, got %v", tt.name, tt.err, err)
+		}
+	}
+
+	// the other one can grow as long as the total fits
+	other.Size = 20
+	if err := validateSublease(other, parent, entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateSublease_Cycle(t *testing.T) {
+	t.Parallel()
+
+	a := Entry{ID: 1, Size: 10, Start: "01-01-2025", End: "31-12-2025"}
+	b := Entry{ID: 2, ParentID: 1, Size: 5, Start: "01-01-2025", End: "31-12-2025"}
+	c := Entry{ID: 3, ParentID: 2, Size: 5, Start: "01-01-2025", End: "31-12-2025"}
+
+	// a can't become a sublease of its own sublease's sublease
+	a.ParentID = 3
+	if err := validateSublease(a, c, []Entry{a, b, c}); err == nil || !strings.Contains(err.Error(), "already a sublease") {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+}
+
+func TestRentPosition(t *testing.T) {
+	t.Parallel()
+
+	parent := Entry{ID: 1, Rent: 1000, Start: "01-01-2025", End: "31-12-2029"}
+	subs := []Entry{
+		{ID: 2, ParentID: 1, Rent: 600, Start: "01-01-2025", End: "31-12-2026",
+			Amendments: []Amendment{{Kind: amendRent, Effective: "01-01-2026", Rent: 700}}},
+		{ID: 3, ParentID: 1, Rent: 500, Start: "01-01-2027", End: "31-12-2029"},
+		{ID: 4, ParentID: 1, Rent: 900, Start: "01-01-2025", End: "31-12-2029", Status: statusDraft},
+	}
+	payments := []Payment{
+		{EntryID: 1, Amount: 1000},
+		{EntryID: 2, Amount: 600},
+		{EntryID: 2, Amount: 350},
+		{EntryID: 9, Amount: 5000},
+	}
+
+	got := rentPosition(parent, subs, payments, date("31-12-2026").Add(15*time.Hour))
+	if got.RentDue != 1000 || got.RentIncome != 700 || got.NetRent() != -300 {
+		t.Fatalf("unexpected rents: %+v", got)
+	}
+	if got.Paid != 1000 || got.Received != 950 || got.Net() != -50 {
+		t.Fatalf("unexpected payments: %+v", got)
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats a template can be rendered to
+const (
+	formatPDF  = "PDF"
+	formatODT  = "ODT"
+	formatDOCX = "DOCX"
+)
+
+var documentFormats = []string{formatPDF, formatODT, formatDOCX}
+
+var documentExts = map[string]string{
+	formatPDF:  ".pdf",
+	formatODT:  ".odt",
+	formatDOCX: ".docx",
+}
+
+// Placeholders a template can use as {{name}}, with what they are replaced by
+var templatePlaceholders = map[string]string{
+	"id":                "Κωδικός συμβολαίου στη βάση",
+	"number":            "Αριθμός συμβολαίου",
+	"name":              "Όνομα συμβολαίου",
+	"kaek":              "ΚΑΕΚ",
+	"atak":              "ΑΤΑΚ",
+	"size":              "Στρέμματα",
+	"type":              "Είδος καλλιέργειας",
+	"start":             "Έναρξη",
+	"end":               "Λήξη",
+	"years":             "Διάρκεια σε έτη",
+	"contract_type":     "Είδος σύμβασης",
+	"share":             "Ποσοστό της σοδειάς στην επίμορτη αγροληψία",
+	"notice_months":     "Μήνες προειδοποίησης για την καταγγελία",
+	"notice_deadline":   "Τελευταία ημέρα καταγγελίας πριν τη σιωπηρή παράταση",
+	"auto_renew_months": "Μήνες σιωπηρής παράτασης",
+	"rent":              "Ετήσιο μίσθωμα",
+	"rent_words":        "Ετήσιο μίσθωμα ολογράφως",
+	"today":             "Σημερινή ημερομηνία",
+	"owners":            "Εκμισθωτές με τα στοιχεία τους, ένας ανά γραμμή",
+	"owner_names":       "Ονόματα εκμισθωτών",
+	"renters":           "Μισθωτές με τα στοιχεία τους, ένας ανά γραμμή",
+	"renter_names":      "Ονόματα μισθωτών",
+	"coords":            "Συντεταγμένες, μία ανά γραμμή",
+	"coords_egsa87":     "Συντεταγμένες σε ΕΓΣΑ87, μία ανά γραμμή",
+	"parcels":           "Αγροτεμάχια με ΚΑΕΚ, ΑΤΑΚ και έκταση, ένα ανά γραμμή",
+}
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+
+// What a new database starts with, lines that start with # are headings
+const defaultTemplateName = "Ιδιωτικό συμφωνητικό μίσθωσης"
+
+const defaultTemplateBody = `# ΙΔΙΩΤΙΚΟ ΣΥΜΦΩΝΗΤΙΚΟ ΜΙΣΘΩΣΗΣ ΑΓΡΟΤΙΚΟΥ ΑΚΙΝΗΤΟΥ
+# Αριθμός {{number}}
+
+Σήμερα {{today}} οι παρακάτω συμβαλλόμενοι:
+
+Εκμισθωτής/ές:
+{{owners}}
+
+Μισθωτής/ές:
+{{renters}}
+
+συμφώνησαν και έκαναν αποδεκτά τα εξής:
+
+1. Ο εκμισθωτής εκμισθώνει στον μισθωτή το αγροτεμάχιο «{{name}}» με ΚΑΕΚ {{kaek}} και ΑΤΑΚ {{atak}}, έκτασης {{size}} στρεμμάτων, για την καλλιέργεια {{type}}.
+
+2. Η μίσθωση αρχίζει στις {{start}} και λήγει στις {{end}} ({{years}} έτη).
+
+3. Το ετήσιο μίσθωμα ορίζεται σε {{rent}}€ ({{rent_words}}).
+
+4. Συντεταγμένες του αγροτεμαχίου:
+{{coords}}
+
+Σε πίστωση των παραπάνω συντάχθηκε το παρόν και υπογράφεται από τους συμβαλλόμενους.
+
+ΟΙ ΕΚΜΙΣΘΩΤΕΣ                    ΟΙ ΜΙΣΘΩΤΕΣ
+`
+
+// Placeholders in the body that aren't in templatePlaceholders, sorted
+func unknownPlaceholders(body string) []string {
+	seen := make(map[string]bool)
+	var unknown []string
+
+	for _, m := range placeholderRe.FindAllStringSubmatch(body, -1) {
+		if _, ok := templatePlaceholders[m[1]]; !ok && !seen[m[1]] {
+			seen[m[1]] = true
+			unknown = append(unknown, m[1])
+		}
+	}
+	sort.Strings(unknown)
+
+	return unknown
+}
+
+// The values of the placeholders for the contract with the terms it has on today
+func templateValues(e Entry, today time.Time) map[string]string {
+	terms := e.AsOf(today)
+
+	var owners []string
+	for _, o := range terms.Owners {
+		owners = append(owners, fmt.Sprintf("%s %s του %s, Α.Φ.Μ.: %d, Α.Δ.Τ.: %s, κάτοικος %s",
+			o.FirstName, o.LastName, o.FathersName, o.AFM, o.ADT, o.HomeAddress))
+	}
+	var renters []string
+	for _, r := range terms.Renters {
+		renters = append(renters, fmt.Sprintf("%s %s του %s, Α.Φ.Μ.: %d, Α.Δ.Τ.: %s",
+			r.FirstName, r.LastName, r.FathersName, r.AFM, r.ADT))
+	}
+
+	var parcels []string
+	for _, p := range terms.Parcels {
+		parcels = append(parcels, fmt.Sprintf("ΚΑΕΚ %s, ΑΤΑΚ %d, %.3f στρέμματα", p.KAEK, p.ATAK, p.Area))
+	}
+
+	years := ""
+	start, errStart := parseDate(terms.Start)
+	end, errEnd := parseDate(terms.End)
+	if errStart == nil && errEnd == nil {
+		years = fmt.Sprintf("%d", yearsBetween(start, end))
+	}
+
+	deadline := ""
+	if d, ok := noticeDeadline(e); ok {
+		deadline = d.Format(dateLayout)
+	}
+
+	return map[string]string{
+		"id":                fmt.Sprintf("%d", terms.ID),
+		"number":            terms.Number,
+		"name":              terms.Name,
+		"kaek":              terms.KAEK,
+		"atak":              fmt.Sprintf("%d", terms.ATAK),
+		"size":              fmt.Sprintf("%.3f", terms.Size),
+		"type":              terms.Type,
+		"start":             terms.Start,
+		"end":               terms.End,
+		"years":             years,
+		"contract_type":     contractTypeLabel(terms.ContractType),
+		"share":             strconv.FormatFloat(terms.Share, 'f', -1, 64),
+		"notice_months":     fmt.Sprintf("%d", terms.NoticeMonths),
+		"notice_deadline":   deadline,
+		"auto_renew_months": fmt.Sprintf("%d", terms.AutoRenewMonths),
+		"rent":              fmt.Sprintf("%.2f", terms.Rent),
+		"rent_words":        greekAmountWords(terms.Rent),
+		"today":             today.Format(dateLayout),
+		"owners":            strings.Join(owners, "\n"),
+		"owner_names":       strings.Join(ownerNames(terms.Owners), ", "),
+		"renters":           strings.Join(renters, "\n"),
+		"renter_names":      strings.Join(renterNames(terms.Renters), ", "),
+		"coords":            strings.Join(shapeLines(terms.Coords), "\n"),
+		"coords_egsa87":     strings.Join(egsa87Lines(terms.Coords), "\n"),
+		"parcels":           strings.Join(parcels, "\n"),
+	}
+}
+
+// Whole years from start to the day after end, a lease of 01-01-2025 to
+// 31-12-2029 is 5 years
+func yearsBetween(start, end time.Time) int {
+	end = end.AddDate(0, 0, 1)
+	years := 0
+	for !start.AddDate(years+1, 0, 0).After(end) {
+		years++
+	}
+
+	return years
+}
+
+// Replaces the placeholders of the body with the values
+func renderTemplate(body string, values map[string]string) (string, error) {
+	if unknown := unknownPlaceholders(body); len(unknown) > 0 {
+		return "", fmt.Errorf("unknown placeholders: %s", strings.Join(unknown, ", "))
+	}
+
+	return placeholderRe.ReplaceAllStringFunc(body, func(m string) string {
+		return values[placeholderRe.FindStringSubmatch(m)[1]]
+	}), nil
+}
+
+// A rendered line of the document, the ones that started with # are headings
+type docParagraph struct {
+	text    string
+	heading bool
+}
+
+func docParagraphs(text string) []docParagraph {
+	var paragraphs []docParagraph
+	for _, l := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(l, "# ") {
+			paragraphs = append(paragraphs, docParagraph{strings.TrimPrefix(l, "# "), true})
+			continue
+		}
+		paragraphs = append(paragraphs, docParagraph{l, false})
+	}
+
+	return paragraphs
+}
+
+// Renders the text of a template to the format
+func renderDocument(text, format string) ([]byte, error) {
+	switch format {
+	case formatPDF:
+		return documentPDF(text)
+	case formatODT:
+		return documentODT(text)
+	case formatDOCX:
+		return documentDOCX(text)
+	}
+
+	return nil, fmt.Errorf("unknown document format: %s", format)
+}
+
+func documentPDF(text string) ([]byte, error) {
+	pdf := newPDF("P")
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	for _, p := range docParagraphs(text) {
+		if p.heading {
+			pdf.SetFont(pdfFont, "B", 13)
+			pdf.MultiCell(0, 7, p.text, "", "C", false)
+			pdf.Ln(3)
+			pdf.SetFont(pdfFont, "", 11)
+			continue
+		}
+		if p.text == "" {
+			pdf.Ln(3)
+			continue
+		}
+		pdf.MultiCell(0, 5.5, p.text, "", "J", false)
+	}
+
+	return pdfBytes(pdf)
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// Writes the files in order to a zip, the stored ones aren't compressed
+func zipFiles(files [][2]string, stored map[string]bool) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	for _, f := range files {
+		method := zip.Deflate
+		if stored[f[0]] {
+			method = zip.Store
+		}
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: f[0], Method: method})
+		if err != nil {
+			return nil, fmt.Errorf("error creating %s: %v", f[0], err)
+		}
+		if _, err := fw.Write([]byte(f[1])); err != nil {
+			return nil, fmt.Errorf("error writing %s: %v", f[0], err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("error closing the document: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// OpenDocument text, the mimetype has to be the first file and uncompressed
+func documentODT(text string) ([]byte, error) {
+	var body strings.Builder
+	for _, p := range docParagraphs(text) {
+		if p.heading {
+			fmt.Fprintf(&body, `<text:h text:style-name="Heading" text:outline-level="1">%s</text:h>`, xmlEscape(p.text))
+			continue
+		}
+		fmt.Fprintf(&body, `<text:p text:style-name="Body">%s</text:p>`, xmlEscape(p.text))
+	}
+
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" office:version="1.2">
+<office:automatic-styles>
+<style:style style:name="Heading" style:family="paragraph"><style:paragraph-properties fo:text-align="center" fo:margin-bottom="0.3cm"/><style:text-properties fo:font-size="13pt" fo:font-weight="bold"/></style:style>
+<style:style style:name="Body" style:family="paragraph"><style:paragraph-properties fo:text-align="justify"/><style:text-properties fo:font-size="11pt"/></style:style>
+</office:automatic-styles>
+<office:body><office:text>` + body.String() + `</office:text></office:body>
+</office:document-content>`
+
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+<manifest:file-entry manifest:full-path="/" manifest:media-type="application/vnd.oasis.opendocument.text"/>
+<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>`
+
+	return zipFiles([][2]string{
+		{"mimetype", "application/vnd.oasis.opendocument.text"},
+		{"META-INF/manifest.xml", manifest},
+		{"content.xml", content},
+	}, map[string]bool{"mimetype": true})
+}
+
+// Office Open XML, the smallest package Word opens
+func documentDOCX(text string) ([]byte, error) {
+	var body strings.Builder
+	for _, p := range docParagraphs(text) {
+		if p.heading {
+			fmt.Fprintf(&body, `<w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:rPr><w:b/><w:sz w:val="26"/></w:rPr><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, xmlEscape(p.text))
+			continue
+		}
+		fmt.Fprintf(&body, `<w:p><w:pPr><w:jc w:val="both"/></w:pPr><w:r><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, xmlEscape(p.text))
+	}
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` + body.String() + `</w:body></w:document>`
+
+	contentTypes := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+</Types>`
+
+	rels := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+	return zipFiles([][2]string{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rels},
+		{"word/document.xml", document},
+	}, nil)
+}
+
+// The extension of an ODT or DOCX file, empty for anything else
+func officeDocumentExt(data []byte) string {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ""
+	}
+
+	for _, f := range r.File {
+		switch f.Name {
+		case "word/document.xml":
+			return ".docx"
+		case "mimetype":
+			rc, err := f.Open()
+			if err != nil {
+				return ""
+			}
+			var buf bytes.Buffer
+			_, _ = buf.ReadFrom(rc)
+			_ = rc.Close()
+			if buf.String() == "application/vnd.oasis.opendocument.text" {
+				return ".odt"
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	t.Parallel()
+
+	e := Entry{
+		ID:      3,
+		Name:    "Κάμπος",
+		KAEK:    "123",
+		ATAK:    45,
+		Size:    12.5,
+		Rent:    1000,
+		Start:   "01-10-2025",
+		End:     "30-09-2030",
+		Owners:  []OwnerDetails{{FirstName: "Α", LastName: "Β"}, {FirstName: "Γ", LastName: "Δ"}},
+		Renters: []RenterDetails{{FirstName: "Ε", LastName: "Ζ"}},
+		Coords:  []Coordinates{{Latitude: 38.5, Longitude: 23.25}},
+		Amendments: []Amendment{
+			{Kind: amendRent, Effective: "01-10-2026", Rent: 1200},
+		},
+	}
+
+	got, err := renderTemplate("{{name}} {{ kaek }} {{years}} έτη, {{rent}}€ ({{rent_words}}) {{owner_names}}\n{{coords}}",
+		templateValues(e, date("01-01-2026")))
+	if err != nil {
+		t.Fatalf("renderTemplate returned error: %v", err)
+	}
+	want := "Κάμπος 123 5 έτη, 1000.00€ (χίλια ευρώ) Α Β, Γ Δ\n1. 38.500000, 23.250000"
+	if got != want {
+		t.Fatalf("unexpected text:\n%s\nwant:\n%s", got, want)
+	}
+
+	// the terms on the day of the document
+	got, _ = renderTemplate("{{rent}}", templateValues(e, date("01-01-2027")))
+	if got != "1200.00" {
+		t.Fatalf("expected the amended rent, got %s", got)
+	}
+
+	if _, err := renderTemplate("{{name}} {{foo}} {{bar}} {{foo}}", nil); err == nil || !strings.Contains(err.Error(), "bar, foo") {
+		t.Fatalf("expected the unknown placeholders, got %v", err)
+	}
+}
+
+func TestDefaultTemplateIsValid(t *testing.T) {
+	t.Parallel()
+
+	if unknown := unknownPlaceholders(defaultTemplateBody); len(unknown) != 0 {
+		t.Fatalf("unknown placeholders in the default template: %v", unknown)
+	}
+}
+
+func TestYearsBetween(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		start, end string
+		want       int
+	}{
+		{"01-01-2025", "31-12-2029", 5},
+		{"29-02-2024", "28-02-2025", 1},
+		{"15-03-2025", "14-03-2026", 1},
+		{"15-03-2025", "13-03-2026", 0},
+	}
+	for _, tt := range tests {
+		if got := yearsBetween(date(tt.start), date(tt.end)); got != tt.want {
+			t.Errorf("yearsBetween(%s, %s) = %d, want %d", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	files := make(map[string]string)
+	for i, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("cannot open %s: %v", f.Name, err)
+		}
+		b, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(b)
+		if i == 0 {
+			files["first"] = f.Name
+		}
+	}
+
+	return files
+}
+
+func TestRenderDocument(t *testing.T) {
+	t.Parallel()
+
+	text := "# Τίτλος\nΚείμενο με <σύμβολα> & άλλα"
+
+	odt, err := renderDocument(text, formatODT)
+	if err != nil {
+		t.Fatalf("renderDocument returned error: %v", err)
+	}
+	files := readZip(t, odt)
+	if files["first"] != "mimetype" || files["mimetype"] != "application/vnd.oasis.opendocument.text" {
+		t.Fatalf("the odt has to start with its mimetype: %v", files["first"])
+	}
+	if !strings.Contains(files["content.xml"], "<text:h") || !strings.Contains(files["content.xml"], "&lt;σύμβολα&gt; &amp; άλλα") {
+		t.Fatalf("unexpected content: %s", files["content.xml"])
+	}
+	if officeDocumentExt(odt) != ".odt" {
+		t.Fatalf("expected an odt")
+	}
+
+	docx, err := renderDocument(text, formatDOCX)
+	if err != nil {
+		t.Fatalf("renderDocument returned error: %v", err)
+	}
+	files = readZip(t, docx)
+	if !strings.Contains(files["word/document.xml"], "<w:b/>") || !strings.Contains(files["word/document.xml"], "&lt;σύμβολα&gt;") {
+		t.Fatalf("unexpected document: %s", files["word/document.xml"])
+	}
+	if officeDocumentExt(docx) != ".docx" {
+		t.Fatalf("expected a docx")
+	}
+
+	pdf, err := renderDocument(text, formatPDF)
+	if err != nil || !bytes.HasPrefix(pdf, []byte("%PDF")) {
+		t.Fatalf("expected a pdf, got error %v", err)
+	}
+	if officeDocumentExt(pdf) != "" {
+		t.Fatalf("a pdf isn't an office document")
+	}
+
+	if _, err := renderDocument(text, "RTF"); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}